@@ -0,0 +1,70 @@
+package apigateway
+
+import (
+	"encoding/json"
+	"io"
+	"myaws/log"
+	"net/http"
+	"strings"
+)
+
+const (
+	errorTypeBadRequest = "BadRequestException"
+	errorTypeConflict   = "ConflictException"
+	errorTypeNotFound   = "NotFoundException"
+)
+
+type errorBody struct {
+	Message string `json:"message"`
+}
+
+// respondWithError writes an error the way API Gateway does, so that SDKs can tell the type of error from the
+// X-Amzn-ErrorType header and show its message.
+func respondWithError(response http.ResponseWriter, statusCode int, errorType string, message string) {
+	header := response.Header()
+	header.Set("Content-Type", "application/json")
+	if len(errorType) > 0 {
+		header.Set("X-Amzn-ErrorType", errorType)
+	}
+	response.WriteHeader(statusCode)
+
+	json.NewEncoder(response).Encode(errorBody{Message: message})
+}
+
+func respondWithJson(response http.ResponseWriter, statusCode int, value interface{}) {
+	log.Info("Response: %+v", value)
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(value)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, request *http.Request, value interface{}) bool {
+	defer request.Body.Close()
+
+	err := json.NewDecoder(request.Body).Decode(value)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return false
+	}
+
+	return true
+}
+
+// pathPart returns the index-th part of the request's path, splitting on slashes.
+func pathPart(request *http.Request, index int) string {
+	parts := strings.Split(request.URL.Path, "/")
+	if index >= len(parts) {
+		return ""
+	}
+
+	return parts[index]
+}
+
+// unescapePatchPath decodes the escaping of JSON Pointers, which patch operations use for paths like
+// /binaryMediaTypes/image~1png.
+func unescapePatchPath(path string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(path, "~1", "/"), "~0", "~")
+}
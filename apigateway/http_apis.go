@@ -0,0 +1,594 @@
+package apigateway
+
+import (
+	"database/sql"
+	"myaws/apigateway/queries"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	integrationIdLength = 7
+	routeIdLength       = 6
+
+	defaultRouteSelectionExpression = "$request.method $request.path"
+	integrationTargetPrefix         = "integrations/"
+)
+
+// findHttpApi writes an error response & returns nil when the HTTP API in the path doesn't exist.
+func findHttpApi(response http.ResponseWriter, request *http.Request, db *database.Database) *types.HttpApi {
+	apiId := pathPart(request, 3)
+	api, err := queries.HttpApiById(request.Context(), db, apiId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid API identifier specified %s", apiId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return api
+}
+
+const HttpApisRegex = `^/v2/apis/?$`
+
+func CreateHttpApi(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateApiInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.Name) == 0 {
+		msg := log.Error("Name is required to create an API")
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	if body.ProtocolType != types.ProtocolTypeHttp {
+		msg := log.Error("Only %s APIs are supported, not %s", types.ProtocolTypeHttp, body.ProtocolType)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	now := time.Now().UnixMilli()
+	api := types.HttpApi{
+		ApiId:                    utils.RandomId(apiIdLength),
+		Name:                     body.Name,
+		Description:              body.Description,
+		ProtocolType:             body.ProtocolType,
+		RouteSelectionExpression: body.RouteSelectionExpression,
+		CreatedOn:                now,
+	}
+
+	if len(api.RouteSelectionExpression) == 0 {
+		api.RouteSelectionExpression = defaultRouteSelectionExpression
+	}
+
+	err := queries.InsertHttpApi(ctx, db, &api)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(body.Target) > 0 {
+		err = quickCreate(request, db, api, body)
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	respondWithJson(response, http.StatusCreated, api.ToOutput(ctx))
+}
+
+// quickCreate adds the integration with the target, a route to it and an automatically deployed $default stage.
+func quickCreate(request *http.Request, db *database.Database, api types.HttpApi, body types.CreateApiInput) error {
+	ctx := request.Context()
+	integration := types.Integration{
+		ApiId:                api.ApiId,
+		IntegrationId:        utils.RandomId(integrationIdLength),
+		IntegrationType:      integrationTypeAwsProxy,
+		IntegrationUri:       body.Target,
+		PayloadFormatVersion: types.PayloadFormatVersion2,
+		TimeoutInMillis:      defaultTimeoutInMillis,
+	}
+
+	err := queries.InsertIntegration(ctx, db, &integration)
+	if err != nil {
+		return err
+	}
+
+	route := types.Route{
+		ApiId:             api.ApiId,
+		RouteId:           utils.RandomId(routeIdLength),
+		RouteKey:          body.RouteKey,
+		Target:            integrationTargetPrefix + integration.IntegrationId,
+		AuthorizationType: authorizationTypeNone,
+	}
+
+	if len(route.RouteKey) == 0 {
+		route.RouteKey = types.DefaultRouteKey
+	}
+
+	err = queries.InsertRoute(ctx, db, &route)
+	if err != nil {
+		return err
+	}
+
+	stage := types.Stage{
+		ApiId:         api.ApiId,
+		StageName:     types.DefaultStageName,
+		AutoDeploy:    true,
+		CreatedOn:     api.CreatedOn,
+		LastUpdatedOn: api.CreatedOn,
+	}
+
+	return queries.InsertStage(ctx, db, &stage)
+}
+
+func GetHttpApis(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	apis, err := queries.HttpApis(ctx, db)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]types.HttpApiOutput, len(apis))
+	for i, api := range apis {
+		items[i] = api.ToOutput(ctx)
+	}
+
+	utils.RespondWithJson(response, map[string][]types.HttpApiOutput{"items": items})
+}
+
+const HttpApiRegex = `^/v2/apis/[a-z0-9]+$`
+
+func GetHttpApi(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, api.ToOutput(ctx))
+}
+
+func UpdateHttpApi(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateApiInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	if len(body.Name) > 0 {
+		api.Name = body.Name
+	}
+
+	if len(body.Description) > 0 {
+		api.Description = body.Description
+	}
+
+	if len(body.RouteSelectionExpression) > 0 {
+		api.RouteSelectionExpression = body.RouteSelectionExpression
+	}
+
+	err := queries.UpdateHttpApi(ctx, db, api)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, api.ToOutput(ctx))
+}
+
+func DeleteHttpApi(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	err := queries.DeleteHttpApi(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+const IntegrationsRegex = `^/v2/apis/[a-z0-9]+/integrations/?$`
+
+func CreateHttpIntegration(response http.ResponseWriter, request *http.Request) {
+	var body types.IntegrationInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if body.IntegrationType != integrationTypeAwsProxy {
+		log.Info("WARNING: Only %s integrations are invoked, %s integrations are only saved", integrationTypeAwsProxy,
+			body.IntegrationType)
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	integration := types.Integration{
+		ApiId:                api.ApiId,
+		IntegrationId:        utils.RandomId(integrationIdLength),
+		IntegrationType:      body.IntegrationType,
+		IntegrationUri:       body.IntegrationUri,
+		IntegrationMethod:    body.IntegrationMethod,
+		PayloadFormatVersion: body.PayloadFormatVersion,
+		TimeoutInMillis:      utils.Int32OrDefault(body.TimeoutInMillis, defaultTimeoutInMillis),
+	}
+
+	if len(integration.PayloadFormatVersion) == 0 {
+		integration.PayloadFormatVersion = types.PayloadFormatVersion1
+	}
+
+	err := queries.InsertIntegration(ctx, db, &integration)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, integration.ToOutput())
+}
+
+func GetHttpIntegrations(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	integrations, err := queries.Integrations(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]types.IntegrationOutput, len(integrations))
+	for i, integration := range integrations {
+		items[i] = integration.ToOutput()
+	}
+
+	utils.RespondWithJson(response, map[string][]types.IntegrationOutput{"items": items})
+}
+
+const HttpIntegrationRegex = `^/v2/apis/[a-z0-9]+/integrations/[a-z0-9]+$`
+
+// findHttpIntegration writes an error response & returns nil when the integration in the path doesn't exist.
+func findHttpIntegration(response http.ResponseWriter, request *http.Request, db *database.Database) *types.Integration {
+	apiId := pathPart(request, 3)
+	integrationId := pathPart(request, 5)
+	integration, err := queries.IntegrationById(request.Context(), db, apiId, integrationId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid Integration identifier specified %s", integrationId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return integration
+}
+
+func GetHttpIntegration(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	integration := findHttpIntegration(response, request, db)
+	if integration == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, integration.ToOutput())
+}
+
+func UpdateHttpIntegration(response http.ResponseWriter, request *http.Request) {
+	var body types.IntegrationInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	integration := findHttpIntegration(response, request, db)
+	if integration == nil {
+		return
+	}
+
+	if len(body.IntegrationType) > 0 {
+		integration.IntegrationType = body.IntegrationType
+	}
+
+	if len(body.IntegrationUri) > 0 {
+		integration.IntegrationUri = body.IntegrationUri
+	}
+
+	if len(body.IntegrationMethod) > 0 {
+		integration.IntegrationMethod = body.IntegrationMethod
+	}
+
+	if len(body.PayloadFormatVersion) > 0 {
+		integration.PayloadFormatVersion = body.PayloadFormatVersion
+	}
+
+	integration.TimeoutInMillis = utils.Int32OrDefault(body.TimeoutInMillis, integration.TimeoutInMillis)
+
+	err := queries.UpdateIntegration(ctx, db, integration)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, integration.ToOutput())
+}
+
+func DeleteHttpIntegration(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	integration := findHttpIntegration(response, request, db)
+	if integration == nil {
+		return
+	}
+
+	err := queries.DeleteIntegration(ctx, db, integration)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// validRouteKey reports whether the key is $default, or an HTTP method (or ANY) followed by a path.
+func validRouteKey(key string) bool {
+	if key == types.DefaultRouteKey {
+		return true
+	}
+
+	parts := strings.SplitN(key, " ", 2)
+	return len(parts) == 2 && len(parts[0]) > 0 && strings.HasPrefix(parts[1], "/")
+}
+
+const RoutesRegex = `^/v2/apis/[a-z0-9]+/routes/?$`
+
+func CreateRoute(response http.ResponseWriter, request *http.Request) {
+	var body types.RouteInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !validRouteKey(body.RouteKey) {
+		msg := log.Error("Unable to create route %q. Route keys must be $default or an HTTP method and path",
+			body.RouteKey)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	route := types.Route{
+		ApiId:             api.ApiId,
+		RouteId:           utils.RandomId(routeIdLength),
+		RouteKey:          body.RouteKey,
+		Target:            body.Target,
+		AuthorizationType: body.AuthorizationType,
+	}
+
+	if len(route.AuthorizationType) == 0 {
+		route.AuthorizationType = authorizationTypeNone
+	}
+
+	routes, err := queries.Routes(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, existing := range routes {
+		if existing.RouteKey == route.RouteKey {
+			msg := log.Error("Unable to create route %s. A route with that key already exists", route.RouteKey)
+			respondWithError(response, http.StatusConflict, errorTypeConflict, msg)
+			return
+		}
+	}
+
+	err = queries.InsertRoute(ctx, db, &route)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, route.ToOutput())
+}
+
+func GetRoutes(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findHttpApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	routes, err := queries.Routes(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]types.RouteOutput, len(routes))
+	for i, route := range routes {
+		items[i] = route.ToOutput()
+	}
+
+	utils.RespondWithJson(response, map[string][]types.RouteOutput{"items": items})
+}
+
+const RouteRegex = `^/v2/apis/[a-z0-9]+/routes/[a-z0-9]+$`
+
+// findRoute writes an error response & returns nil when the route in the path doesn't exist.
+func findRoute(response http.ResponseWriter, request *http.Request, db *database.Database) *types.Route {
+	apiId := pathPart(request, 3)
+	routeId := pathPart(request, 5)
+	route, err := queries.RouteById(request.Context(), db, apiId, routeId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid Route identifier specified %s", routeId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return route
+}
+
+func GetRoute(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	route := findRoute(response, request, db)
+	if route == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, route.ToOutput())
+}
+
+func UpdateRoute(response http.ResponseWriter, request *http.Request) {
+	var body types.RouteInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.RouteKey) > 0 && !validRouteKey(body.RouteKey) {
+		msg := log.Error("Unable to update route to %q. Route keys must be $default or an HTTP method and path",
+			body.RouteKey)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	route := findRoute(response, request, db)
+	if route == nil {
+		return
+	}
+
+	if len(body.RouteKey) > 0 {
+		route.RouteKey = body.RouteKey
+	}
+
+	if len(body.Target) > 0 {
+		route.Target = body.Target
+	}
+
+	if len(body.AuthorizationType) > 0 {
+		route.AuthorizationType = body.AuthorizationType
+	}
+
+	err := queries.UpdateRoute(ctx, db, route)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, route.ToOutput())
+}
+
+func DeleteRoute(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	route := findRoute(response, request, db)
+	if route == nil {
+		return
+	}
+
+	err := queries.DeleteRoute(ctx, db, route)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+package apigateway
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "ApiGateway",
+		Description: "Create REST API Tables",
+		Query: `CREATE TABLE IF NOT EXISTS apigateway_rest_api (
+					id					integer primary key autoincrement,
+					api_id				text not null UNIQUE,
+					name				text not null,
+					description			text not null,
+					root_resource_id	text not null,
+					binary_media_types	text not null,
+					created_on			integer not null
+				);
+				CREATE TABLE IF NOT EXISTS apigateway_resource (
+					id					integer primary key autoincrement,
+					api_id				text not null,
+					resource_id			text not null UNIQUE,
+					parent_id			text not null,
+					path_part			text not null,
+					path				text not null,
+					UNIQUE(api_id, path)
+				);
+				CREATE TABLE IF NOT EXISTS apigateway_method (
+					id						integer primary key autoincrement,
+					api_id					text not null,
+					resource_id				text not null,
+					http_method				text not null,
+					authorization_type		text not null,
+					api_key_required		integer not null,
+					integration_type		text not null DEFAULT '',
+					integration_http_method	text not null DEFAULT '',
+					integration_uri			text not null DEFAULT '',
+					integration_timeout		integer not null DEFAULT 29000,
+					UNIQUE(resource_id, http_method)
+				);
+		`,
+	},
+	{
+		Service:     "ApiGateway",
+		Description: "Create HTTP API Tables",
+		Query: `CREATE TABLE IF NOT EXISTS apigateway_http_api (
+					id							integer primary key autoincrement,
+					api_id						text not null UNIQUE,
+					name						text not null,
+					description					text not null,
+					protocol_type				text not null,
+					route_selection_expression	text not null,
+					created_on					integer not null
+				);
+				CREATE TABLE IF NOT EXISTS apigateway_integration (
+					id						integer primary key autoincrement,
+					api_id					text not null,
+					integration_id			text not null UNIQUE,
+					integration_type		text not null,
+					integration_uri			text not null,
+					integration_method		text not null,
+					payload_format_version	text not null,
+					timeout					integer not null
+				);
+				CREATE TABLE IF NOT EXISTS apigateway_route (
+					id					integer primary key autoincrement,
+					api_id				text not null,
+					route_id			text not null UNIQUE,
+					route_key			text not null,
+					target				text not null,
+					authorization_type	text not null,
+					UNIQUE(api_id, route_key)
+				);
+		`,
+	},
+	{
+		Service:     "ApiGateway",
+		Description: "Create Deployment & Stage Tables",
+		Query: `CREATE TABLE IF NOT EXISTS apigateway_deployment (
+					id				integer primary key autoincrement,
+					api_id			text not null,
+					deployment_id	text not null UNIQUE,
+					description		text not null,
+					created_on		integer not null
+				);
+				CREATE TABLE IF NOT EXISTS apigateway_stage (
+					id				integer primary key autoincrement,
+					api_id			text not null,
+					stage_name		text not null,
+					deployment_id	text not null,
+					description		text not null,
+					variables		text not null,
+					auto_deploy		integer not null,
+					created_on		integer not null,
+					last_updated_on	integer not null,
+					UNIQUE(api_id, stage_name)
+				);
+		`,
+	},
+}
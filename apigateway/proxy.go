@@ -0,0 +1,499 @@
+package apigateway
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"myaws/apigateway/queries"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/lambda"
+	lambdaTypes "myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ExecuteApiHostRegex matches the hostname of stage endpoints, capturing the API's ID.
+const ExecuteApiHostRegex = `^([a-z0-9]+)\.execute-api\.[a-z0-9-]+\.localhost$`
+
+// ExecuteApiPathRegex is an alternative to the hostname of stage endpoints for clients that can't resolve subdomains
+// of localhost, such as Functions running in containers: /_myaws/execute-api/<api id>/<stage>/<path>.
+const ExecuteApiPathRegex = `^/_myaws/execute-api/[a-z0-9]+(/.*)?$`
+
+const requestTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+var (
+	functionNameRegex  = regexp.MustCompile(`:function:([A-Za-z0-9_-]+)`)
+	stageVariableRegex = regexp.MustCompile(`\$\{stageVariables\.([A-Za-z0-9_]+)}`)
+)
+
+// InvokeApiByHost serves the stage endpoint of the API whose ID is in the hostname of the request.
+func InvokeApiByHost(response http.ResponseWriter, request *http.Request) {
+	host := request.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	apiId := strings.SplitN(host, ".", 2)[0]
+	invokeApi(response, request, apiId, request.URL.Path)
+}
+
+// InvokeApiByPath serves the stage endpoint of the API whose ID is in the path of the request.
+func InvokeApiByPath(response http.ResponseWriter, request *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(request.URL.Path, "/_myaws/execute-api/"), "/", 2)
+	path := "/"
+	if len(parts) == 2 {
+		path += parts[1]
+	}
+
+	invokeApi(response, request, parts[0], path)
+}
+
+// invocation is everything about a request to a stage endpoint that its proxy event is built from.
+type invocation struct {
+	request    *http.Request
+	apiId      string
+	stage      *types.Stage
+	path       string
+	stagePath  string
+	parameters map[string]string
+	body       []byte
+	now        time.Time
+	requestId  string
+}
+
+func invokeApi(response http.ResponseWriter, request *http.Request, apiId string, path string) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		log.Error("Unable to read body of request to API %s: %v", apiId, err)
+		respondWithError(response, http.StatusBadRequest, "", "Bad Request")
+		return
+	}
+
+	now := time.Now()
+	call := invocation{
+		request:   request,
+		apiId:     apiId,
+		path:      path,
+		stagePath: path,
+		body:      body,
+		now:       now,
+		requestId: fmt.Sprintf("%x", now.UnixNano()),
+	}
+
+	restApi, err := queries.RestApiById(ctx, db, apiId)
+	if err == nil {
+		invokeRestApi(response, db, restApi, call)
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	httpApi, err := queries.HttpApiById(ctx, db, apiId)
+	if err == nil {
+		invokeHttpApi(response, db, httpApi, call)
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	log.Error("There is no API with id %s", apiId)
+	respondWithError(response, http.StatusNotFound, "", "Not Found")
+}
+
+// invokeRestApi serves a request for a REST API, whose stage is always the first segment of the path.
+func invokeRestApi(response http.ResponseWriter, db *database.Database, api *types.RestApi, call invocation) {
+	ctx := call.request.Context()
+	parts := strings.SplitN(strings.TrimPrefix(call.path, "/"), "/", 2)
+	stage, err := queries.StageByName(ctx, db, api.ApiId, parts[0])
+	if err != nil {
+		log.Error("REST API %s has no stage %s", api.ApiId, parts[0])
+		respondWithError(response, http.StatusForbidden, "", "Forbidden")
+		return
+	}
+
+	call.stage = stage
+	call.path = "/"
+	if len(parts) == 2 {
+		call.path += parts[1]
+	}
+
+	resources, err := queries.Resources(ctx, db, api.ApiId)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	var resource *types.Resource
+	var method *types.Method
+	var best *match
+	for i := range resources {
+		candidate := resources[i].Methods[call.request.Method]
+		if candidate == nil {
+			candidate = resources[i].Methods[httpMethodAny]
+		}
+
+		if candidate == nil {
+			continue
+		}
+
+		result, ok := matchPath(resources[i].Path, call.path)
+		if ok && (best == nil || result.score > best.score) {
+			resource, method, best = &resources[i], candidate, result
+		}
+	}
+
+	if method == nil {
+		log.Error("No resource of REST API %s matches %s %s", api.ApiId, call.request.Method, call.path)
+		respondWithError(response, http.StatusForbidden, "", "Missing Authentication Token")
+		return
+	}
+
+	if method.AuthorizationType == authorizationTypeAwsIam && len(call.request.Header.Get("Authorization")) == 0 {
+		log.Error("Request to %s %s of REST API %s isn't signed", method.HttpMethod, resource.Path, api.ApiId)
+		respondWithError(response, http.StatusForbidden, "", "Missing Authentication Token")
+		return
+	}
+
+	integration := method.Integration
+	if integration == nil || integration.Type != integrationTypeAwsProxy {
+		log.Error("%s %s of REST API %s has no %s integration", method.HttpMethod, resource.Path, api.ApiId,
+			integrationTypeAwsProxy)
+		respondWithError(response, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	if len(best.parameters) > 0 {
+		call.parameters = best.parameters
+	}
+
+	event := buildProxyEvent(ctx, call, resource, isBinary(call.request, api.BinaryMediaTypes))
+	payload, err := invokeIntegration(ctx, call, integration.Uri, event)
+	if err != nil {
+		respondWithError(response, http.StatusBadGateway, "", "Internal server error")
+		return
+	}
+
+	writeProxyResponse(response, payload)
+}
+
+// invokeHttpApi serves a request for an HTTP API. Named stages are the first segment of the path, while the $default
+// stage is served without any prefix.
+func invokeHttpApi(response http.ResponseWriter, db *database.Database, api *types.HttpApi, call invocation) {
+	ctx := call.request.Context()
+	parts := strings.SplitN(strings.TrimPrefix(call.path, "/"), "/", 2)
+	stage, err := queries.StageByName(ctx, db, api.ApiId, parts[0])
+	if err == nil && stage.StageName != types.DefaultStageName {
+		call.path = "/"
+		if len(parts) == 2 {
+			call.path += parts[1]
+		}
+	} else {
+		stage, err = queries.StageByName(ctx, db, api.ApiId, types.DefaultStageName)
+	}
+
+	if err != nil {
+		log.Error("HTTP API %s has no stage for %s", api.ApiId, call.path)
+		respondWithError(response, http.StatusNotFound, "", "Not Found")
+		return
+	}
+
+	call.stage = stage
+	routes, err := queries.Routes(ctx, db, api.ApiId)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	route, result := matchRoute(routes, call.request.Method, call.path)
+	if route == nil {
+		log.Error("No route of HTTP API %s matches %s %s", api.ApiId, call.request.Method, call.path)
+		respondWithError(response, http.StatusNotFound, "", "Not Found")
+		return
+	}
+
+	if route.AuthorizationType == authorizationTypeAwsIam && len(call.request.Header.Get("Authorization")) == 0 {
+		log.Error("Request to %s of HTTP API %s isn't signed", route.RouteKey, api.ApiId)
+		respondWithError(response, http.StatusForbidden, "", "Forbidden")
+		return
+	}
+
+	integrationId := strings.TrimPrefix(route.Target, integrationTargetPrefix)
+	integration, err := queries.IntegrationById(ctx, db, api.ApiId, integrationId)
+	if err != nil || integration.IntegrationType != integrationTypeAwsProxy {
+		log.Error("Route %s of HTTP API %s has no %s integration", route.RouteKey, api.ApiId,
+			integrationTypeAwsProxy)
+		respondWithError(response, http.StatusInternalServerError, "", "Internal Server Error")
+		return
+	}
+
+	if len(result.parameters) > 0 {
+		call.parameters = result.parameters
+	}
+
+	if integration.PayloadFormatVersion == types.PayloadFormatVersion1 {
+		binary := !lambda.IsTextContent(call.request.Header.Get("Content-Type"))
+		resource := types.Resource{Path: routePath(route.RouteKey), ResourceId: route.RouteId}
+		event := buildProxyEvent(ctx, call, &resource, binary)
+		payload, err := invokeIntegration(ctx, call, integration.IntegrationUri, event)
+		if err != nil {
+			respondWithError(response, http.StatusInternalServerError, "", "Internal Server Error")
+			return
+		}
+
+		writeProxyResponse(response, payload)
+		return
+	}
+
+	event := buildHttpEvent(ctx, call, route.RouteKey)
+	payload, err := invokeIntegration(ctx, call, integration.IntegrationUri, event)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal Server Error")
+		return
+	}
+
+	err = lambda.WriteHttpResponse(response, payload)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, "", "Internal Server Error")
+	}
+}
+
+// routePath returns the path of the route key, or the greedy path that the $default route matches.
+func routePath(routeKey string) string {
+	if routeKey == types.DefaultRouteKey {
+		return "/{proxy+}"
+	}
+
+	return strings.SplitN(routeKey, " ", 2)[1]
+}
+
+// matchRoute returns the most specific route matching the method & path, preferring routes for the method over ANY
+// routes, and falling back to the $default route.
+func matchRoute(routes []types.Route, method string, path string) (*types.Route, *match) {
+	var route, fallback *types.Route
+	var best *match
+	for i := range routes {
+		if routes[i].RouteKey == types.DefaultRouteKey {
+			fallback = &routes[i]
+			continue
+		}
+
+		parts := strings.SplitN(routes[i].RouteKey, " ", 2)
+		if parts[0] != method && parts[0] != httpMethodAny {
+			continue
+		}
+
+		result, ok := matchPath(parts[1], path)
+		if !ok {
+			continue
+		}
+
+		result.score *= 2
+		if parts[0] == method {
+			result.score += 1
+		}
+
+		if best == nil || result.score > best.score {
+			route, best = &routes[i], result
+		}
+	}
+
+	if route == nil && fallback != nil {
+		return fallback, &match{}
+	}
+
+	return route, best
+}
+
+// invokeIntegration invokes the Function of the integration's URI with the event, returning what it responded with.
+func invokeIntegration(ctx context.Context, call invocation, uri string, event interface{}) ([]byte, error) {
+	uri = stageVariableRegex.ReplaceAllStringFunc(uri, func(variable string) string {
+		name := stageVariableRegex.FindStringSubmatch(variable)[1]
+		return call.stage.Variables[name]
+	})
+
+	groups := functionNameRegex.FindStringSubmatch(uri)
+	if groups == nil {
+		msg := log.Error("Unable to find the Function to invoke in %s", uri)
+		return nil, errors.New(msg)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		msg := log.Error("Unable to serialize event for API %s: %v", call.apiId, err)
+		return nil, errors.New(msg)
+	}
+
+	source := lambda.WithInvocationSource(ctx, "apigateway:"+call.apiId)
+	result, err := lambda.Invoke(source, groups[1], payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.FunctionError) > 0 {
+		msg := log.Error("Function %s failed to handle request to API %s: %s", groups[1], call.apiId, result.Payload)
+		return nil, errors.New(msg)
+	}
+
+	return result.Payload, nil
+}
+
+// isBinary reports whether the body of a request to a REST API is one of its binary media types, which are passed
+// to Functions base64 encoded.
+func isBinary(request *http.Request, binaryMediaTypes []string) bool {
+	contentType := strings.ToLower(strings.TrimSpace(strings.SplitN(request.Header.Get("Content-Type"), ";", 2)[0]))
+	for _, mediaType := range binaryMediaTypes {
+		mediaType = strings.ToLower(mediaType)
+		if mediaType == "*/*" || mediaType == contentType ||
+			(strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(mediaType, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeBody(body []byte, binary bool) (string, bool) {
+	if binary {
+		return base64.StdEncoding.EncodeToString(body), true
+	}
+
+	return string(body), false
+}
+
+func buildProxyEvent(ctx context.Context, call invocation, resource *types.Resource, binary bool) types.ProxyEvent {
+	cfg := settings.FromContext(ctx)
+	request := call.request
+
+	headers := make(map[string]string, len(request.Header))
+	multiValueHeaders := make(map[string][]string, len(request.Header))
+	for key, values := range request.Header {
+		headers[key] = values[len(values)-1]
+		multiValueHeaders[key] = values
+	}
+
+	var parameters map[string]string
+	var multiValueParameters map[string][]string
+	if query := request.URL.Query(); len(query) > 0 {
+		parameters = make(map[string]string, len(query))
+		multiValueParameters = query
+		for key, values := range query {
+			parameters[key] = values[len(values)-1]
+		}
+	}
+
+	event := types.ProxyEvent{
+		Resource:                        resource.Path,
+		Path:                            call.path,
+		HttpMethod:                      request.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiValueHeaders,
+		QueryStringParameters:           parameters,
+		MultiValueQueryStringParameters: multiValueParameters,
+		PathParameters:                  call.parameters,
+		StageVariables:                  call.stage.Variables,
+		RequestContext: types.ProxyRequestContext{
+			AccountId:    cfg.AccountNumber,
+			ApiId:        call.apiId,
+			DomainName:   request.Host,
+			DomainPrefix: call.apiId,
+			HttpMethod:   request.Method,
+			Identity: types.ProxyIdentity{
+				SourceIp:  lambda.SourceIp(request),
+				UserAgent: request.UserAgent(),
+			},
+			Path:             call.stagePath,
+			Protocol:         request.Proto,
+			RequestId:        call.requestId,
+			RequestTime:      call.now.Format(requestTimeFormat),
+			RequestTimeEpoch: call.now.UnixMilli(),
+			ResourceId:       resource.ResourceId,
+			ResourcePath:     resource.Path,
+			Stage:            call.stage.StageName,
+		},
+	}
+
+	if len(call.body) > 0 {
+		body, encoded := encodeBody(call.body, binary)
+		event.Body = &body
+		event.IsBase64Encoded = encoded
+	}
+
+	return event
+}
+
+func buildHttpEvent(ctx context.Context, call invocation, routeKey string) lambdaTypes.HttpEvent {
+	event := lambda.NewHttpEvent(ctx, call.request, call.body, call.apiId, call.stagePath, call.requestId, call.now)
+	event.RouteKey = routeKey
+	event.PathParameters = call.parameters
+	event.StageVariables = call.stage.Variables
+	event.RequestContext.RouteKey = routeKey
+	event.RequestContext.Stage = call.stage.StageName
+	return event
+}
+
+func writeBody(response http.ResponseWriter, statusCode int, body string, isBase64Encoded bool) {
+	bytes := []byte(body)
+	if isBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			log.Error("Unable to decode body returned by Function: %v", err)
+			respondWithError(response, http.StatusBadGateway, "", "Internal server error")
+			return
+		}
+		bytes = decoded
+	}
+
+	response.WriteHeader(statusCode)
+	response.Write(bytes)
+}
+
+// writeProxyResponse maps the response to a ProxyEvent back to HTTP, which must include a status code.
+func writeProxyResponse(response http.ResponseWriter, payload []byte) {
+	var fields map[string]json.RawMessage
+	var result types.ProxyResponse
+	err := json.Unmarshal(payload, &fields)
+	if err == nil {
+		err = json.Unmarshal(payload, &result)
+	}
+
+	if _, ok := fields["statusCode"]; err != nil || !ok {
+		log.Error("Malformed Lambda proxy response: %s", payload)
+		respondWithError(response, http.StatusBadGateway, "", "Internal server error")
+		return
+	}
+
+	header := response.Header()
+	for key, value := range result.Headers {
+		header.Set(key, value)
+	}
+
+	for key, values := range result.MultiValueHeaders {
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	writeBody(response, result.StatusCode, result.Body, result.IsBase64Encoded)
+}
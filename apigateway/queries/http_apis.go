@@ -0,0 +1,328 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+)
+
+func InsertHttpApi(ctx context.Context, db *database.Database, api *types.HttpApi) error {
+	var err error
+	api.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_http_api (api_id, name, description, protocol_type, route_selection_expression,
+					created_on)
+				VALUES (?, ?, ?, ?, ?, ?)
+		`,
+		api.ApiId,
+		api.Name,
+		api.Description,
+		api.ProtocolType,
+		api.RouteSelectionExpression,
+		api.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save HTTP API %s: %v", api.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectHttpApi = `SELECT id, api_id, name, description, protocol_type, route_selection_expression, created_on
+				FROM apigateway_http_api`
+
+func HttpApis(ctx context.Context, db *database.Database) ([]types.HttpApi, error) {
+	rows, err := db.QueryContext(ctx, selectHttpApi+` ORDER BY id`)
+	if err != nil {
+		msg := log.Error("Unable to query HTTP APIs: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.HttpApi{}
+	for rows.Next() {
+		api, err := scanHttpApi(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *api)
+	}
+
+	return results, nil
+}
+
+// HttpApiById returns the HTTP API, or sql.ErrNoRows when there is no such API.
+func HttpApiById(ctx context.Context, db *database.Database, apiId string) (*types.HttpApi, error) {
+	row := db.QueryRowContext(ctx, selectHttpApi+` WHERE api_id = ?`, apiId)
+	return scanHttpApi(row)
+}
+
+func scanHttpApi(row scanner) (*types.HttpApi, error) {
+	var api types.HttpApi
+	err := row.Scan(
+		&api.ID,
+		&api.ApiId,
+		&api.Name,
+		&api.Description,
+		&api.ProtocolType,
+		&api.RouteSelectionExpression,
+		&api.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan HTTP API: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &api, nil
+}
+
+func UpdateHttpApi(ctx context.Context, db *database.Database, api *types.HttpApi) error {
+	_, err := db.ExecContext(
+		ctx,
+		`UPDATE apigateway_http_api SET name = ?, description = ?, route_selection_expression = ? WHERE id = ?`,
+		api.Name,
+		api.Description,
+		api.RouteSelectionExpression,
+		api.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update HTTP API %s: %v", api.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// DeleteHttpApi deletes the HTTP API along with its integrations, routes, deployments & stages.
+func DeleteHttpApi(ctx context.Context, db *database.Database, apiId string) error {
+	return deleteAll(ctx, db, []string{
+		`DELETE FROM apigateway_route WHERE api_id = ?`,
+		`DELETE FROM apigateway_integration WHERE api_id = ?`,
+		`DELETE FROM apigateway_stage WHERE api_id = ?`,
+		`DELETE FROM apigateway_deployment WHERE api_id = ?`,
+		`DELETE FROM apigateway_http_api WHERE api_id = ?`,
+	}, apiId)
+}
+
+func InsertIntegration(ctx context.Context, db *database.Database, integration *types.Integration) error {
+	var err error
+	integration.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_integration (api_id, integration_id, integration_type, integration_uri,
+					integration_method, payload_format_version, timeout)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+		`,
+		integration.ApiId,
+		integration.IntegrationId,
+		integration.IntegrationType,
+		integration.IntegrationUri,
+		integration.IntegrationMethod,
+		integration.PayloadFormatVersion,
+		integration.TimeoutInMillis,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save integration of HTTP API %s: %v", integration.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectIntegration = `SELECT id, api_id, integration_id, integration_type, integration_uri, integration_method,
+					payload_format_version, timeout
+				FROM apigateway_integration`
+
+func Integrations(ctx context.Context, db *database.Database, apiId string) ([]types.Integration, error) {
+	rows, err := db.QueryContext(ctx, selectIntegration+` WHERE api_id = ? ORDER BY id`, apiId)
+	if err != nil {
+		msg := log.Error("Unable to query integrations of HTTP API %s: %v", apiId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Integration{}
+	for rows.Next() {
+		integration, err := scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *integration)
+	}
+
+	return results, nil
+}
+
+// IntegrationById returns the integration of the HTTP API, or sql.ErrNoRows when there is no such integration.
+func IntegrationById(ctx context.Context, db *database.Database, apiId string, integrationId string) (*types.Integration, error) {
+	row := db.QueryRowContext(ctx, selectIntegration+` WHERE api_id = ? AND integration_id = ?`, apiId, integrationId)
+	return scanIntegration(row)
+}
+
+func scanIntegration(row scanner) (*types.Integration, error) {
+	var integration types.Integration
+	err := row.Scan(
+		&integration.ID,
+		&integration.ApiId,
+		&integration.IntegrationId,
+		&integration.IntegrationType,
+		&integration.IntegrationUri,
+		&integration.IntegrationMethod,
+		&integration.PayloadFormatVersion,
+		&integration.TimeoutInMillis,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan integration: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &integration, nil
+}
+
+func UpdateIntegration(ctx context.Context, db *database.Database, integration *types.Integration) error {
+	_, err := db.ExecContext(
+		ctx,
+		`UPDATE apigateway_integration SET integration_type = ?, integration_uri = ?, integration_method = ?,
+					payload_format_version = ?, timeout = ?
+				WHERE id = ?
+		`,
+		integration.IntegrationType,
+		integration.IntegrationUri,
+		integration.IntegrationMethod,
+		integration.PayloadFormatVersion,
+		integration.TimeoutInMillis,
+		integration.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update integration %s: %v", integration.IntegrationId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func DeleteIntegration(ctx context.Context, db *database.Database, integration *types.Integration) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM apigateway_integration WHERE id = ?`, integration.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete integration %s: %v", integration.IntegrationId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func InsertRoute(ctx context.Context, db *database.Database, route *types.Route) error {
+	var err error
+	route.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_route (api_id, route_id, route_key, target, authorization_type) VALUES (?, ?, ?, ?, ?)`,
+		route.ApiId,
+		route.RouteId,
+		route.RouteKey,
+		route.Target,
+		route.AuthorizationType,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save route %s of HTTP API %s: %v", route.RouteKey, route.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectRoute = `SELECT id, api_id, route_id, route_key, target, authorization_type FROM apigateway_route`
+
+func Routes(ctx context.Context, db *database.Database, apiId string) ([]types.Route, error) {
+	rows, err := db.QueryContext(ctx, selectRoute+` WHERE api_id = ? ORDER BY id`, apiId)
+	if err != nil {
+		msg := log.Error("Unable to query routes of HTTP API %s: %v", apiId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Route{}
+	for rows.Next() {
+		route, err := scanRoute(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *route)
+	}
+
+	return results, nil
+}
+
+// RouteById returns the route of the HTTP API, or sql.ErrNoRows when there is no such route.
+func RouteById(ctx context.Context, db *database.Database, apiId string, routeId string) (*types.Route, error) {
+	row := db.QueryRowContext(ctx, selectRoute+` WHERE api_id = ? AND route_id = ?`, apiId, routeId)
+	return scanRoute(row)
+}
+
+func scanRoute(row scanner) (*types.Route, error) {
+	var route types.Route
+	err := row.Scan(
+		&route.ID,
+		&route.ApiId,
+		&route.RouteId,
+		&route.RouteKey,
+		&route.Target,
+		&route.AuthorizationType,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan route: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &route, nil
+}
+
+func UpdateRoute(ctx context.Context, db *database.Database, route *types.Route) error {
+	_, err := db.ExecContext(
+		ctx,
+		`UPDATE apigateway_route SET route_key = ?, target = ?, authorization_type = ? WHERE id = ?`,
+		route.RouteKey,
+		route.Target,
+		route.AuthorizationType,
+		route.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update route %s: %v", route.RouteId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func DeleteRoute(ctx context.Context, db *database.Database, route *types.Route) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM apigateway_route WHERE id = ?`, route.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete route %s: %v", route.RouteId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
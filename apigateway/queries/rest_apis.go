@@ -0,0 +1,390 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+)
+
+// InsertRestApi saves the REST API along with its root resource.
+func InsertRestApi(ctx context.Context, db *database.Database, api *types.RestApi, root *types.Resource) error {
+	binaryMediaTypes, err := toJsonColumn(api.BinaryMediaTypes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction for REST API %s: %v", api.Name, err)
+		return errors.New(msg)
+	}
+
+	api.ID, err = tx.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_rest_api (api_id, name, description, root_resource_id, binary_media_types, created_on)
+				VALUES (?, ?, ?, ?, ?, ?)
+		`,
+		api.ApiId,
+		api.Name,
+		api.Description,
+		api.RootResourceId,
+		binaryMediaTypes,
+		api.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save REST API %s: %v", api.Name, err)
+		return errors.New(msg)
+	}
+
+	root.ID, err = tx.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_resource (api_id, resource_id, parent_id, path_part, path) VALUES (?, ?, ?, ?, ?)`,
+		root.ApiId,
+		root.ResourceId,
+		root.ParentId,
+		root.PathPart,
+		root.Path,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save root resource of REST API %s: %v", api.Name, err)
+		return errors.New(msg)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit REST API %s: %v", api.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectRestApi = `SELECT id, api_id, name, description, root_resource_id, binary_media_types, created_on
+				FROM apigateway_rest_api`
+
+func RestApis(ctx context.Context, db *database.Database) ([]types.RestApi, error) {
+	rows, err := db.QueryContext(ctx, selectRestApi+` ORDER BY id`)
+	if err != nil {
+		msg := log.Error("Unable to query REST APIs: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.RestApi{}
+	for rows.Next() {
+		api, err := scanRestApi(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *api)
+	}
+
+	return results, nil
+}
+
+// RestApiById returns the REST API, or sql.ErrNoRows when there is no such API.
+func RestApiById(ctx context.Context, db *database.Database, apiId string) (*types.RestApi, error) {
+	row := db.QueryRowContext(ctx, selectRestApi+` WHERE api_id = ?`, apiId)
+	return scanRestApi(row)
+}
+
+func scanRestApi(row scanner) (*types.RestApi, error) {
+	var api types.RestApi
+	var binaryMediaTypes string
+	err := row.Scan(
+		&api.ID,
+		&api.ApiId,
+		&api.Name,
+		&api.Description,
+		&api.RootResourceId,
+		&binaryMediaTypes,
+		&api.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan REST API: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	err = fromJsonColumn(binaryMediaTypes, &api.BinaryMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api, nil
+}
+
+func UpdateRestApi(ctx context.Context, db *database.Database, api *types.RestApi) error {
+	binaryMediaTypes, err := toJsonColumn(api.BinaryMediaTypes)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`UPDATE apigateway_rest_api SET name = ?, description = ?, binary_media_types = ? WHERE id = ?`,
+		api.Name,
+		api.Description,
+		binaryMediaTypes,
+		api.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update REST API %s: %v", api.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// DeleteRestApi deletes the REST API along with its resources, methods, deployments & stages.
+func DeleteRestApi(ctx context.Context, db *database.Database, apiId string) error {
+	return deleteAll(ctx, db, []string{
+		`DELETE FROM apigateway_method WHERE api_id = ?`,
+		`DELETE FROM apigateway_resource WHERE api_id = ?`,
+		`DELETE FROM apigateway_stage WHERE api_id = ?`,
+		`DELETE FROM apigateway_deployment WHERE api_id = ?`,
+		`DELETE FROM apigateway_rest_api WHERE api_id = ?`,
+	}, apiId)
+}
+
+func InsertResource(ctx context.Context, db *database.Database, resource *types.Resource) error {
+	var err error
+	resource.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_resource (api_id, resource_id, parent_id, path_part, path) VALUES (?, ?, ?, ?, ?)`,
+		resource.ApiId,
+		resource.ResourceId,
+		resource.ParentId,
+		resource.PathPart,
+		resource.Path,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save resource %s of REST API %s: %v", resource.Path, resource.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectResource = `SELECT id, api_id, resource_id, parent_id, path_part, path FROM apigateway_resource`
+
+// Resources returns all resources of the REST API along with their methods.
+func Resources(ctx context.Context, db *database.Database, apiId string) ([]types.Resource, error) {
+	rows, err := db.QueryContext(ctx, selectResource+` WHERE api_id = ? ORDER BY path`, apiId)
+	if err != nil {
+		msg := log.Error("Unable to query resources of REST API %s: %v", apiId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Resource{}
+	for rows.Next() {
+		resource, err := scanResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *resource)
+	}
+	rows.Close()
+
+	methods, err := methods(ctx, db, `api_id = ?`, apiId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Methods = methods[results[i].ResourceId]
+	}
+
+	return results, nil
+}
+
+// ResourceById returns the resource of the REST API along with its methods, or sql.ErrNoRows when there is no such
+// resource.
+func ResourceById(ctx context.Context, db *database.Database, apiId string, resourceId string) (*types.Resource, error) {
+	row := db.QueryRowContext(ctx, selectResource+` WHERE api_id = ? AND resource_id = ?`, apiId, resourceId)
+	resource, err := scanResource(row)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := methods(ctx, db, `resource_id = ?`, resourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	resource.Methods = methods[resourceId]
+	return resource, nil
+}
+
+func scanResource(row scanner) (*types.Resource, error) {
+	var resource types.Resource
+	err := row.Scan(
+		&resource.ID,
+		&resource.ApiId,
+		&resource.ResourceId,
+		&resource.ParentId,
+		&resource.PathPart,
+		&resource.Path,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan resource: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &resource, nil
+}
+
+// DeleteResource deletes the resource along with its descendants and all of their methods.
+func DeleteResource(ctx context.Context, db *database.Database, resource *types.Resource) error {
+	return deleteAll(ctx, db, []string{
+		`DELETE FROM apigateway_method WHERE resource_id IN (
+			SELECT resource_id FROM apigateway_resource WHERE api_id = ?1 AND (path = ?2 OR path LIKE ?2 || '/%')
+		)`,
+		`DELETE FROM apigateway_resource WHERE api_id = ?1 AND (path = ?2 OR path LIKE ?2 || '/%')`,
+	}, resource.ApiId, resource.Path)
+}
+
+func InsertMethod(ctx context.Context, db *database.Database, method *types.Method) error {
+	var err error
+	method.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_method (api_id, resource_id, http_method, authorization_type, api_key_required)
+				VALUES (?, ?, ?, ?, ?)
+		`,
+		method.ApiId,
+		method.ResourceId,
+		method.HttpMethod,
+		method.AuthorizationType,
+		method.ApiKeyRequired,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save method %s of resource %s: %v", method.HttpMethod, method.ResourceId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// MethodByResource returns the method of the resource, or sql.ErrNoRows when there is no such method.
+func MethodByResource(ctx context.Context, db *database.Database, resourceId string, httpMethod string) (*types.Method, error) {
+	row := db.QueryRowContext(ctx, selectMethod+` WHERE resource_id = ? AND http_method = ?`, resourceId, httpMethod)
+	return scanMethod(row)
+}
+
+const selectMethod = `SELECT id, api_id, resource_id, http_method, authorization_type, api_key_required,
+					integration_type, integration_http_method, integration_uri, integration_timeout
+				FROM apigateway_method`
+
+// methods returns the methods matching the condition, by HTTP method by resource ID.
+func methods(ctx context.Context, db *database.Database, condition string, args ...interface{}) (map[string]map[string]*types.Method, error) {
+	rows, err := db.QueryContext(ctx, selectMethod+` WHERE `+condition, args...)
+	if err != nil {
+		msg := log.Error("Unable to query methods: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := make(map[string]map[string]*types.Method)
+	for rows.Next() {
+		method, err := scanMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := results[method.ResourceId]; !ok {
+			results[method.ResourceId] = make(map[string]*types.Method)
+		}
+		results[method.ResourceId][method.HttpMethod] = method
+	}
+
+	return results, nil
+}
+
+func scanMethod(row scanner) (*types.Method, error) {
+	var method types.Method
+	var integration types.MethodIntegration
+	err := row.Scan(
+		&method.ID,
+		&method.ApiId,
+		&method.ResourceId,
+		&method.HttpMethod,
+		&method.AuthorizationType,
+		&method.ApiKeyRequired,
+		&integration.Type,
+		&integration.HttpMethod,
+		&integration.Uri,
+		&integration.TimeoutInMillis,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan method: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	if len(integration.Type) > 0 {
+		method.Integration = &integration
+	}
+
+	return &method, nil
+}
+
+func UpdateMethod(ctx context.Context, db *database.Database, method *types.Method) error {
+	integration := types.MethodIntegration{TimeoutInMillis: 29000}
+	if method.Integration != nil {
+		integration = *method.Integration
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`UPDATE apigateway_method SET authorization_type = ?, api_key_required = ?, integration_type = ?,
+					integration_http_method = ?, integration_uri = ?, integration_timeout = ?
+				WHERE id = ?
+		`,
+		method.AuthorizationType,
+		method.ApiKeyRequired,
+		integration.Type,
+		integration.HttpMethod,
+		integration.Uri,
+		integration.TimeoutInMillis,
+		method.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update method %s of resource %s: %v", method.HttpMethod, method.ResourceId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func DeleteMethod(ctx context.Context, db *database.Database, method *types.Method) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM apigateway_method WHERE id = ?`, method.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete method %s of resource %s: %v", method.HttpMethod, method.ResourceId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+)
+
+// scanner is either a sql.Row or sql.Rows, so that a single function scans a table's columns for both.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func toJsonColumn(value interface{}) (string, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		msg := log.Error("Unable to serialize %+v: %v", value, err)
+		return "", errors.New(msg)
+	}
+
+	return string(bytes), nil
+}
+
+func fromJsonColumn(column string, value interface{}) error {
+	if len(column) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal([]byte(column), value)
+	if err != nil {
+		msg := log.Error("Unable to parse %s: %v", column, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// deleteAll runs each of the deletes with the same arguments in a single transaction.
+func deleteAll(ctx context.Context, db *database.Database, deletes []string, args ...interface{}) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction: %v", err)
+		return errors.New(msg)
+	}
+
+	for _, query := range deletes {
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			msg := tx.Rollback("Unable to delete with %s: %v", query, err)
+			log.Error(msg)
+			return errors.New(msg)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit transaction: %v", err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
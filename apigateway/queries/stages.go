@@ -0,0 +1,219 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+)
+
+func InsertDeployment(ctx context.Context, db *database.Database, deployment *types.Deployment) error {
+	var err error
+	deployment.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_deployment (api_id, deployment_id, description, created_on) VALUES (?, ?, ?, ?)`,
+		deployment.ApiId,
+		deployment.DeploymentId,
+		deployment.Description,
+		deployment.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save deployment of API %s: %v", deployment.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectDeployment = `SELECT id, api_id, deployment_id, description, created_on FROM apigateway_deployment`
+
+func Deployments(ctx context.Context, db *database.Database, apiId string) ([]types.Deployment, error) {
+	rows, err := db.QueryContext(ctx, selectDeployment+` WHERE api_id = ? ORDER BY id`, apiId)
+	if err != nil {
+		msg := log.Error("Unable to query deployments of API %s: %v", apiId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Deployment{}
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *deployment)
+	}
+
+	return results, nil
+}
+
+// DeploymentById returns the deployment of the API, or sql.ErrNoRows when there is no such deployment.
+func DeploymentById(ctx context.Context, db *database.Database, apiId string, deploymentId string) (*types.Deployment, error) {
+	row := db.QueryRowContext(ctx, selectDeployment+` WHERE api_id = ? AND deployment_id = ?`, apiId, deploymentId)
+	return scanDeployment(row)
+}
+
+func scanDeployment(row scanner) (*types.Deployment, error) {
+	var deployment types.Deployment
+	err := row.Scan(
+		&deployment.ID,
+		&deployment.ApiId,
+		&deployment.DeploymentId,
+		&deployment.Description,
+		&deployment.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan deployment: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &deployment, nil
+}
+
+func DeleteDeployment(ctx context.Context, db *database.Database, deployment *types.Deployment) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM apigateway_deployment WHERE id = ?`, deployment.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete deployment %s: %v", deployment.DeploymentId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func InsertStage(ctx context.Context, db *database.Database, stage *types.Stage) error {
+	variables, err := toJsonColumn(stage.Variables)
+	if err != nil {
+		return err
+	}
+
+	stage.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO apigateway_stage (api_id, stage_name, deployment_id, description, variables, auto_deploy,
+					created_on, last_updated_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+		stage.ApiId,
+		stage.StageName,
+		stage.DeploymentId,
+		stage.Description,
+		variables,
+		stage.AutoDeploy,
+		stage.CreatedOn,
+		stage.LastUpdatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save stage %s of API %s: %v", stage.StageName, stage.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectStage = `SELECT id, api_id, stage_name, deployment_id, description, variables, auto_deploy, created_on,
+					last_updated_on
+				FROM apigateway_stage`
+
+func Stages(ctx context.Context, db *database.Database, apiId string) ([]types.Stage, error) {
+	rows, err := db.QueryContext(ctx, selectStage+` WHERE api_id = ? ORDER BY id`, apiId)
+	if err != nil {
+		msg := log.Error("Unable to query stages of API %s: %v", apiId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Stage{}
+	for rows.Next() {
+		stage, err := scanStage(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *stage)
+	}
+
+	return results, nil
+}
+
+// StageByName returns the stage of the API, or sql.ErrNoRows when there is no such stage.
+func StageByName(ctx context.Context, db *database.Database, apiId string, stageName string) (*types.Stage, error) {
+	row := db.QueryRowContext(ctx, selectStage+` WHERE api_id = ? AND stage_name = ?`, apiId, stageName)
+	return scanStage(row)
+}
+
+func scanStage(row scanner) (*types.Stage, error) {
+	var stage types.Stage
+	var variables string
+	err := row.Scan(
+		&stage.ID,
+		&stage.ApiId,
+		&stage.StageName,
+		&stage.DeploymentId,
+		&stage.Description,
+		&variables,
+		&stage.AutoDeploy,
+		&stage.CreatedOn,
+		&stage.LastUpdatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan stage: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	err = fromJsonColumn(variables, &stage.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stage, nil
+}
+
+func UpdateStage(ctx context.Context, db *database.Database, stage *types.Stage) error {
+	variables, err := toJsonColumn(stage.Variables)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`UPDATE apigateway_stage SET deployment_id = ?, description = ?, variables = ?, auto_deploy = ?,
+					last_updated_on = ?
+				WHERE id = ?
+		`,
+		stage.DeploymentId,
+		stage.Description,
+		variables,
+		stage.AutoDeploy,
+		stage.LastUpdatedOn,
+		stage.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update stage %s of API %s: %v", stage.StageName, stage.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func DeleteStage(ctx context.Context, db *database.Database, stage *types.Stage) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM apigateway_stage WHERE id = ?`, stage.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete stage %s of API %s: %v", stage.StageName, stage.ApiId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
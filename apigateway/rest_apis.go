@@ -0,0 +1,611 @@
+package apigateway
+
+import (
+	"database/sql"
+	"myaws/apigateway/queries"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiIdLength      = 10
+	resourceIdLength = 6
+
+	integrationTypeAwsProxy  = "AWS_PROXY"
+	defaultTimeoutInMillis   = 29000
+	authorizationTypeNone    = "NONE"
+	authorizationTypeAwsIam  = "AWS_IAM"
+	httpMethodAny            = "ANY"
+	restApiResourcesPosition = 4
+)
+
+// findRestApi writes an error response & returns nil when the REST API in the path doesn't exist.
+func findRestApi(response http.ResponseWriter, request *http.Request, db *database.Database) *types.RestApi {
+	apiId := pathPart(request, 2)
+	api, err := queries.RestApiById(request.Context(), db, apiId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid API identifier specified %s", apiId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return api
+}
+
+// findResource writes an error response & returns nil when the resource in the path doesn't exist.
+func findResource(response http.ResponseWriter, request *http.Request, db *database.Database) *types.Resource {
+	apiId := pathPart(request, 2)
+	resourceId := pathPart(request, restApiResourcesPosition)
+	resource, err := queries.ResourceById(request.Context(), db, apiId, resourceId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid Resource identifier specified %s", resourceId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return resource
+}
+
+// findMethod writes an error response & returns nil when the method in the path doesn't exist.
+func findMethod(response http.ResponseWriter, request *http.Request, db *database.Database) *types.Method {
+	resourceId := pathPart(request, restApiResourcesPosition)
+	httpMethod := pathPart(request, 6)
+	method, err := queries.MethodByResource(request.Context(), db, resourceId, httpMethod)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid Method identifier specified %s %s", resourceId, httpMethod)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return method
+}
+
+const RestApisRegex = `^/restapis/?$`
+
+func CreateRestApi(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateRestApiInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.Name) == 0 {
+		msg := log.Error("Name is required to create a REST API")
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := types.RestApi{
+		ApiId:            utils.RandomId(apiIdLength),
+		Name:             body.Name,
+		Description:      body.Description,
+		RootResourceId:   utils.RandomId(resourceIdLength),
+		BinaryMediaTypes: body.BinaryMediaTypes,
+		CreatedOn:        time.Now().UnixMilli(),
+	}
+
+	root := types.Resource{
+		ApiId:      api.ApiId,
+		ResourceId: api.RootResourceId,
+		Path:       "/",
+	}
+
+	err := queries.InsertRestApi(ctx, db, &api, &root)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, api.ToOutput())
+}
+
+func GetRestApis(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	apis, err := queries.RestApis(ctx, db)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]types.RestApiOutput, len(apis))
+	for i, api := range apis {
+		items[i] = api.ToOutput()
+	}
+
+	utils.RespondWithJson(response, map[string][]types.RestApiOutput{"item": items})
+}
+
+const RestApiRegex = `^/restapis/[a-z0-9]+$`
+
+func GetRestApi(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findRestApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, api.ToOutput())
+}
+
+func UpdateRestApi(response http.ResponseWriter, request *http.Request) {
+	var body types.PatchInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findRestApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	for _, operation := range body.PatchOperations {
+		path := unescapePatchPath(operation.Path)
+		switch {
+		case path == "/name":
+			api.Name = operation.Value
+		case path == "/description":
+			api.Description = operation.Value
+		case strings.HasPrefix(path, "/binaryMediaTypes/") && operation.Op == "add":
+			api.BinaryMediaTypes = append(api.BinaryMediaTypes, strings.TrimPrefix(path, "/binaryMediaTypes/"))
+		case strings.HasPrefix(path, "/binaryMediaTypes/") && operation.Op == "remove":
+			api.BinaryMediaTypes = without(api.BinaryMediaTypes, strings.TrimPrefix(path, "/binaryMediaTypes/"))
+		default:
+			log.Info("Ignoring unsupported patch of REST API %s: %+v", api.ApiId, operation)
+		}
+	}
+
+	err := queries.UpdateRestApi(ctx, db, api)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, api.ToOutput())
+}
+
+func without(values []string, value string) []string {
+	var results []string
+	for _, v := range values {
+		if v != value {
+			results = append(results, v)
+		}
+	}
+
+	return results
+}
+
+func DeleteRestApi(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findRestApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	err := queries.DeleteRestApi(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+}
+
+const ResourcesRegex = `^/restapis/[a-z0-9]+/resources/?$`
+
+func GetResources(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findRestApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	resources, err := queries.Resources(ctx, db, api.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]types.ResourceOutput, len(resources))
+	for i, resource := range resources {
+		items[i] = resource.ToOutput()
+	}
+
+	utils.RespondWithJson(response, map[string][]types.ResourceOutput{"item": items})
+}
+
+const ResourceRegex = `^/restapis/[a-z0-9]+/resources/[a-z0-9]+$`
+
+// CreateResource creates a child of the resource in the path.
+func CreateResource(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateResourceInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.PathPart) == 0 || strings.Contains(body.PathPart, "/") {
+		msg := log.Error("Resource's path part %q must be a single, non-empty segment", body.PathPart)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	parent := findResource(response, request, db)
+	if parent == nil {
+		return
+	}
+
+	resource := types.Resource{
+		ApiId:      parent.ApiId,
+		ResourceId: utils.RandomId(resourceIdLength),
+		ParentId:   parent.ResourceId,
+		PathPart:   body.PathPart,
+		Path:       strings.TrimSuffix(parent.Path, "/") + "/" + body.PathPart,
+	}
+
+	siblings, err := queries.Resources(ctx, db, parent.ApiId)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, sibling := range siblings {
+		if sibling.Path == resource.Path {
+			msg := log.Error("Another resource with the same parent already has this name: %s", body.PathPart)
+			respondWithError(response, http.StatusConflict, errorTypeConflict, msg)
+			return
+		}
+	}
+
+	err = queries.InsertResource(ctx, db, &resource)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, resource.ToOutput())
+}
+
+func GetResource(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	resource := findResource(response, request, db)
+	if resource == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, resource.ToOutput())
+}
+
+func DeleteResource(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	resource := findResource(response, request, db)
+	if resource == nil {
+		return
+	}
+
+	if len(resource.ParentId) == 0 {
+		msg := log.Error("Cannot delete the root resource %s", resource.ResourceId)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	err := queries.DeleteResource(ctx, db, resource)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+}
+
+const MethodRegex = `^/restapis/[a-z0-9]+/resources/[a-z0-9]+/methods/[A-Z]+$`
+
+func PutMethod(response http.ResponseWriter, request *http.Request) {
+	var body types.PutMethodInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	resource := findResource(response, request, db)
+	if resource == nil {
+		return
+	}
+
+	httpMethod := pathPart(request, 6)
+	if _, ok := resource.Methods[httpMethod]; ok {
+		msg := log.Error("Method %s already exists for resource %s", httpMethod, resource.Path)
+		respondWithError(response, http.StatusConflict, errorTypeConflict, msg)
+		return
+	}
+
+	method := types.Method{
+		ApiId:             resource.ApiId,
+		ResourceId:        resource.ResourceId,
+		HttpMethod:        httpMethod,
+		AuthorizationType: body.AuthorizationType,
+		ApiKeyRequired:    body.ApiKeyRequired,
+	}
+
+	if len(method.AuthorizationType) == 0 {
+		method.AuthorizationType = authorizationTypeNone
+	}
+
+	err := queries.InsertMethod(ctx, db, &method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, method.ToOutput())
+}
+
+func GetMethod(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findMethod(response, request, db)
+	if method == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, method.ToOutput())
+}
+
+func UpdateMethod(response http.ResponseWriter, request *http.Request) {
+	var body types.PatchInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findMethod(response, request, db)
+	if method == nil {
+		return
+	}
+
+	for _, operation := range body.PatchOperations {
+		switch operation.Path {
+		case "/authorizationType":
+			method.AuthorizationType = operation.Value
+		case "/apiKeyRequired":
+			method.ApiKeyRequired = operation.Value == "true"
+		default:
+			log.Info("Ignoring unsupported patch of method %s: %+v", method.HttpMethod, operation)
+		}
+	}
+
+	err := queries.UpdateMethod(ctx, db, method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, method.ToOutput())
+}
+
+func DeleteMethod(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findMethod(response, request, db)
+	if method == nil {
+		return
+	}
+
+	err := queries.DeleteMethod(ctx, db, method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+const IntegrationRegex = `^/restapis/[a-z0-9]+/resources/[a-z0-9]+/methods/[A-Z]+/integration$`
+
+func PutIntegration(response http.ResponseWriter, request *http.Request) {
+	var body types.PutIntegrationInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.Type) == 0 {
+		msg := log.Error("Enumeration value for IntegrationType must be non-empty")
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	if body.Type != integrationTypeAwsProxy {
+		log.Info("WARNING: Only %s integrations are invoked, %s integrations are only saved", integrationTypeAwsProxy,
+			body.Type)
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findMethod(response, request, db)
+	if method == nil {
+		return
+	}
+
+	method.Integration = &types.MethodIntegration{
+		Type:            body.Type,
+		HttpMethod:      body.HttpMethod,
+		Uri:             body.Uri,
+		TimeoutInMillis: utils.Int32OrDefault(body.TimeoutInMillis, defaultTimeoutInMillis),
+	}
+
+	err := queries.UpdateMethod(ctx, db, method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJson(response, http.StatusCreated, method.Integration.ToOutput(method.ResourceId))
+}
+
+// findIntegration writes an error response & returns nil when the method in the path has no integration.
+func findIntegration(response http.ResponseWriter, request *http.Request, db *database.Database) *types.Method {
+	method := findMethod(response, request, db)
+	if method == nil {
+		return nil
+	}
+
+	if method.Integration == nil {
+		msg := log.Error("No integration defined for method %s", method.HttpMethod)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil
+	}
+
+	return method
+}
+
+func GetIntegration(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findIntegration(response, request, db)
+	if method == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, method.Integration.ToOutput(method.ResourceId))
+}
+
+func UpdateIntegration(response http.ResponseWriter, request *http.Request) {
+	var body types.PatchInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findIntegration(response, request, db)
+	if method == nil {
+		return
+	}
+
+	integration := method.Integration
+	for _, operation := range body.PatchOperations {
+		switch operation.Path {
+		case "/uri":
+			integration.Uri = operation.Value
+		case "/httpMethod":
+			integration.HttpMethod = operation.Value
+		case "/timeoutInMillis":
+			timeout, err := strconv.Atoi(operation.Value)
+			if err != nil {
+				msg := log.Error("Invalid timeout %s: %v", operation.Value, err)
+				respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+				return
+			}
+			integration.TimeoutInMillis = int32(timeout)
+		default:
+			log.Info("Ignoring unsupported patch of integration for %s: %+v", method.HttpMethod, operation)
+		}
+	}
+
+	err := queries.UpdateMethod(ctx, db, method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, integration.ToOutput(method.ResourceId))
+}
+
+func DeleteIntegration(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	method := findIntegration(response, request, db)
+	if method == nil {
+		return
+	}
+
+	method.Integration = nil
+	err := queries.UpdateMethod(ctx, db, method)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package apigateway
+
+import (
+	"strings"
+)
+
+// match is a resource or route whose path template matched a request's path.
+type match struct {
+	parameters map[string]string
+	score      int
+}
+
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	return strings.Split(trimmed, "/")
+}
+
+// matchPath matches the path against a template like /pets/{id} or /files/{proxy+}, returning the values of its
+// parameters. More specific templates score higher: literal segments win over parameters, which win over greedy
+// parameters, the way API Gateway picks between resources or routes matching the same path.
+func matchPath(template string, path string) (*match, bool) {
+	templateParts := splitPath(template)
+	pathParts := splitPath(path)
+	result := match{parameters: make(map[string]string)}
+
+	for i, part := range templateParts {
+		isParameter := strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}")
+		if isParameter && strings.HasSuffix(part, "+}") {
+			if i != len(templateParts)-1 || i >= len(pathParts) {
+				return nil, false
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(part, "{"), "+}")
+			result.parameters[name] = strings.Join(pathParts[i:], "/")
+			return &result, true
+		}
+
+		if i >= len(pathParts) {
+			return nil, false
+		}
+
+		if isParameter {
+			result.parameters[strings.Trim(part, "{}")] = pathParts[i]
+			result.score += 10
+		} else if part == pathParts[i] {
+			result.score += 100
+		} else {
+			return nil, false
+		}
+	}
+
+	if len(templateParts) != len(pathParts) {
+		return nil, false
+	}
+
+	// greedy matches never get the bonus for matching the whole path
+	result.score += 1
+	return &result, true
+}
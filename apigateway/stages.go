@@ -0,0 +1,486 @@
+package apigateway
+
+import (
+	"database/sql"
+	"myaws/apigateway/queries"
+	"myaws/apigateway/types"
+	"myaws/database"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const deploymentIdLength = 6
+
+// apiRef identifies the REST or HTTP API in the path of requests for deployments & stages, which both kinds of APIs
+// have.
+type apiRef struct {
+	id     string
+	isHttp bool
+}
+
+// childId returns the ID of the deployment or name of the stage in the path.
+func (api apiRef) childId(request *http.Request) string {
+	if api.isHttp {
+		return pathPart(request, 5)
+	}
+
+	return pathPart(request, 4)
+}
+
+// findApi writes an error response & returns nil when the REST or HTTP API in the path doesn't exist.
+func findApi(response http.ResponseWriter, request *http.Request, db *database.Database) *apiRef {
+	if strings.HasPrefix(request.URL.Path, "/v2/") {
+		httpApi := findHttpApi(response, request, db)
+		if httpApi == nil {
+			return nil
+		}
+		return &apiRef{id: httpApi.ApiId, isHttp: true}
+	}
+
+	restApi := findRestApi(response, request, db)
+	if restApi == nil {
+		return nil
+	}
+	return &apiRef{id: restApi.ApiId}
+}
+
+// findStage writes an error response & returns nil when the stage in the path doesn't exist.
+func findStage(response http.ResponseWriter, request *http.Request, db *database.Database) (*apiRef, *types.Stage) {
+	api := findApi(response, request, db)
+	if api == nil {
+		return nil, nil
+	}
+
+	name := api.childId(request)
+	stage, err := queries.StageByName(request.Context(), db, api.id, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid stage identifier specified %s", name)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil, nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+
+	return api, stage
+}
+
+func deploymentOutput(api *apiRef, deployment types.Deployment) interface{} {
+	if api.isHttp {
+		return deployment.ToHttpOutput()
+	}
+
+	return deployment.ToRestOutput()
+}
+
+func stageOutput(api *apiRef, stage types.Stage) interface{} {
+	if api.isHttp {
+		return stage.ToHttpOutput()
+	}
+
+	return stage.ToRestOutput()
+}
+
+// listOutput wraps the items of a list the way REST or HTTP APIs do.
+func listOutput(api *apiRef, items []interface{}) map[string][]interface{} {
+	if api.isHttp {
+		return map[string][]interface{}{"items": items}
+	}
+
+	return map[string][]interface{}{"item": items}
+}
+
+const (
+	RestDeploymentsRegex = `^/restapis/[a-z0-9]+/deployments/?$`
+	HttpDeploymentsRegex = `^/v2/apis/[a-z0-9]+/deployments/?$`
+)
+
+// CreateDeployment deploys the API, optionally to a stage that is created when it doesn't exist yet. Unlike API Gateway,
+// deployments don't snapshot the API: stages serve its current resources, routes & integrations, so changes are live
+// without redeploying. Deployments only record which stages exist & what they were last deployed with.
+func CreateDeployment(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateDeploymentInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	deployment := types.Deployment{
+		ApiId:        api.id,
+		DeploymentId: utils.RandomId(deploymentIdLength),
+		Description:  body.Description,
+		CreatedOn:    now,
+	}
+
+	err := queries.InsertDeployment(ctx, db, &deployment)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(body.StageName) > 0 {
+		stage, err := queries.StageByName(ctx, db, api.id, body.StageName)
+		switch {
+		case err == sql.ErrNoRows:
+			stage = &types.Stage{
+				ApiId:        api.id,
+				StageName:    body.StageName,
+				DeploymentId: deployment.DeploymentId,
+				Description:  body.StageDescription,
+				Variables:    body.Variables,
+				CreatedOn:    now,
+			}
+			stage.LastUpdatedOn = now
+			err = queries.InsertStage(ctx, db, stage)
+		case err == nil:
+			stage.DeploymentId = deployment.DeploymentId
+			stage.LastUpdatedOn = now
+			err = queries.UpdateStage(ctx, db, stage)
+		}
+
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("Stage %s of API %s can be invoked at %s", body.StageName, api.id,
+			types.InvokeUrl(ctx, api.id, body.StageName))
+	}
+
+	respondWithJson(response, http.StatusCreated, deploymentOutput(api, deployment))
+}
+
+func GetDeployments(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	deployments, err := queries.Deployments(ctx, db, api.id)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]interface{}, len(deployments))
+	for i, deployment := range deployments {
+		items[i] = deploymentOutput(api, deployment)
+	}
+
+	utils.RespondWithJson(response, listOutput(api, items))
+}
+
+const (
+	RestDeploymentRegex = `^/restapis/[a-z0-9]+/deployments/[a-z0-9]+$`
+	HttpDeploymentRegex = `^/v2/apis/[a-z0-9]+/deployments/[a-z0-9]+$`
+)
+
+// findDeployment writes an error response & returns nil when the deployment in the path doesn't exist.
+func findDeployment(response http.ResponseWriter, request *http.Request, db *database.Database) (*apiRef, *types.Deployment) {
+	api := findApi(response, request, db)
+	if api == nil {
+		return nil, nil
+	}
+
+	deploymentId := api.childId(request)
+	deployment, err := queries.DeploymentById(request.Context(), db, api.id, deploymentId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Invalid Deployment identifier specified %s", deploymentId)
+		respondWithError(response, http.StatusNotFound, errorTypeNotFound, msg)
+		return nil, nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+
+	return api, deployment
+}
+
+func GetDeployment(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api, deployment := findDeployment(response, request, db)
+	if deployment == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, deploymentOutput(api, *deployment))
+}
+
+func DeleteDeployment(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api, deployment := findDeployment(response, request, db)
+	if deployment == nil {
+		return
+	}
+
+	stages, err := queries.Stages(ctx, db, api.id)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, stage := range stages {
+		if stage.DeploymentId == deployment.DeploymentId {
+			msg := log.Error("Active stages pointing to this deployment must be moved or deleted: %s", stage.StageName)
+			respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+			return
+		}
+	}
+
+	err = queries.DeleteDeployment(ctx, db, deployment)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if api.isHttp {
+		response.WriteHeader(http.StatusNoContent)
+	} else {
+		response.WriteHeader(http.StatusAccepted)
+	}
+}
+
+const (
+	RestStagesRegex = `^/restapis/[a-z0-9]+/stages/?$`
+	HttpStagesRegex = `^/v2/apis/[a-z0-9]+/stages/?$`
+)
+
+func CreateStage(response http.ResponseWriter, request *http.Request) {
+	var body types.StageInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.StageName) == 0 {
+		msg := log.Error("StageName is required to create a stage")
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	if !api.isHttp && len(body.DeploymentId) == 0 {
+		msg := log.Error("DeploymentId is required to create a stage of REST API %s", api.id)
+		respondWithError(response, http.StatusBadRequest, errorTypeBadRequest, msg)
+		return
+	}
+
+	_, err := queries.StageByName(ctx, db, api.id, body.StageName)
+	if err == nil {
+		msg := log.Error("Stage already exists: %s", body.StageName)
+		respondWithError(response, http.StatusConflict, errorTypeConflict, msg)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	stage := types.Stage{
+		ApiId:         api.id,
+		StageName:     body.StageName,
+		DeploymentId:  body.DeploymentId,
+		Description:   body.Description,
+		Variables:     body.Variables,
+		AutoDeploy:    body.AutoDeploy != nil && *body.AutoDeploy,
+		CreatedOn:     now,
+		LastUpdatedOn: now,
+	}
+
+	if api.isHttp {
+		stage.Variables = body.StageVariables
+	}
+
+	err = queries.InsertStage(ctx, db, &stage)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Stage %s of API %s can be invoked at %s", stage.StageName, api.id,
+		types.InvokeUrl(ctx, api.id, stage.StageName))
+
+	respondWithJson(response, http.StatusCreated, stageOutput(api, stage))
+}
+
+func GetStages(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api := findApi(response, request, db)
+	if api == nil {
+		return
+	}
+
+	stages, err := queries.Stages(ctx, db, api.id)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]interface{}, len(stages))
+	for i, stage := range stages {
+		items[i] = stageOutput(api, stage)
+	}
+
+	utils.RespondWithJson(response, listOutput(api, items))
+}
+
+const (
+	RestStageRegex = `^/restapis/[a-z0-9]+/stages/[A-Za-z0-9_$-]+$`
+	HttpStageRegex = `^/v2/apis/[a-z0-9]+/stages/[A-Za-z0-9_$-]+$`
+)
+
+func GetStage(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api, stage := findStage(response, request, db)
+	if stage == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, stageOutput(api, *stage))
+}
+
+// UpdateStage updates stages of REST APIs with patch operations, and those of HTTP APIs with the changed fields.
+func UpdateStage(response http.ResponseWriter, request *http.Request) {
+	var patch types.PatchInput
+	var body types.StageInput
+	var ok bool
+	if strings.HasPrefix(request.URL.Path, "/v2/") {
+		ok = decodeBody(response, request, &body)
+	} else {
+		ok = decodeBody(response, request, &patch)
+	}
+
+	if !ok {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api, stage := findStage(response, request, db)
+	if stage == nil {
+		return
+	}
+
+	if api.isHttp {
+		applyStageInput(stage, body)
+	} else {
+		applyStagePatch(stage, patch)
+	}
+
+	stage.LastUpdatedOn = time.Now().UnixMilli()
+	err := queries.UpdateStage(ctx, db, stage)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, stageOutput(api, *stage))
+}
+
+func applyStageInput(stage *types.Stage, body types.StageInput) {
+	if len(body.DeploymentId) > 0 {
+		stage.DeploymentId = body.DeploymentId
+	}
+
+	if len(body.Description) > 0 {
+		stage.Description = body.Description
+	}
+
+	if body.StageVariables != nil {
+		stage.Variables = body.StageVariables
+	}
+
+	if body.AutoDeploy != nil {
+		stage.AutoDeploy = *body.AutoDeploy
+	}
+}
+
+func applyStagePatch(stage *types.Stage, patch types.PatchInput) {
+	for _, operation := range patch.PatchOperations {
+		path := unescapePatchPath(operation.Path)
+		switch {
+		case path == "/deploymentId":
+			stage.DeploymentId = operation.Value
+		case path == "/description":
+			stage.Description = operation.Value
+		case strings.HasPrefix(path, "/variables/") && operation.Op == "remove":
+			delete(stage.Variables, strings.TrimPrefix(path, "/variables/"))
+		case strings.HasPrefix(path, "/variables/"):
+			if stage.Variables == nil {
+				stage.Variables = make(map[string]string)
+			}
+			stage.Variables[strings.TrimPrefix(path, "/variables/")] = operation.Value
+		default:
+			log.Info("Ignoring unsupported patch of stage %s: %+v", stage.StageName, operation)
+		}
+	}
+}
+
+func DeleteStage(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	api, stage := findStage(response, request, db)
+	if stage == nil {
+		return
+	}
+
+	err := queries.DeleteStage(ctx, db, stage)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if api.isHttp {
+		response.WriteHeader(http.StatusNoContent)
+	} else {
+		response.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,49 @@
+package types
+
+// ProxyEvent is the event that Functions receive from REST APIs, and from HTTP API integrations using payload format
+// version 1.0.
+type ProxyEvent struct {
+	Resource                        string              `json:"resource"`
+	Path                            string              `json:"path"`
+	HttpMethod                      string              `json:"httpMethod"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string   `json:"pathParameters"`
+	StageVariables                  map[string]string   `json:"stageVariables"`
+	RequestContext                  ProxyRequestContext `json:"requestContext"`
+	Body                            *string             `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+type ProxyIdentity struct {
+	SourceIp  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+type ProxyRequestContext struct {
+	AccountId        string        `json:"accountId"`
+	ApiId            string        `json:"apiId"`
+	DomainName       string        `json:"domainName"`
+	DomainPrefix     string        `json:"domainPrefix"`
+	HttpMethod       string        `json:"httpMethod"`
+	Identity         ProxyIdentity `json:"identity"`
+	Path             string        `json:"path"`
+	Protocol         string        `json:"protocol"`
+	RequestId        string        `json:"requestId"`
+	RequestTime      string        `json:"requestTime"`
+	RequestTimeEpoch int64         `json:"requestTimeEpoch"`
+	ResourceId       string        `json:"resourceId"`
+	ResourcePath     string        `json:"resourcePath"`
+	Stage            string        `json:"stage"`
+}
+
+// ProxyResponse is what Functions return for ProxyEvents.
+type ProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
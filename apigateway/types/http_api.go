@@ -0,0 +1,134 @@
+package types
+
+import "context"
+
+const (
+	ProtocolTypeHttp = "HTTP"
+
+	PayloadFormatVersion1 = "1.0"
+	PayloadFormatVersion2 = "2.0"
+
+	// DefaultRouteKey is the key of the route that matches requests no other route does.
+	DefaultRouteKey = "$default"
+)
+
+// CreateApiInput creates an HTTP API. When Target is set, the API is quick created with a $default route & stage that
+// integrate with the target.
+type CreateApiInput struct {
+	Name                     string `json:"name"`
+	Description              string `json:"description"`
+	ProtocolType             string `json:"protocolType"`
+	RouteSelectionExpression string `json:"routeSelectionExpression"`
+	RouteKey                 string `json:"routeKey"`
+	Target                   string `json:"target"`
+}
+
+type HttpApi struct {
+	ID                       int64
+	ApiId                    string
+	Name                     string
+	Description              string
+	ProtocolType             string
+	RouteSelectionExpression string
+	CreatedOn                int64
+}
+
+type HttpApiOutput struct {
+	ApiId                     string            `json:"apiId"`
+	Name                      string            `json:"name"`
+	Description               string            `json:"description,omitempty"`
+	ProtocolType              string            `json:"protocolType"`
+	RouteSelectionExpression  string            `json:"routeSelectionExpression"`
+	ApiKeySelectionExpression string            `json:"apiKeySelectionExpression"`
+	ApiEndpoint               string            `json:"apiEndpoint"`
+	CreatedDate               string            `json:"createdDate"`
+	DisableExecuteApiEndpoint bool              `json:"disableExecuteApiEndpoint"`
+	Tags                      map[string]string `json:"tags"`
+}
+
+func (api HttpApi) ToOutput(ctx context.Context) HttpApiOutput {
+	return HttpApiOutput{
+		ApiId:                     api.ApiId,
+		Name:                      api.Name,
+		Description:               api.Description,
+		ProtocolType:              api.ProtocolType,
+		RouteSelectionExpression:  api.RouteSelectionExpression,
+		ApiKeySelectionExpression: "$request.header.x-api-key",
+		ApiEndpoint:               InvokeUrl(ctx, api.ApiId, DefaultStageName),
+		CreatedDate:               millisToIso8601(api.CreatedOn),
+		Tags:                      map[string]string{},
+	}
+}
+
+type IntegrationInput struct {
+	IntegrationType      string `json:"integrationType"`
+	IntegrationUri       string `json:"integrationUri"`
+	IntegrationMethod    string `json:"integrationMethod"`
+	PayloadFormatVersion string `json:"payloadFormatVersion"`
+	TimeoutInMillis      *int32 `json:"timeoutInMillis"`
+}
+
+type Integration struct {
+	ID                   int64
+	ApiId                string
+	IntegrationId        string
+	IntegrationType      string
+	IntegrationUri       string
+	IntegrationMethod    string
+	PayloadFormatVersion string
+	TimeoutInMillis      int32
+}
+
+type IntegrationOutput struct {
+	IntegrationId        string `json:"integrationId"`
+	IntegrationType      string `json:"integrationType"`
+	IntegrationUri       string `json:"integrationUri,omitempty"`
+	IntegrationMethod    string `json:"integrationMethod,omitempty"`
+	PayloadFormatVersion string `json:"payloadFormatVersion"`
+	TimeoutInMillis      int32  `json:"timeoutInMillis"`
+	ConnectionType       string `json:"connectionType"`
+}
+
+func (integration Integration) ToOutput() IntegrationOutput {
+	return IntegrationOutput{
+		IntegrationId:        integration.IntegrationId,
+		IntegrationType:      integration.IntegrationType,
+		IntegrationUri:       integration.IntegrationUri,
+		IntegrationMethod:    integration.IntegrationMethod,
+		PayloadFormatVersion: integration.PayloadFormatVersion,
+		TimeoutInMillis:      integration.TimeoutInMillis,
+		ConnectionType:       "INTERNET",
+	}
+}
+
+type RouteInput struct {
+	RouteKey          string `json:"routeKey"`
+	Target            string `json:"target"`
+	AuthorizationType string `json:"authorizationType"`
+}
+
+type Route struct {
+	ID                int64
+	ApiId             string
+	RouteId           string
+	RouteKey          string
+	Target            string
+	AuthorizationType string
+}
+
+type RouteOutput struct {
+	RouteId           string `json:"routeId"`
+	RouteKey          string `json:"routeKey"`
+	Target            string `json:"target,omitempty"`
+	AuthorizationType string `json:"authorizationType"`
+	ApiKeyRequired    bool   `json:"apiKeyRequired"`
+}
+
+func (route Route) ToOutput() RouteOutput {
+	return RouteOutput{
+		RouteId:           route.RouteId,
+		RouteKey:          route.RouteKey,
+		Target:            route.Target,
+		AuthorizationType: route.AuthorizationType,
+	}
+}
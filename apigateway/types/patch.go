@@ -0,0 +1,13 @@
+package types
+
+// PatchOperation is a change to a REST API resource, which are updated with JSON Patch-like operations.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	From  string `json:"from"`
+}
+
+type PatchInput struct {
+	PatchOperations []PatchOperation `json:"patchOperations"`
+}
@@ -0,0 +1,180 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"myaws/settings"
+)
+
+type CreateRestApiInput struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	BinaryMediaTypes []string `json:"binaryMediaTypes"`
+}
+
+type RestApi struct {
+	ID               int64
+	ApiId            string
+	Name             string
+	Description      string
+	RootResourceId   string
+	BinaryMediaTypes []string
+	CreatedOn        int64
+}
+
+type EndpointConfiguration struct {
+	Types []string `json:"types"`
+}
+
+type RestApiOutput struct {
+	Id                        string                `json:"id"`
+	Name                      string                `json:"name"`
+	Description               string                `json:"description,omitempty"`
+	CreatedDate               int64                 `json:"createdDate"`
+	RootResourceId            string                `json:"rootResourceId"`
+	ApiKeySource              string                `json:"apiKeySource"`
+	BinaryMediaTypes          []string              `json:"binaryMediaTypes,omitempty"`
+	EndpointConfiguration     EndpointConfiguration `json:"endpointConfiguration"`
+	DisableExecuteApiEndpoint bool                  `json:"disableExecuteApiEndpoint"`
+	Tags                      map[string]string     `json:"tags"`
+}
+
+func (api RestApi) ToOutput() RestApiOutput {
+	return RestApiOutput{
+		Id:                    api.ApiId,
+		Name:                  api.Name,
+		Description:           api.Description,
+		CreatedDate:           millisToSeconds(api.CreatedOn),
+		RootResourceId:        api.RootResourceId,
+		ApiKeySource:          "HEADER",
+		BinaryMediaTypes:      api.BinaryMediaTypes,
+		EndpointConfiguration: EndpointConfiguration{Types: []string{"EDGE"}},
+		Tags:                  map[string]string{},
+	}
+}
+
+// InvokeUrl returns the URL that invokes the API's stage. It uses a subdomain of localhost like API Gateway uses a
+// subdomain of amazonaws.com, which resolves to myaws without any DNS setup.
+func InvokeUrl(ctx context.Context, apiId string, stageName string) string {
+	cfg := settings.FromContext(ctx)
+	url := fmt.Sprintf("%s://%s.execute-api.%s.localhost:%d", cfg.HTTP.Protocol, apiId, cfg.Region, cfg.HTTP.Port)
+	if stageName != DefaultStageName {
+		url += "/" + stageName
+	}
+
+	return url
+}
+
+type CreateResourceInput struct {
+	PathPart string `json:"pathPart"`
+}
+
+type Resource struct {
+	ID         int64
+	ApiId      string
+	ResourceId string
+	ParentId   string
+	PathPart   string
+	Path       string
+	Methods    map[string]*Method
+}
+
+type ResourceOutput struct {
+	Id              string                  `json:"id"`
+	ParentId        string                  `json:"parentId,omitempty"`
+	PathPart        string                  `json:"pathPart,omitempty"`
+	Path            string                  `json:"path"`
+	ResourceMethods map[string]MethodOutput `json:"resourceMethods,omitempty"`
+}
+
+func (resource Resource) ToOutput() ResourceOutput {
+	output := ResourceOutput{
+		Id:       resource.ResourceId,
+		ParentId: resource.ParentId,
+		PathPart: resource.PathPart,
+		Path:     resource.Path,
+	}
+
+	if len(resource.Methods) > 0 {
+		output.ResourceMethods = make(map[string]MethodOutput, len(resource.Methods))
+		for httpMethod, method := range resource.Methods {
+			output.ResourceMethods[httpMethod] = method.ToOutput()
+		}
+	}
+
+	return output
+}
+
+type PutMethodInput struct {
+	AuthorizationType string `json:"authorizationType"`
+	ApiKeyRequired    bool   `json:"apiKeyRequired"`
+}
+
+// Method is an HTTP method of a REST API's resource, along with its integration since each method has at most one.
+type Method struct {
+	ID                int64
+	ApiId             string
+	ResourceId        string
+	HttpMethod        string
+	AuthorizationType string
+	ApiKeyRequired    bool
+	Integration       *MethodIntegration
+}
+
+type MethodOutput struct {
+	HttpMethod        string                   `json:"httpMethod"`
+	AuthorizationType string                   `json:"authorizationType"`
+	ApiKeyRequired    bool                     `json:"apiKeyRequired"`
+	MethodIntegration *MethodIntegrationOutput `json:"methodIntegration,omitempty"`
+}
+
+func (method Method) ToOutput() MethodOutput {
+	output := MethodOutput{
+		HttpMethod:        method.HttpMethod,
+		AuthorizationType: method.AuthorizationType,
+		ApiKeyRequired:    method.ApiKeyRequired,
+	}
+
+	if method.Integration != nil {
+		integration := method.Integration.ToOutput(method.ResourceId)
+		output.MethodIntegration = &integration
+	}
+
+	return output
+}
+
+type PutIntegrationInput struct {
+	Type            string `json:"type"`
+	HttpMethod      string `json:"httpMethod"`
+	Uri             string `json:"uri"`
+	TimeoutInMillis *int32 `json:"timeoutInMillis"`
+}
+
+type MethodIntegration struct {
+	Type            string
+	HttpMethod      string
+	Uri             string
+	TimeoutInMillis int32
+}
+
+type MethodIntegrationOutput struct {
+	Type                string   `json:"type"`
+	HttpMethod          string   `json:"httpMethod,omitempty"`
+	Uri                 string   `json:"uri,omitempty"`
+	PassthroughBehavior string   `json:"passthroughBehavior"`
+	TimeoutInMillis     int32    `json:"timeoutInMillis"`
+	CacheNamespace      string   `json:"cacheNamespace"`
+	CacheKeyParameters  []string `json:"cacheKeyParameters"`
+}
+
+func (integration MethodIntegration) ToOutput(resourceId string) MethodIntegrationOutput {
+	return MethodIntegrationOutput{
+		Type:                integration.Type,
+		HttpMethod:          integration.HttpMethod,
+		Uri:                 integration.Uri,
+		PassthroughBehavior: "WHEN_NO_MATCH",
+		TimeoutInMillis:     integration.TimeoutInMillis,
+		CacheNamespace:      resourceId,
+		CacheKeyParameters:  []string{},
+	}
+}
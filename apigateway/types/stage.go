@@ -0,0 +1,128 @@
+package types
+
+// DefaultStageName is the name of the HTTP API stage that is served without a stage prefix in its path.
+const DefaultStageName = "$default"
+
+// Deployment is a deployment of either a REST or HTTP API. myaws always serves the current configuration of an API, so
+// deployments only exist for stages to refer to.
+type Deployment struct {
+	ID           int64
+	ApiId        string
+	DeploymentId string
+	Description  string
+	CreatedOn    int64
+}
+
+type CreateDeploymentInput struct {
+	Description      string            `json:"description"`
+	StageName        string            `json:"stageName"`
+	StageDescription string            `json:"stageDescription"`
+	Variables        map[string]string `json:"variables"`
+}
+
+type RestDeploymentOutput struct {
+	Id          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	CreatedDate int64  `json:"createdDate"`
+}
+
+func (deployment Deployment) ToRestOutput() RestDeploymentOutput {
+	return RestDeploymentOutput{
+		Id:          deployment.DeploymentId,
+		Description: deployment.Description,
+		CreatedDate: millisToSeconds(deployment.CreatedOn),
+	}
+}
+
+type HttpDeploymentOutput struct {
+	DeploymentId     string `json:"deploymentId"`
+	DeploymentStatus string `json:"deploymentStatus"`
+	Description      string `json:"description,omitempty"`
+	CreatedDate      string `json:"createdDate"`
+	AutoDeployed     bool   `json:"autoDeployed"`
+}
+
+func (deployment Deployment) ToHttpOutput() HttpDeploymentOutput {
+	return HttpDeploymentOutput{
+		DeploymentId:     deployment.DeploymentId,
+		DeploymentStatus: "DEPLOYED",
+		Description:      deployment.Description,
+		CreatedDate:      millisToIso8601(deployment.CreatedOn),
+	}
+}
+
+// StageInput creates or updates stages of both REST & HTTP APIs, which name the stage's variables differently.
+type StageInput struct {
+	StageName      string            `json:"stageName"`
+	DeploymentId   string            `json:"deploymentId"`
+	Description    string            `json:"description"`
+	Variables      map[string]string `json:"variables"`
+	StageVariables map[string]string `json:"stageVariables"`
+	AutoDeploy     *bool             `json:"autoDeploy"`
+}
+
+type Stage struct {
+	ID            int64
+	ApiId         string
+	StageName     string
+	DeploymentId  string
+	Description   string
+	Variables     map[string]string
+	AutoDeploy    bool
+	CreatedOn     int64
+	LastUpdatedOn int64
+}
+
+type RestStageOutput struct {
+	StageName           string                 `json:"stageName"`
+	DeploymentId        string                 `json:"deploymentId"`
+	Description         string                 `json:"description,omitempty"`
+	CreatedDate         int64                  `json:"createdDate"`
+	LastUpdatedDate     int64                  `json:"lastUpdatedDate"`
+	Variables           map[string]string      `json:"variables,omitempty"`
+	MethodSettings      map[string]interface{} `json:"methodSettings"`
+	CacheClusterEnabled bool                   `json:"cacheClusterEnabled"`
+	TracingEnabled      bool                   `json:"tracingEnabled"`
+	Tags                map[string]string      `json:"tags"`
+}
+
+func (stage Stage) ToRestOutput() RestStageOutput {
+	return RestStageOutput{
+		StageName:       stage.StageName,
+		DeploymentId:    stage.DeploymentId,
+		Description:     stage.Description,
+		CreatedDate:     millisToSeconds(stage.CreatedOn),
+		LastUpdatedDate: millisToSeconds(stage.LastUpdatedOn),
+		Variables:       stage.Variables,
+		MethodSettings:  map[string]interface{}{},
+		Tags:            map[string]string{},
+	}
+}
+
+type HttpStageOutput struct {
+	StageName            string                 `json:"stageName"`
+	DeploymentId         string                 `json:"deploymentId,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	CreatedDate          string                 `json:"createdDate"`
+	LastUpdatedDate      string                 `json:"lastUpdatedDate"`
+	StageVariables       map[string]string      `json:"stageVariables,omitempty"`
+	AutoDeploy           bool                   `json:"autoDeploy"`
+	DefaultRouteSettings map[string]interface{} `json:"defaultRouteSettings"`
+	RouteSettings        map[string]interface{} `json:"routeSettings"`
+	Tags                 map[string]string      `json:"tags"`
+}
+
+func (stage Stage) ToHttpOutput() HttpStageOutput {
+	return HttpStageOutput{
+		StageName:            stage.StageName,
+		DeploymentId:         stage.DeploymentId,
+		Description:          stage.Description,
+		CreatedDate:          millisToIso8601(stage.CreatedOn),
+		LastUpdatedDate:      millisToIso8601(stage.LastUpdatedOn),
+		StageVariables:       stage.Variables,
+		AutoDeploy:           stage.AutoDeploy,
+		DefaultRouteSettings: map[string]interface{}{},
+		RouteSettings:        map[string]interface{}{},
+		Tags:                 map[string]string{},
+	}
+}
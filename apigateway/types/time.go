@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// REST APIs return timestamps as seconds since the epoch, while HTTP APIs return them as ISO 8601 strings.
+const iso8601Format = "2006-01-02T15:04:05Z"
+
+func millisToSeconds(ms int64) int64 {
+	return ms / 1000
+}
+
+func millisToIso8601(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(iso8601Format)
+}
@@ -75,6 +75,10 @@ func (db *Database) Exec(query string, args ...interface{}) (sql.Result, error)
 	return db.wrapped.Exec(query, args...)
 }
 
+func (db *Database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.wrapped.ExecContext(ctx, query, args...)
+}
+
 func (db *Database) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	return db.wrapped.QueryContext(ctx, query, args...)
 }
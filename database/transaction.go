@@ -15,6 +15,10 @@ func (tx *Transaction) Commit() error {
 	return tx.wrapped.Commit()
 }
 
+func (tx *Transaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.wrapped.ExecContext(ctx, query, args...)
+}
+
 func (tx *Transaction) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
 	return tx.wrapped.PrepareContext(ctx, query)
 }
@@ -12,8 +12,15 @@ type Container struct {
 	ID          string
 	Mounts      []mount.Mount
 	Ports       map[int]int
+	Entrypoint  []string
 	Command     []string
+	WorkingDir  string
 	Environment []string
+	ExtraHosts  []string
+	User        string
+
+	// Output is called with each line the container writes to stdout or stderr, when it's set.
+	Output func(line string)
 }
 
 func (c Container) String() string {
@@ -7,9 +7,12 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
 	"myaws/log"
 	"myaws/utils"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +25,17 @@ func init() {
 type Docker struct {
 	cli     *client.Client
 	running map[string]Container
+
+	runningMutex sync.Mutex
+
+	pullsMutex sync.Mutex
+	pulls      map[string]*imagePull
+}
+
+// imagePull serializes pulls of a single image, so that pulling one image does not hold up others.
+type imagePull struct {
+	mutex  sync.Mutex
+	pulled bool
 }
 
 func NewController() *Docker {
@@ -31,14 +45,49 @@ func NewController() *Docker {
 	}
 
 	running := make(map[string]Container, 5)
-	return &Docker{cli: cli, running: running}
+	pulls := make(map[string]*imagePull)
+	return &Docker{cli: cli, running: running, pulls: pulls}
 }
 
 func EnsureImage(ctx context.Context, image string) {
+	err := PullImage(ctx, image)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+// PullImageOnce pulls the image the first time it is requested, so that images are only downloaded once they are
+// needed. Subsequent calls for the same image return immediately.
+func PullImageOnce(ctx context.Context, image string) error {
+	instance.pullsMutex.Lock()
+	pull, ok := instance.pulls[image]
+	if !ok {
+		pull = &imagePull{}
+		instance.pulls[image] = pull
+	}
+	instance.pullsMutex.Unlock()
+
+	pull.mutex.Lock()
+	defer pull.mutex.Unlock()
+
+	if pull.pulled {
+		return nil
+	}
+
+	err := PullImage(ctx, image)
+	if err != nil {
+		return err
+	}
+
+	pull.pulled = true
+	return nil
+}
+
+func PullImage(ctx context.Context, image string) error {
 	reader, err := instance.cli.ImagePull(ctx, image, types.ImagePullOptions{})
 	if err != nil {
 		msg := log.Error("Error when ensuring image %s exists: %v", image, err)
-		panic(msg)
+		return errors.New(msg)
 	}
 
 	defer reader.Close()
@@ -53,6 +102,19 @@ func EnsureImage(ctx context.Context, image string) {
 
 		log.Info("[DOCKER] %s", progress)
 	}
+
+	return nil
+}
+
+// InspectImage returns the configuration baked into a local image, such as its entrypoint and command.
+func InspectImage(ctx context.Context, image string) (*container.Config, error) {
+	inspect, _, err := instance.cli.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		msg := log.Error("Unable to inspect image %s: %v", image, err)
+		return nil, errors.New(msg)
+	}
+
+	return inspect.Config, nil
 }
 
 func Start(ctx context.Context, c Container, ready string) (chan bool, error) {
@@ -65,13 +127,17 @@ func Start(ctx context.Context, c Container, ready string) (chan bool, error) {
 	hostConfig := container.HostConfig{}
 	hostConfig.Mounts = c.Mounts
 	hostConfig.PortBindings = portMap
+	hostConfig.ExtraHosts = c.ExtraHosts
 
 	containerConfig := container.Config{
 		ExposedPorts: portSet,
 		Tty:          false,
+		Entrypoint:   c.Entrypoint,
 		Cmd:          c.Command,
+		WorkingDir:   c.WorkingDir,
 		Image:        c.Image,
 		Env:          c.Environment,
+		User:         c.User,
 	}
 
 	resp, err := instance.cli.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, c.Name)
@@ -87,7 +153,9 @@ func Start(ctx context.Context, c Container, ready string) (chan bool, error) {
 	}
 
 	c.ID = resp.ID
+	instance.runningMutex.Lock()
 	instance.running[c.Name] = c
+	instance.runningMutex.Unlock()
 
 	readyChan := make(chan bool)
 	go func() {
@@ -101,10 +169,23 @@ func Start(ctx context.Context, c Container, ready string) (chan bool, error) {
 		}
 		defer reader.Close()
 
-		lines := utils.ReadLinesAsBytes(reader)
+		// the container doesn't have a TTY, so its stdout & stderr are multiplexed in its logs
+		output, writer := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(writer, writer, reader)
+			if err != nil {
+				log.Error("Unable to read logs of container %s: %v", c, err)
+			}
+			writer.Close()
+		}()
+
+		lines := utils.ReadLinesAsBytes(output)
 		for line := range lines {
 			text := string(line)
 			log.Info("[DOCKER %s] %s", c.Name, text)
+			if c.Output != nil {
+				c.Output(text)
+			}
 			if len(ready) > 0 && strings.Contains(text, ready) {
 				readyChan <- true
 				close(readyChan)
@@ -127,7 +208,9 @@ func Shutdown(ctx context.Context, c Container) error {
 		return errors.New(msg)
 	}
 
+	instance.runningMutex.Lock()
 	delete(instance.running, c.Name)
+	instance.runningMutex.Unlock()
 
 	err = instance.cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{})
 	if err != nil {
@@ -138,9 +221,30 @@ func Shutdown(ctx context.Context, c Container) error {
 	return nil
 }
 
+// Stop shuts down and removes the running container with the given name, if there is one.
+func Stop(ctx context.Context, name string) error {
+	instance.runningMutex.Lock()
+	c, ok := instance.running[name]
+	instance.runningMutex.Unlock()
+
+	if !ok {
+		log.Info("Container %s is not running", name)
+		return nil
+	}
+
+	return Shutdown(ctx, c)
+}
+
 func ShutdownAll(ctx context.Context) error {
-	var allErrors []string
+	instance.runningMutex.Lock()
+	containers := make([]Container, 0, len(instance.running))
 	for _, c := range instance.running {
+		containers = append(containers, c)
+	}
+	instance.runningMutex.Unlock()
+
+	var allErrors []string
+	for _, c := range containers {
 		err := Shutdown(ctx, c)
 		if err != nil {
 			allErrors = append(allErrors, err.Error())
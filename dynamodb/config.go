@@ -0,0 +1,41 @@
+package dynamodb
+
+import (
+	"errors"
+	"github.com/docker/docker/api/types/mount"
+	"myaws/docker"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"path/filepath"
+)
+
+const Image = "amazon/dynamodb-local:1.18.0"
+
+func Container(cfg *settings.Config) (*docker.Container, error) {
+	basePath := filepath.Join(cfg.DataPath(), "dynamodb")
+	err := utils.CreateDirs(basePath)
+	if err != nil {
+		msg := log.Error("Unable to create directory %s: %v", basePath, err)
+		return nil, errors.New(msg)
+	}
+
+	// a shared DB keeps every region & access key in a single file, since requests are signed by whoever calls myaws
+	return &docker.Container{
+		Name:  "dynamodb",
+		Image: Image,
+		Mounts: []mount.Mount{
+			{
+				Source: basePath,
+				Target: "/data",
+				Type:   mount.TypeBind,
+			},
+		},
+		Ports: map[int]int{
+			8000: cfg.DynamoDB.Port,
+		},
+		Command: []string{"-jar", "DynamoDBLocal.jar", "-sharedDb", "-dbPath", "/data"},
+		// the image's user can't write to directories mounted from the host
+		User: "root",
+	}, nil
+}
@@ -0,0 +1,271 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"myaws/database"
+	"myaws/dynamodb/queries"
+	"myaws/dynamodb/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"strings"
+)
+
+const (
+	errorTypePrefix           = "com.amazonaws.dynamodb.v20120810#"
+	errorTypeResourceNotFound = "ResourceNotFoundException"
+	errorTypeValidation       = "ValidationException"
+	errorTypeInternal         = "InternalServerError"
+)
+
+type errorBody struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func respondWithError(response http.ResponseWriter, statusCode int, errorType string, message string) {
+	response.Header().Set("Content-Type", contentType)
+	response.WriteHeader(statusCode)
+
+	json.NewEncoder(response).Encode(errorBody{Type: errorTypePrefix + errorType, Message: message})
+}
+
+func respondWithJson(response http.ResponseWriter, value interface{}) {
+	log.Info("Response: %+v", value)
+
+	response.Header().Set("Content-Type", contentType)
+	json.NewEncoder(response).Encode(value)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, body []byte, value interface{}) bool {
+	err := json.Unmarshal(body, value)
+	if err != nil {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return false
+	}
+
+	return true
+}
+
+// findTable writes an error response & returns nil when the table, which is either a name or an ARN, doesn't exist
+// in DynamoDB Local. Otherwise, it returns what myaws keeps about the table.
+func findTable(response http.ResponseWriter, request *http.Request, db *database.Database, nameOrArn string) *types.Table {
+	name := nameOrArn
+	if index := strings.Index(nameOrArn, ":table/"); index >= 0 {
+		name = nameOrArn[index+len(":table/"):]
+	}
+
+	exists, err := tableExists(request, name)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return nil
+	}
+
+	if !exists {
+		msg := log.Error("Requested resource not found: Table: %s not found", name)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	}
+
+	table, err := queries.TableByName(request.Context(), db, name)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return nil
+	}
+
+	return table
+}
+
+// saveTable writes an error response & returns false when the metadata of the table can't be saved.
+func saveTable(response http.ResponseWriter, request *http.Request, db *database.Database, table *types.Table) bool {
+	err := queries.SaveTable(request.Context(), db, table)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return false
+	}
+
+	return true
+}
+
+func tagResource(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.TagResourceInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.ResourceArn)
+	if table == nil {
+		return
+	}
+
+	for _, tag := range input.Tags {
+		table.Tags = append(withoutTag(table.Tags, tag.Key), tag)
+	}
+
+	if saveTable(response, request, db, table) {
+		respondWithJson(response, struct{}{})
+	}
+}
+
+func untagResource(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.TagResourceInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.ResourceArn)
+	if table == nil {
+		return
+	}
+
+	for _, key := range input.TagKeys {
+		table.Tags = withoutTag(table.Tags, key)
+	}
+
+	if saveTable(response, request, db, table) {
+		respondWithJson(response, struct{}{})
+	}
+}
+
+func withoutTag(tags []types.Tag, key string) []types.Tag {
+	results := []types.Tag{}
+	for _, tag := range tags {
+		if tag.Key != key {
+			results = append(results, tag)
+		}
+	}
+
+	return results
+}
+
+func listTagsOfResource(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.TagResourceInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.ResourceArn)
+	if table == nil {
+		return
+	}
+
+	respondWithJson(response, map[string][]types.Tag{"Tags": table.Tags})
+}
+
+// updateTimeToLive only keeps the TTL settings of the table. Items aren't deleted once they expire.
+func updateTimeToLive(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.TimeToLiveInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	specification := input.TimeToLiveSpecification
+	if len(specification.AttributeName) == 0 {
+		msg := log.Error("TimeToLiveSpecification must have an AttributeName")
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.TableName)
+	if table == nil {
+		return
+	}
+
+	enabled := table.TimeToLive.TimeToLiveStatus == types.TimeToLiveStatusEnabled
+	if enabled == specification.Enabled {
+		msg := log.Error("TimeToLive is already %s", strings.ToLower(table.TimeToLive.TimeToLiveStatus))
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	table.TimeToLive = types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+	if specification.Enabled {
+		table.TimeToLive = types.TimeToLiveDescription{
+			AttributeName:    specification.AttributeName,
+			TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+		}
+	}
+
+	if saveTable(response, request, db, table) {
+		respondWithJson(response, map[string]types.TimeToLiveSpecification{"TimeToLiveSpecification": specification})
+	}
+}
+
+func describeTimeToLive(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.TimeToLiveInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.TableName)
+	if table == nil {
+		return
+	}
+
+	respondWithJson(response, map[string]types.TimeToLiveDescription{"TimeToLiveDescription": table.TimeToLive})
+}
+
+func updateContinuousBackups(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.ContinuousBackupsInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.TableName)
+	if table == nil {
+		return
+	}
+
+	table.PointInTimeRecovery = input.PointInTimeRecoverySpecification.PointInTimeRecoveryEnabled
+	if saveTable(response, request, db, table) {
+		respondWithJson(response, map[string]types.ContinuousBackupsDescription{
+			"ContinuousBackupsDescription": table.ContinuousBackups(),
+		})
+	}
+}
+
+func describeContinuousBackups(response http.ResponseWriter, request *http.Request, body []byte) {
+	var input types.ContinuousBackupsInput
+	if !decodeBody(response, body, &input) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := findTable(response, request, db, input.TableName)
+	if table == nil {
+		return
+	}
+
+	respondWithJson(response, map[string]types.ContinuousBackupsDescription{
+		"ContinuousBackupsDescription": table.ContinuousBackups(),
+	})
+}
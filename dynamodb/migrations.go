@@ -0,0 +1,19 @@
+package dynamodb
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "DynamoDB",
+		Description: "Create Table Metadata Table",
+		Query: `CREATE TABLE IF NOT EXISTS dynamodb_table (
+					id						integer primary key autoincrement,
+					name					text not null unique,
+					tags					text not null,
+					ttl_attribute_name		text not null,
+					ttl_status				text not null,
+					point_in_time_recovery	integer not null
+				);
+		`,
+	},
+}
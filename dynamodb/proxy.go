@@ -0,0 +1,196 @@
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"myaws/database"
+	"myaws/dynamodb/queries"
+	"myaws/dynamodb/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	targetPrefix = "DynamoDB_20120810."
+	contentType  = "application/x-amz-json-1.0"
+
+	// localArnPrefix starts the ARNs in responses of DynamoDB Local, which has its own region & account
+	localArnPrefix = "arn:aws:dynamodb:ddblocal:000000000000:"
+)
+
+type action func(http.ResponseWriter, *http.Request, []byte)
+
+// actions are handled by myaws instead of DynamoDB Local, which doesn't keep what they change.
+var actions = map[string]action{
+	"TagResource":               tagResource,
+	"UntagResource":             untagResource,
+	"ListTagsOfResource":        listTagsOfResource,
+	"UpdateTimeToLive":          updateTimeToLive,
+	"DescribeTimeToLive":        describeTimeToLive,
+	"UpdateContinuousBackups":   updateContinuousBackups,
+	"DescribeContinuousBackups": describeContinuousBackups,
+}
+
+// Handler serves DynamoDB's JSON protocol by proxying to DynamoDB Local, except for the actions whose results myaws
+// keeps itself.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		msg := log.Error("Unable to read DynamoDB request: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	target := request.Header.Get("X-Amz-Target")
+	if action, ok := actions[strings.TrimPrefix(target, targetPrefix)]; ok {
+		action(response, request, body)
+		return
+	}
+
+	switch target {
+	case targetPrefix + "CreateTable":
+		createTable(response, request, body)
+	case targetPrefix + "DeleteTable":
+		deleteTable(response, request, body)
+	default:
+		proxy(response, request, body)
+	}
+}
+
+// proxy forwards the request to DynamoDB Local & writes its response, with ARNs rewritten to myaws' region & account.
+func proxy(response http.ResponseWriter, request *http.Request, body []byte) int {
+	statusCode, header, responseBody, err := forward(request.Context(), request.Header, body)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return http.StatusInternalServerError
+	}
+
+	for key, values := range header {
+		for _, value := range values {
+			response.Header().Add(key, value)
+		}
+	}
+
+	// the SDKs check the checksum of DynamoDB responses, so it has to match the rewritten body
+	response.Header().Set("Content-Length", strconv.Itoa(len(responseBody)))
+	response.Header().Set("X-Amz-Crc32", strconv.FormatUint(uint64(crc32.ChecksumIEEE(responseBody)), 10))
+	response.WriteHeader(statusCode)
+	response.Write(responseBody)
+
+	return statusCode
+}
+
+func forward(ctx context.Context, header http.Header, body []byte) (int, http.Header, []byte, error) {
+	cfg := settings.FromContext(ctx)
+	arnPrefix := "arn:aws:dynamodb:" + cfg.ArnFragment() + ":"
+
+	// ARNs in requests, such as those of streams, are only known to DynamoDB Local by its own region & account
+	body = bytes.ReplaceAll(body, []byte(arnPrefix), []byte(localArnPrefix))
+	proxyReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, cfg.DynamoDB.BuildUrl("/"), bytes.NewReader(body))
+	for key, values := range header {
+		if key != "Content-Length" {
+			proxyReq.Header[key] = values
+		}
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(proxyReq)
+	if err != nil {
+		msg := log.Error("Problem proxying to DynamoDB Local: %v", err)
+		return 0, nil, nil, errors.New(msg)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		msg := log.Error("Unable to read response of DynamoDB Local: %v", err)
+		return 0, nil, nil, errors.New(msg)
+	}
+
+	responseBody = bytes.ReplaceAll(responseBody, []byte(localArnPrefix), []byte(arnPrefix))
+
+	resp.Header.Del("Content-Length")
+	resp.Header.Del("X-Amz-Crc32")
+	return resp.StatusCode, resp.Header, responseBody, nil
+}
+
+// tableExists asks DynamoDB Local whether the table exists.
+func tableExists(request *http.Request, name string) (bool, error) {
+	body, err := json.Marshal(map[string]string{"TableName": name})
+	if err != nil {
+		return false, err
+	}
+
+	header := request.Header.Clone()
+	header.Set("X-Amz-Target", targetPrefix+"DescribeTable")
+	statusCode, _, _, err := forward(request.Context(), header, body)
+	if err != nil {
+		return false, err
+	}
+
+	return statusCode == http.StatusOK, nil
+}
+
+// createTable creates the table in DynamoDB Local, keeping its tags in myaws.
+func createTable(response http.ResponseWriter, request *http.Request, body []byte) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		proxy(response, request, body)
+		return
+	}
+
+	var input struct {
+		TableName string
+		Tags      []types.Tag
+	}
+	json.Unmarshal(body, &input)
+
+	if _, ok := fields["Tags"]; ok {
+		delete(fields, "Tags")
+		body, _ = json.Marshal(fields)
+	}
+
+	if proxy(response, request, body) != http.StatusOK {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	table := types.NewTable(input.TableName)
+	if input.Tags != nil {
+		table.Tags = input.Tags
+	}
+
+	// the table was already created, so failing to keep its metadata is only logged
+	queries.SaveTable(ctx, db, table)
+}
+
+// deleteTable deletes the table in DynamoDB Local along with what myaws keeps about it.
+func deleteTable(response http.ResponseWriter, request *http.Request, body []byte) {
+	if proxy(response, request, body) != http.StatusOK {
+		return
+	}
+
+	var input struct {
+		TableName string
+	}
+	json.Unmarshal(body, &input)
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	queries.DeleteTable(ctx, db, input.TableName)
+}
@@ -0,0 +1,82 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/dynamodb/types"
+	"myaws/log"
+)
+
+// SaveTable creates the metadata of the table, or replaces it when it already exists.
+func SaveTable(ctx context.Context, db *database.Database, table *types.Table) error {
+	tags, err := json.Marshal(table.Tags)
+	if err != nil {
+		msg := log.Error("Unable to serialize tags of table %s: %v", table.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`INSERT INTO dynamodb_table (name, tags, ttl_attribute_name, ttl_status, point_in_time_recovery)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT(name) DO UPDATE SET tags = excluded.tags, ttl_attribute_name = excluded.ttl_attribute_name,
+					ttl_status = excluded.ttl_status, point_in_time_recovery = excluded.point_in_time_recovery
+		`,
+		table.Name,
+		string(tags),
+		table.TimeToLive.AttributeName,
+		table.TimeToLive.TimeToLiveStatus,
+		table.PointInTimeRecovery,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save metadata of table %s: %v", table.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// TableByName returns the metadata of the table, which has the defaults when none was saved.
+func TableByName(ctx context.Context, db *database.Database, name string) (*types.Table, error) {
+	row := db.QueryRowContext(
+		ctx,
+		`SELECT id, tags, ttl_attribute_name, ttl_status, point_in_time_recovery FROM dynamodb_table WHERE name = ?`,
+		name,
+	)
+
+	table := types.NewTable(name)
+	var tags string
+	err := row.Scan(&table.ID, &tags, &table.TimeToLive.AttributeName, &table.TimeToLive.TimeToLiveStatus,
+		&table.PointInTimeRecovery)
+
+	if err == sql.ErrNoRows {
+		return table, nil
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan metadata of table %s: %v", name, err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(tags), &table.Tags)
+	if err != nil {
+		msg := log.Error("Unable to parse tags of table %s: %v", name, err)
+		return nil, errors.New(msg)
+	}
+
+	return table, nil
+}
+
+func DeleteTable(ctx context.Context, db *database.Database, name string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM dynamodb_table WHERE name = ?`, name)
+	if err != nil {
+		msg := log.Error("Unable to delete metadata of table %s: %v", name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
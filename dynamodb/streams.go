@@ -0,0 +1,51 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/log"
+	"net/http"
+)
+
+const (
+	streamsTargetPrefix = "DynamoDBStreams_20120810."
+
+	// streamsAuthorization is sent with myaws' own requests to DynamoDB Local, which needs one but doesn't check it
+	streamsAuthorization = "AWS4-HMAC-SHA256 Credential=myaws/20120810/ddblocal/dynamodb/aws4_request, " +
+		"SignedHeaders=host;x-amz-target, Signature=myaws"
+)
+
+// StreamsRequest calls an action of DynamoDB Local's Streams API, for myaws' services that consume streams.
+func StreamsRequest(ctx context.Context, action string, input interface{}, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		msg := log.Error("Unable to encode %s request: %v", action, err)
+		return errors.New(msg)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("X-Amz-Target", streamsTargetPrefix+action)
+	header.Set("Authorization", streamsAuthorization)
+
+	statusCode, _, responseBody, err := forward(ctx, header, body)
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusOK {
+		var failure errorBody
+		json.Unmarshal(responseBody, &failure)
+		msg := log.Error("%s failed with %d %s: %s", action, statusCode, failure.Type, failure.Message)
+		return errors.New(msg)
+	}
+
+	err = json.Unmarshal(responseBody, output)
+	if err != nil {
+		msg := log.Error("Unable to decode %s response: %v", action, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package types
+
+import "encoding/json"
+
+const (
+	ShardIteratorTypeTrimHorizon         = "TRIM_HORIZON"
+	ShardIteratorTypeLatest              = "LATEST"
+	ShardIteratorTypeAfterSequenceNumber = "AFTER_SEQUENCE_NUMBER"
+)
+
+type DescribeStreamInput struct {
+	StreamArn string
+}
+
+type DescribeStreamOutput struct {
+	StreamDescription StreamDescription
+}
+
+type StreamDescription struct {
+	StreamArn    string
+	StreamStatus string
+	Shards       []Shard
+}
+
+type Shard struct {
+	ShardId             string
+	ParentShardId       string
+	SequenceNumberRange SequenceNumberRange
+}
+
+type SequenceNumberRange struct {
+	StartingSequenceNumber string
+	EndingSequenceNumber   string
+}
+
+type GetShardIteratorInput struct {
+	StreamArn         string
+	ShardId           string
+	ShardIteratorType string
+	SequenceNumber    string `json:",omitempty"`
+}
+
+type GetShardIteratorOutput struct {
+	ShardIterator string
+}
+
+type GetRecordsInput struct {
+	ShardIterator string
+	Limit         int32
+}
+
+type GetRecordsOutput struct {
+	Records           []StreamRecord
+	NextShardIterator string
+}
+
+// StreamRecord is a change to an item, as returned by GetRecords & as delivered to Functions.
+type StreamRecord struct {
+	EventID        string          `json:"eventID"`
+	EventName      string          `json:"eventName"`
+	EventVersion   string          `json:"eventVersion"`
+	EventSource    string          `json:"eventSource"`
+	AwsRegion      string          `json:"awsRegion"`
+	Dynamodb       json.RawMessage `json:"dynamodb"`
+	UserIdentity   json.RawMessage `json:"userIdentity,omitempty"`
+	EventSourceARN string          `json:"eventSourceARN,omitempty"`
+}
+
+// SequenceNumber returns the sequence number of the record within its shard.
+func (record StreamRecord) SequenceNumber() string {
+	var data struct {
+		SequenceNumber string
+	}
+	json.Unmarshal(record.Dynamodb, &data)
+
+	return data.SequenceNumber
+}
@@ -0,0 +1,84 @@
+package types
+
+const (
+	TimeToLiveStatusEnabled  = "ENABLED"
+	TimeToLiveStatusDisabled = "DISABLED"
+
+	StatusEnabled  = "ENABLED"
+	StatusDisabled = "DISABLED"
+)
+
+// The version of the SDK used by myaws has no DynamoDB client, so these types mirror the API's JSON.
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Table is what myaws keeps about a table that DynamoDB Local doesn't, while DynamoDB Local keeps the table itself.
+type Table struct {
+	ID                  int64
+	Name                string
+	Tags                []Tag
+	TimeToLive          TimeToLiveDescription
+	PointInTimeRecovery bool
+}
+
+func NewTable(name string) *Table {
+	return &Table{
+		Name:       name,
+		Tags:       []Tag{},
+		TimeToLive: TimeToLiveDescription{TimeToLiveStatus: TimeToLiveStatusDisabled},
+	}
+}
+
+type TagResourceInput struct {
+	ResourceArn string
+	Tags        []Tag
+	TagKeys     []string
+}
+
+type TimeToLiveSpecification struct {
+	AttributeName string
+	Enabled       bool
+}
+
+type TimeToLiveDescription struct {
+	AttributeName    string `json:",omitempty"`
+	TimeToLiveStatus string
+}
+
+type TimeToLiveInput struct {
+	TableName               string
+	TimeToLiveSpecification TimeToLiveSpecification
+}
+
+type PointInTimeRecoverySpecification struct {
+	PointInTimeRecoveryEnabled bool
+}
+
+type ContinuousBackupsInput struct {
+	TableName                        string
+	PointInTimeRecoverySpecification PointInTimeRecoverySpecification
+}
+
+type PointInTimeRecoveryDescription struct {
+	PointInTimeRecoveryStatus string
+}
+
+type ContinuousBackupsDescription struct {
+	ContinuousBackupsStatus        string
+	PointInTimeRecoveryDescription PointInTimeRecoveryDescription
+}
+
+func (table Table) ContinuousBackups() ContinuousBackupsDescription {
+	status := StatusDisabled
+	if table.PointInTimeRecovery {
+		status = StatusEnabled
+	}
+
+	return ContinuousBackupsDescription{
+		ContinuousBackupsStatus:        StatusEnabled,
+		PointInTimeRecoveryDescription: PointInTimeRecoveryDescription{PointInTimeRecoveryStatus: status},
+	}
+}
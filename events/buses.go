@@ -0,0 +1,176 @@
+package events
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var eventBusNameRegex = regexp.MustCompile(`^[/.\-_A-Za-z0-9]{1,256}$`)
+
+// findEventBus writes an error response & returns nil when the event bus doesn't exist. The default event bus always
+// exists, without being saved.
+func findEventBus(response http.ResponseWriter, request *http.Request, db *database.Database, nameOrArn string) *types.EventBus {
+	name := eventBusName(nameOrArn)
+	if name == types.DefaultEventBusName {
+		return &types.EventBus{Name: name}
+	}
+
+	bus, err := queries.EventBusByName(request.Context(), db, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Event bus %s does not exist.", name)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return nil
+	}
+
+	return bus
+}
+
+func createEventBus(response http.ResponseWriter, request *http.Request) {
+	var body types.EventBusInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !eventBusNameRegex.MatchString(body.Name) || body.Name == types.DefaultEventBusName {
+		msg := log.Error("Event bus name %s must match %s and can't be default", body.Name, eventBusNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	_, err := queries.EventBusByName(ctx, db, body.Name)
+	switch {
+	case err == nil:
+		msg := log.Error("Event bus %s already exists.", body.Name)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceAlreadyExists, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	bus := types.EventBus{Name: body.Name, CreatedOn: time.Now().UnixMilli()}
+	err = queries.InsertEventBus(ctx, db, &bus)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, map[string]string{"EventBusArn": bus.GetArn(ctx)})
+}
+
+func describeEventBus(response http.ResponseWriter, request *http.Request) {
+	var body types.EventBusInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	bus := findEventBus(response, request, db, body.Name)
+	if bus == nil {
+		return
+	}
+
+	respondWithJson(response, bus.ToOutput(ctx))
+}
+
+func listEventBuses(response http.ResponseWriter, request *http.Request) {
+	var body types.EventBusInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	buses, err := queries.EventBuses(ctx, db, body.NamePrefix)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	outputs := []types.EventBusOutput{}
+	defaultBus := types.EventBus{Name: types.DefaultEventBusName}
+	if strings.HasPrefix(defaultBus.Name, body.NamePrefix) {
+		outputs = append(outputs, defaultBus.ToOutput(ctx))
+	}
+
+	for _, bus := range buses {
+		outputs = append(outputs, bus.ToOutput(ctx))
+	}
+
+	respondWithJson(response, map[string][]types.EventBusOutput{"EventBuses": outputs})
+}
+
+func deleteEventBus(response http.ResponseWriter, request *http.Request) {
+	var body types.EventBusInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	name := eventBusName(body.Name)
+	if name == types.DefaultEventBusName {
+		msg := log.Error("Cannot delete event bus default.")
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	bus, err := queries.EventBusByName(ctx, db, name)
+	if err == sql.ErrNoRows {
+		// deleting an event bus that doesn't exist succeeds, like deleting rules
+		respondWithJson(response, struct{}{})
+		return
+	}
+
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	rules, err := queries.Rules(ctx, db, bus.Name, "")
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	if len(rules) > 0 {
+		msg := log.Error("Event bus %s can't be deleted since it has rules.", bus.Name)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	err = queries.DeleteEventBus(ctx, db, bus)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
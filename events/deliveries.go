@@ -0,0 +1,94 @@
+package events
+
+import (
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func deliveryFilter(request *http.Request) (types.DeliveryFilter, error) {
+	query := request.URL.Query()
+	filter := types.DeliveryFilter{
+		EventBusName: query.Get("EventBusName"),
+		RuleName:     query.Get("RuleName"),
+		TargetId:     query.Get("TargetId"),
+		EventId:      query.Get("EventId"),
+		Source:       query.Get("Source"),
+		DetailType:   query.Get("DetailType"),
+		Input:        query.Get("Input"),
+	}
+
+	if value := query.Get("Since"); len(value) > 0 {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since.UnixMilli()
+	}
+
+	if value := query.Get("Limit"); len(value) > 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+const DeliveriesRegex = `^/_myaws/events/deliveries$`
+
+// GetDeliveries lists the events delivered to targets newest first, whether by schedules, PutEvents or triggering
+// rules, filtered by the EventBusName, RuleName, TargetId, EventId, Source, DetailType, Input (a substring of what the
+// target was sent), Since (an RFC 3339 time) and Limit query parameters.
+func GetDeliveries(response http.ResponseWriter, request *http.Request) {
+	filter, err := deliveryFilter(request)
+	if err != nil {
+		msg := log.Error("Invalid filter for deliveries: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	deliveries, err := queries.Deliveries(ctx, db, filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, map[string][]types.Delivery{"Deliveries": deliveries})
+}
+
+// DeleteDeliveries forgets delivered events, using the same filters as GetDeliveries.
+func DeleteDeliveries(response http.ResponseWriter, request *http.Request) {
+	filter, err := deliveryFilter(request)
+	if err != nil {
+		msg := log.Error("Invalid filter for deliveries: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	err = queries.DeleteDeliveries(ctx, db, filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"myaws/events/types"
+	"myaws/log"
+	"net/http"
+	"strings"
+)
+
+const contentType = "application/x-amz-json-1.1"
+
+type action func(http.ResponseWriter, *http.Request)
+
+var actions = map[string]action{
+	"AWSEvents.PutRule":             putRule,
+	"AWSEvents.DescribeRule":        describeRule,
+	"AWSEvents.ListRules":           listRules,
+	"AWSEvents.DeleteRule":          deleteRule,
+	"AWSEvents.EnableRule":          enableRule,
+	"AWSEvents.DisableRule":         disableRule,
+	"AWSEvents.PutTargets":          putTargets,
+	"AWSEvents.ListTargetsByRule":   listTargetsByRule,
+	"AWSEvents.RemoveTargets":       removeTargets,
+	"AWSEvents.ListTagsForResource": listTagsForResource,
+	"AWSEvents.TagResource":         tagResource,
+	"AWSEvents.UntagResource":       untagResource,
+	"AWSEvents.CreateEventBus":      createEventBus,
+	"AWSEvents.DescribeEventBus":    describeEventBus,
+	"AWSEvents.ListEventBuses":      listEventBuses,
+	"AWSEvents.DeleteEventBus":      deleteEventBus,
+	"AWSEvents.PutEvents":           putEvents,
+	"AWSEvents.TestEventPattern":    testEventPattern,
+}
+
+// Handler serves EventBridge's JSON protocol, dispatching on the action in the X-Amz-Target header.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	target := request.Header.Get("X-Amz-Target")
+	action, ok := actions[target]
+	if !ok {
+		msg := log.Error("Unsupported EventBridge action %s", target)
+		respondWithError(response, http.StatusBadRequest, errorTypeUnknownOperation, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, request *http.Request, value interface{}) bool {
+	defer request.Body.Close()
+
+	err := json.NewDecoder(request.Body).Decode(value)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return false
+	}
+
+	return true
+}
+
+func respondWithJson(response http.ResponseWriter, value interface{}) {
+	log.Info("Response: %+v", value)
+
+	response.Header().Set("Content-Type", contentType)
+	json.NewEncoder(response).Encode(value)
+}
+
+// eventBusName returns the name of the event bus in a request, which may be either its name or ARN, and is the
+// default event bus when missing.
+func eventBusName(nameOrArn string) string {
+	if len(nameOrArn) == 0 {
+		return types.DefaultEventBusName
+	}
+
+	if strings.HasPrefix(nameOrArn, "arn:") {
+		return nameOrArn[strings.LastIndex(nameOrArn, "/")+1:]
+	}
+
+	return nameOrArn
+}
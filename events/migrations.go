@@ -0,0 +1,59 @@
+package events
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "Events",
+		Description: "Create Rule & Target Tables",
+		Query: `CREATE TABLE IF NOT EXISTS events_rule (
+					id					integer primary key autoincrement,
+					name				text not null,
+					event_bus_name		text not null,
+					schedule_expression	text not null,
+					event_pattern		text not null,
+					state				text not null,
+					description			text not null,
+					role_arn			text not null,
+					tags				text not null,
+					created_on			integer not null,
+					UNIQUE(event_bus_name, name)
+				);
+				CREATE TABLE IF NOT EXISTS events_target (
+					id					integer primary key autoincrement,
+					event_bus_name		text not null,
+					rule_name			text not null,
+					target_id			text not null,
+					arn					text not null,
+					input				text not null,
+					input_path			text not null,
+					input_transformer	text not null,
+					role_arn			text not null,
+					UNIQUE(event_bus_name, rule_name, target_id)
+				);
+		`,
+	},
+	{
+		Service:     "Events",
+		Description: "Create Event Bus & Delivery Tables",
+		Query: `CREATE TABLE IF NOT EXISTS events_bus (
+					id					integer primary key autoincrement,
+					name				text not null unique,
+					created_on			integer not null
+				);
+				CREATE TABLE IF NOT EXISTS events_delivery (
+					id					integer primary key autoincrement,
+					event_id			text not null,
+					event_bus_name		text not null,
+					rule_name			text not null,
+					target_id			text not null,
+					target_arn			text not null,
+					source				text not null,
+					detail_type			text not null,
+					input				text not null,
+					error				text not null,
+					delivered_on		integer not null
+				);
+		`,
+	},
+}
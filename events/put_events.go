@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+const maxEntriesPerPutEvents = 10
+
+func putEvents(response http.ResponseWriter, request *http.Request) {
+	var body types.PutEventsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.Entries) == 0 || len(body.Entries) > maxEntriesPerPutEvents {
+		msg := log.Error("PutEvents must have between 1 and %d entries, not %d", maxEntriesPerPutEvents,
+			len(body.Entries))
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	// targets are invoked after responding, like EventBridge does, so they can't use the request's context
+	deliveryCtx := cfg.NewContext(context.Background())
+
+	output := types.PutEventsOutput{Entries: make([]types.PutEventsResultEntry, len(body.Entries))}
+	for i, entry := range body.Entries {
+		event, errorCode, msg := newEvent(ctx, entry)
+		if len(errorCode) == 0 {
+			errorCode, msg = routeEvent(ctx, deliveryCtx, db, eventBusName(entry.EventBusName), event)
+		}
+
+		if len(errorCode) > 0 {
+			log.Error("Unable to put event %d: %s", i, msg)
+			output.Entries[i] = types.PutEventsResultEntry{ErrorCode: errorCode, ErrorMessage: msg}
+			output.FailedEntryCount++
+			continue
+		}
+
+		output.Entries[i] = types.PutEventsResultEntry{EventId: event.Id}
+	}
+
+	respondWithJson(response, output)
+}
+
+// newEvent returns the event for an entry of PutEvents, or the code & message of the error that the entry fails with.
+func newEvent(ctx context.Context, entry types.PutEventsRequestEntry) (types.Event, string, string) {
+	if len(entry.Source) == 0 || len(entry.DetailType) == 0 || len(entry.Detail) == 0 {
+		return types.Event{}, "InvalidArgument", "Parameters Source, DetailType & Detail are required."
+	}
+
+	var detail map[string]interface{}
+	if json.Unmarshal([]byte(entry.Detail), &detail) != nil {
+		return types.Event{}, "MalformedDetail", "Detail is malformed."
+	}
+
+	now := time.Now()
+	if entry.Time != nil {
+		seconds, fraction := math.Modf(*entry.Time)
+		now = time.Unix(int64(seconds), int64(fraction*float64(time.Second)))
+	}
+
+	resources := entry.Resources
+	if resources == nil {
+		resources = []string{}
+	}
+
+	cfg := settings.FromContext(ctx)
+	return types.Event{
+		Version:    "0",
+		Id:         uuid.Generate().String(),
+		DetailType: entry.DetailType,
+		Source:     entry.Source,
+		Account:    cfg.AccountNumber,
+		Time:       types.FormatTime(now),
+		Region:     cfg.Region,
+		Resources:  resources,
+		Detail:     json.RawMessage(entry.Detail),
+	}, "", ""
+}
+
+// routeEvent triggers the rules of the event bus whose patterns match the event, returning the code & message of the
+// error when it can't be routed.
+func routeEvent(ctx context.Context, deliveryCtx context.Context, db *database.Database, busName string, event types.Event) (string, string) {
+	if busName != types.DefaultEventBusName {
+		_, err := queries.EventBusByName(ctx, db, busName)
+		if err != nil {
+			return errorTypeResourceNotFound, "Event bus " + busName + " does not exist."
+		}
+	}
+
+	rules, err := queries.PatternRules(ctx, db, busName)
+	if err != nil {
+		return errorTypeInternal, err.Error()
+	}
+
+	document, err := json.Marshal(event)
+	if err != nil {
+		return errorTypeInternal, err.Error()
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		pattern, err := types.ParsePattern(rule.EventPattern)
+		if err != nil {
+			log.Error("Rule %s has an invalid event pattern: %v", rule.Name, err)
+			continue
+		}
+
+		if pattern.Matches(document) {
+			go triggerRule(deliveryCtx, rule, event)
+		}
+	}
+
+	return "", ""
+}
+
+func testEventPattern(response http.ResponseWriter, request *http.Request) {
+	var body types.TestEventPatternInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	pattern, err := types.ParsePattern(body.EventPattern)
+	if err != nil {
+		msg := log.Error("Event pattern is not valid: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidEventPattern, msg)
+		return
+	}
+
+	if !json.Valid([]byte(body.Event)) {
+		msg := log.Error("Event is not valid JSON.")
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	respondWithJson(response, map[string]bool{"Result": pattern.Matches([]byte(body.Event))})
+}
@@ -0,0 +1,81 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/database"
+	"myaws/events/types"
+	"myaws/log"
+)
+
+func InsertEventBus(ctx context.Context, db *database.Database, bus *types.EventBus) error {
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO events_bus (name, created_on) VALUES (?, ?)`,
+		bus.Name,
+		bus.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save event bus %s: %v", bus.Name, err)
+		return errors.New(msg)
+	}
+
+	bus.ID = id
+	return nil
+}
+
+const selectEventBus = `SELECT id, name, created_on FROM events_bus`
+
+// EventBusByName returns the custom event bus, or sql.ErrNoRows when there is no such bus.
+func EventBusByName(ctx context.Context, db *database.Database, name string) (*types.EventBus, error) {
+	row := db.QueryRowContext(ctx, selectEventBus+` WHERE name = ?`, name)
+	return scanEventBus(row)
+}
+
+// EventBuses returns the custom event buses whose names start with the prefix.
+func EventBuses(ctx context.Context, db *database.Database, prefix string) ([]types.EventBus, error) {
+	rows, err := db.QueryContext(ctx, selectEventBus+` WHERE name LIKE ? || '%' ORDER BY name`, prefix)
+	if err != nil {
+		msg := log.Error("Unable to query event buses: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.EventBus{}
+	for rows.Next() {
+		bus, err := scanEventBus(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *bus)
+	}
+
+	return results, nil
+}
+
+func scanEventBus(row scanner) (*types.EventBus, error) {
+	var bus types.EventBus
+	err := row.Scan(&bus.ID, &bus.Name, &bus.CreatedOn)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan event bus: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &bus, nil
+}
+
+func DeleteEventBus(ctx context.Context, db *database.Database, bus *types.EventBus) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM events_bus WHERE id = ?`, bus.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete event bus %s: %v", bus.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"myaws/database"
+	"myaws/events/types"
+	"myaws/log"
+	"strings"
+)
+
+func InsertDelivery(ctx context.Context, db *database.Database, delivery types.Delivery) error {
+	_, err := db.InsertOne(
+		ctx,
+		`INSERT INTO events_delivery (event_id, event_bus_name, rule_name, target_id, target_arn, source, detail_type,
+					input, error, delivered_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+		delivery.EventId,
+		delivery.EventBusName,
+		delivery.RuleName,
+		delivery.TargetId,
+		delivery.TargetArn,
+		delivery.Source,
+		delivery.DetailType,
+		delivery.Input,
+		delivery.Error,
+		delivery.DeliveredOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save delivery of event %s to target %s: %v", delivery.EventId,
+			delivery.TargetId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// Deliveries returns the Deliveries matching the filter, newest first.
+func Deliveries(ctx context.Context, db *database.Database, filter types.DeliveryFilter) ([]types.Delivery, error) {
+	conditions, args := deliveryConditions(filter)
+	query := `SELECT id, event_id, event_bus_name, rule_name, target_id, target_arn, source, detail_type, input, error,
+					delivered_on
+				FROM events_delivery` + conditions + ` ORDER BY id DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		msg := log.Error("Unable to query deliveries: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Delivery{}
+	for rows.Next() {
+		var delivery types.Delivery
+		var deliveredOn int64
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.EventId,
+			&delivery.EventBusName,
+			&delivery.RuleName,
+			&delivery.TargetId,
+			&delivery.TargetArn,
+			&delivery.Source,
+			&delivery.DetailType,
+			&delivery.Input,
+			&delivery.Error,
+			&deliveredOn,
+		)
+
+		if err != nil {
+			msg := log.Error("Unable to scan delivery: %v", err)
+			return nil, errors.New(msg)
+		}
+
+		delivery.SetDeliveredOn(deliveredOn)
+		results = append(results, delivery)
+	}
+
+	// most recent were selected first, so that Limit keeps them
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+
+	return results, nil
+}
+
+// DeleteDeliveries forgets the Deliveries matching the filter.
+func DeleteDeliveries(ctx context.Context, db *database.Database, filter types.DeliveryFilter) error {
+	conditions, args := deliveryConditions(filter)
+	_, err := db.ExecContext(ctx, `DELETE FROM events_delivery`+conditions, args...)
+	if err != nil {
+		msg := log.Error("Unable to delete deliveries: %v", err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func deliveryConditions(filter types.DeliveryFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	equal := []struct {
+		column string
+		value  string
+	}{
+		{"event_bus_name", filter.EventBusName},
+		{"rule_name", filter.RuleName},
+		{"target_id", filter.TargetId},
+		{"event_id", filter.EventId},
+		{"source", filter.Source},
+		{"detail_type", filter.DetailType},
+	}
+
+	for _, condition := range equal {
+		if len(condition.value) > 0 {
+			conditions = append(conditions, condition.column+" = ?")
+			args = append(args, condition.value)
+		}
+	}
+
+	if len(filter.Input) > 0 {
+		conditions = append(conditions, "instr(input, ?) > 0")
+		args = append(args, filter.Input)
+	}
+
+	if filter.Since > 0 {
+		conditions = append(conditions, "delivered_on >= ?")
+		args = append(args, filter.Since)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
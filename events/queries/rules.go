@@ -0,0 +1,142 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/events/types"
+	"myaws/log"
+)
+
+// scanner is either a sql.Row or sql.Rows, so that a single function scans a table's columns for both.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// SaveRule creates the rule, or replaces it when a rule with the same name already exists on its event bus.
+func SaveRule(ctx context.Context, db *database.Database, rule *types.Rule) error {
+	tags, err := json.Marshal(rule.Tags)
+	if err != nil {
+		msg := log.Error("Unable to serialize tags of rule %s: %v", rule.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`INSERT INTO events_rule (name, event_bus_name, schedule_expression, event_pattern, state, description,
+					role_arn, tags, created_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(event_bus_name, name) DO UPDATE SET schedule_expression = excluded.schedule_expression,
+					event_pattern = excluded.event_pattern, state = excluded.state,
+					description = excluded.description, role_arn = excluded.role_arn, tags = excluded.tags
+		`,
+		rule.Name,
+		rule.EventBusName,
+		rule.ScheduleExpression,
+		rule.EventPattern,
+		rule.State,
+		rule.Description,
+		rule.RoleArn,
+		string(tags),
+		rule.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save rule %s: %v", rule.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectRule = `SELECT id, name, event_bus_name, schedule_expression, event_pattern, state, description, role_arn,
+					tags, created_on
+				FROM events_rule`
+
+// RuleByName returns the rule on the event bus, or sql.ErrNoRows when there is no such rule.
+func RuleByName(ctx context.Context, db *database.Database, eventBusName string, name string) (*types.Rule, error) {
+	row := db.QueryRowContext(ctx, selectRule+` WHERE event_bus_name = ? AND name = ?`, eventBusName, name)
+	return scanRule(row)
+}
+
+// Rules returns the rules on the event bus whose names start with the prefix.
+func Rules(ctx context.Context, db *database.Database, eventBusName string, prefix string) ([]types.Rule, error) {
+	return rules(ctx, db, ` WHERE event_bus_name = ? AND name LIKE ? || '%' ORDER BY name`, eventBusName, prefix)
+}
+
+// ScheduledRules returns the enabled rules of all event buses that have a schedule.
+func ScheduledRules(ctx context.Context, db *database.Database) ([]types.Rule, error) {
+	return rules(ctx, db, ` WHERE state = ? AND schedule_expression != ''`, types.RuleStateEnabled)
+}
+
+// PatternRules returns the enabled rules of the event bus that have an event pattern.
+func PatternRules(ctx context.Context, db *database.Database, eventBusName string) ([]types.Rule, error) {
+	return rules(ctx, db, ` WHERE event_bus_name = ? AND state = ? AND event_pattern != ''`, eventBusName,
+		types.RuleStateEnabled)
+}
+
+func rules(ctx context.Context, db *database.Database, conditions string, args ...interface{}) ([]types.Rule, error) {
+	rows, err := db.QueryContext(ctx, selectRule+conditions, args...)
+	if err != nil {
+		msg := log.Error("Unable to query rules: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *rule)
+	}
+
+	return results, nil
+}
+
+func scanRule(row scanner) (*types.Rule, error) {
+	var rule types.Rule
+	var tags string
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.EventBusName,
+		&rule.ScheduleExpression,
+		&rule.EventPattern,
+		&rule.State,
+		&rule.Description,
+		&rule.RoleArn,
+		&tags,
+		&rule.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan rule: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(tags), &rule.Tags)
+	if err != nil {
+		msg := log.Error("Unable to parse tags of rule %s: %v", rule.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	return &rule, nil
+}
+
+func DeleteRule(ctx context.Context, db *database.Database, rule *types.Rule) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM events_rule WHERE id = ?`, rule.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete rule %s: %v", rule.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/events/types"
+	"myaws/log"
+)
+
+// SaveTarget adds the target to its rule, or replaces the target with the same ID.
+func SaveTarget(ctx context.Context, db *database.Database, target types.Target) error {
+	transformer := ""
+	if target.InputTransformer != nil {
+		value, err := json.Marshal(target.InputTransformer)
+		if err != nil {
+			msg := log.Error("Unable to serialize input transformer of target %s: %v", target.Id, err)
+			return errors.New(msg)
+		}
+		transformer = string(value)
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO events_target (event_bus_name, rule_name, target_id, arn, input, input_path, input_transformer,
+					role_arn)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(event_bus_name, rule_name, target_id) DO UPDATE SET arn = excluded.arn,
+					input = excluded.input, input_path = excluded.input_path,
+					input_transformer = excluded.input_transformer, role_arn = excluded.role_arn
+		`,
+		target.EventBusName,
+		target.RuleName,
+		target.Id,
+		target.Arn,
+		target.Input,
+		target.InputPath,
+		transformer,
+		target.RoleArn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save target %s of rule %s: %v", target.Id, target.RuleName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func TargetsByRule(ctx context.Context, db *database.Database, rule *types.Rule) ([]types.Target, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT id, event_bus_name, rule_name, target_id, arn, input, input_path, input_transformer, role_arn
+				FROM events_target
+				WHERE event_bus_name = ? AND rule_name = ?
+				ORDER BY target_id
+		`,
+		rule.EventBusName,
+		rule.Name,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to query targets of rule %s: %v", rule.Name, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Target{}
+	for rows.Next() {
+		var target types.Target
+		var transformer string
+		err := rows.Scan(
+			&target.ID,
+			&target.EventBusName,
+			&target.RuleName,
+			&target.Id,
+			&target.Arn,
+			&target.Input,
+			&target.InputPath,
+			&transformer,
+			&target.RoleArn,
+		)
+
+		if err != nil {
+			msg := log.Error("Unable to scan target of rule %s: %v", rule.Name, err)
+			return nil, errors.New(msg)
+		}
+
+		if len(transformer) > 0 {
+			target.InputTransformer = &types.InputTransformer{}
+			err = json.Unmarshal([]byte(transformer), target.InputTransformer)
+			if err != nil {
+				msg := log.Error("Unable to parse input transformer of target %s: %v", target.Id, err)
+				return nil, errors.New(msg)
+			}
+		}
+
+		results = append(results, target)
+	}
+
+	return results, nil
+}
+
+func DeleteTarget(ctx context.Context, db *database.Database, rule *types.Rule, targetId string) error {
+	_, err := db.ExecContext(
+		ctx,
+		`DELETE FROM events_target WHERE event_bus_name = ? AND rule_name = ? AND target_id = ?`,
+		rule.EventBusName,
+		rule.Name,
+		targetId,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to delete target %s of rule %s: %v", targetId, rule.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
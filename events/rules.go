@@ -0,0 +1,347 @@
+package events
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ruleNameRegex = regexp.MustCompile(`^[.\-_A-Za-z0-9]{1,64}$`)
+
+// findRule writes an error response & returns nil when the rule doesn't exist.
+func findRule(response http.ResponseWriter, request *http.Request, db *database.Database, busName string, name string) *types.Rule {
+	busName = eventBusName(busName)
+	rule, err := queries.RuleByName(request.Context(), db, busName, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Rule %s does not exist on EventBus %s.", name, busName)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return nil
+	}
+
+	return rule
+}
+
+func validateRule(input types.PutRuleInput) (string, bool) {
+	if !ruleNameRegex.MatchString(input.Name) {
+		return "Rule name " + input.Name + " must match " + ruleNameRegex.String(), false
+	}
+
+	if len(input.ScheduleExpression) == 0 && len(input.EventPattern) == 0 {
+		return "Parameter(s) ScheduleExpression or EventPattern must be specified.", false
+	}
+
+	if len(input.ScheduleExpression) > 0 {
+		if eventBusName(input.EventBusName) != types.DefaultEventBusName {
+			return "ScheduleExpression is supported only on the default event bus.", false
+		}
+
+		_, err := parseSchedule(input.ScheduleExpression)
+		if err != nil {
+			return "Parameter ScheduleExpression is not valid: " + err.Error(), false
+		}
+	}
+
+	if len(input.EventPattern) > 0 {
+		_, err := types.ParsePattern(input.EventPattern)
+		if err != nil {
+			return "Event pattern is not valid: " + err.Error(), false
+		}
+	}
+
+	switch input.State {
+	case "", types.RuleStateEnabled, types.RuleStateDisabled:
+	default:
+		return "State " + input.State + " must be ENABLED or DISABLED.", false
+	}
+
+	return "", true
+}
+
+func putRule(response http.ResponseWriter, request *http.Request) {
+	var body types.PutRuleInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if msg, ok := validateRule(body); !ok {
+		log.Error(msg)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	if findEventBus(response, request, db, body.EventBusName) == nil {
+		return
+	}
+
+	rule := types.Rule{
+		Name:               body.Name,
+		EventBusName:       eventBusName(body.EventBusName),
+		ScheduleExpression: body.ScheduleExpression,
+		EventPattern:       body.EventPattern,
+		State:              body.State,
+		Description:        body.Description,
+		RoleArn:            body.RoleArn,
+		Tags:               body.Tags,
+		CreatedOn:          time.Now().UnixMilli(),
+	}
+
+	if len(rule.State) == 0 {
+		rule.State = types.RuleStateEnabled
+	}
+
+	if rule.Tags == nil {
+		rule.Tags = []types.Tag{}
+	}
+
+	err := queries.SaveRule(ctx, db, &rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, map[string]string{"RuleArn": rule.GetArn(ctx)})
+}
+
+func describeRule(response http.ResponseWriter, request *http.Request) {
+	var body types.RuleInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := findRule(response, request, db, body.EventBusName, body.Name)
+	if rule == nil {
+		return
+	}
+
+	respondWithJson(response, rule.ToOutput(ctx))
+}
+
+func listRules(response http.ResponseWriter, request *http.Request) {
+	var body types.ListRulesInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rules, err := queries.Rules(ctx, db, eventBusName(body.EventBusName), body.NamePrefix)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	outputs := make([]types.RuleOutput, len(rules))
+	for i, rule := range rules {
+		outputs[i] = rule.ToOutput(ctx)
+	}
+
+	respondWithJson(response, map[string][]types.RuleOutput{"Rules": outputs})
+}
+
+func deleteRule(response http.ResponseWriter, request *http.Request) {
+	var body types.RuleInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule, err := queries.RuleByName(ctx, db, eventBusName(body.EventBusName), body.Name)
+	if err == sql.ErrNoRows {
+		// deleting a rule that doesn't exist succeeds, like it does in EventBridge
+		respondWithJson(response, struct{}{})
+		return
+	}
+
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	targets, err := queries.TargetsByRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	if len(targets) > 0 {
+		msg := log.Error("Rule %s can't be deleted since it has targets.", rule.Name)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return
+	}
+
+	err = queries.DeleteRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func enableRule(response http.ResponseWriter, request *http.Request) {
+	setRuleState(response, request, types.RuleStateEnabled)
+}
+
+func disableRule(response http.ResponseWriter, request *http.Request) {
+	setRuleState(response, request, types.RuleStateDisabled)
+}
+
+func setRuleState(response http.ResponseWriter, request *http.Request, state string) {
+	var body types.RuleInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := findRule(response, request, db, body.EventBusName, body.Name)
+	if rule == nil {
+		return
+	}
+
+	rule.State = state
+	err := queries.SaveRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+// ruleForArn writes an error response & returns nil when the ARN isn't of an existing rule, the only resources that
+// are tagged.
+func ruleForArn(response http.ResponseWriter, request *http.Request, db *database.Database, arn string) *types.Rule {
+	index := strings.Index(arn, ":rule/")
+	if index < 0 {
+		msg := log.Error("Only rules can be tagged, not %s", arn)
+		respondWithError(response, http.StatusBadRequest, errorTypeValidation, msg)
+		return nil
+	}
+
+	parts := strings.Split(arn[index+len(":rule/"):], "/")
+	if len(parts) == 1 {
+		return findRule(response, request, db, types.DefaultEventBusName, parts[0])
+	}
+
+	return findRule(response, request, db, parts[0], parts[1])
+}
+
+func listTagsForResource(response http.ResponseWriter, request *http.Request) {
+	var body types.TagResourceInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := ruleForArn(response, request, db, body.ResourceARN)
+	if rule == nil {
+		return
+	}
+
+	respondWithJson(response, map[string][]types.Tag{"Tags": rule.Tags})
+}
+
+func tagResource(response http.ResponseWriter, request *http.Request) {
+	var body types.TagResourceInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := ruleForArn(response, request, db, body.ResourceARN)
+	if rule == nil {
+		return
+	}
+
+	for _, tag := range body.Tags {
+		rule.Tags = append(withoutTag(rule.Tags, tag.Key), tag)
+	}
+
+	err := queries.SaveRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func untagResource(response http.ResponseWriter, request *http.Request) {
+	var body types.TagResourceInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := ruleForArn(response, request, db, body.ResourceARN)
+	if rule == nil {
+		return
+	}
+
+	for _, key := range body.TagKeys {
+		rule.Tags = withoutTag(rule.Tags, key)
+	}
+
+	err := queries.SaveRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func withoutTag(tags []types.Tag, key string) []types.Tag {
+	results := []types.Tag{}
+	for _, tag := range tags {
+		if tag.Key != key {
+			results = append(results, tag)
+		}
+	}
+
+	return results
+}
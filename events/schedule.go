@@ -0,0 +1,240 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is when a rule with a ScheduleExpression is triggered.
+type schedule interface {
+	// Next returns the first time the rule is triggered after the time, or the zero time when it never is.
+	Next(after time.Time) time.Time
+}
+
+var (
+	rateRegex = regexp.MustCompile(`^rate\((\d+) (minutes?|hours?|days?)\)$`)
+	cronRegex = regexp.MustCompile(`^cron\((.*)\)$`)
+)
+
+func parseSchedule(expression string) (schedule, error) {
+	if groups := rateRegex.FindStringSubmatch(expression); groups != nil {
+		return parseRate(groups[1], groups[2])
+	}
+
+	if groups := cronRegex.FindStringSubmatch(expression); groups != nil {
+		return parseCron(groups[1])
+	}
+
+	return nil, fmt.Errorf("%s is neither a rate() nor cron() expression", expression)
+}
+
+type rateSchedule struct {
+	interval time.Duration
+}
+
+func parseRate(value string, unit string) (schedule, error) {
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("rate value %s must be a positive integer", value)
+	}
+
+	if (count == 1) != !strings.HasSuffix(unit, "s") {
+		return nil, fmt.Errorf("rate unit %s must be singular only when the value is 1", unit)
+	}
+
+	interval := time.Minute
+	switch strings.TrimSuffix(unit, "s") {
+	case "hour":
+		interval = time.Hour
+	case "day":
+		interval = 24 * time.Hour
+	}
+
+	return rateSchedule{interval: time.Duration(count) * interval}, nil
+}
+
+func (rate rateSchedule) Next(after time.Time) time.Time {
+	return after.Add(rate.interval)
+}
+
+// cronSchedule is a cron() expression with the fields minutes, hours, day-of-month, month, day-of-week & year, which
+// are all evaluated in UTC.
+type cronSchedule struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	years       map[int]bool
+
+	// anyDayOfMonth & anyDayOfWeek are set by ?, one of which is required
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+
+	// lastDayOfMonth is set by L in the day-of-month field
+	lastDayOfMonth bool
+
+	// nthDaysOfWeek are set by day#n in the day-of-week field, such as 6#3 for the third Friday of the month
+	nthDaysOfWeek map[int]int
+}
+
+var monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8,
+	"SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+
+var dayNames = map[string]int{"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7}
+
+func parseCron(expression string) (schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("cron expression %s must have 6 fields", expression)
+	}
+
+	var cron cronSchedule
+	var err error
+	if cron.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+
+	if cron.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+
+	switch fields[2] {
+	case "?":
+		cron.anyDayOfMonth = true
+	case "L":
+		cron.lastDayOfMonth = true
+	default:
+		if cron.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if cron.months, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case fields[4] == "?":
+		cron.anyDayOfWeek = true
+	case strings.Contains(fields[4], "#"):
+		parts := strings.Split(fields[4], "#")
+		day, dayErr := parseCronValue(parts[0], 1, 7, dayNames)
+		nth, nthErr := strconv.Atoi(parts[1])
+		if len(parts) != 2 || dayErr != nil || nthErr != nil || nth < 1 || nth > 5 {
+			return nil, fmt.Errorf("day-of-week %s must be like 6#3", fields[4])
+		}
+		cron.nthDaysOfWeek = map[int]int{day: nth}
+	default:
+		if cron.daysOfWeek, err = parseCronField(fields[4], 1, 7, dayNames); err != nil {
+			return nil, err
+		}
+	}
+
+	if cron.anyDayOfMonth == cron.anyDayOfWeek {
+		return nil, errors.New("exactly one of day-of-month & day-of-week must be ?")
+	}
+
+	if cron.years, err = parseCronField(fields[5], 1970, 2199, nil); err != nil {
+		return nil, err
+	}
+
+	return cron, nil
+}
+
+// parseCronField parses a comma separated list of *, values, ranges like 1-5 and increments like */5 or 10/15.
+func parseCronField(field string, min int, max int, names map[string]int) (map[int]bool, error) {
+	results := make(map[int]bool)
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if parts := strings.SplitN(item, "/", 2); len(parts) == 2 {
+			var err error
+			step, err = strconv.Atoi(parts[1])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("increment %s must be a positive integer", parts[1])
+			}
+			item = parts[0]
+		}
+
+		start, end := min, max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			parts := strings.SplitN(item, "-", 2)
+			var err error
+			if start, err = parseCronValue(parts[0], min, max, names); err != nil {
+				return nil, err
+			}
+			if end, err = parseCronValue(parts[1], min, max, names); err != nil {
+				return nil, err
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(item, min, max, names); err != nil {
+				return nil, err
+			}
+			if step == 1 {
+				end = start
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			results[value] = true
+		}
+	}
+
+	return results, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToUpper(value)]; ok {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", value, min, max)
+	}
+
+	return number, nil
+}
+
+func (cron cronSchedule) matchesDay(t time.Time) bool {
+	switch {
+	case cron.lastDayOfMonth:
+		return t.AddDate(0, 0, 1).Day() == 1
+	case !cron.anyDayOfMonth:
+		return cron.daysOfMonth[t.Day()]
+	case cron.nthDaysOfWeek != nil:
+		nth, ok := cron.nthDaysOfWeek[int(t.Weekday())+1]
+		return ok && (t.Day()-1)/7+1 == nth
+	}
+
+	return cron.daysOfWeek[int(t.Weekday())+1]
+}
+
+func (cron cronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	for t.Year() <= 2199 {
+		switch {
+		case !cron.years[t.Year()]:
+			t = time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		case !cron.months[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !cron.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !cron.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !cron.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,196 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+const schedulerInterval = time.Second
+
+// scheduled is when an enabled rule with a schedule is next triggered.
+type scheduled struct {
+	expression string
+	schedule   schedule
+	next       time.Time
+}
+
+// RunScheduler triggers rules with a ScheduleExpression when they are due, until the context is done. Rules are
+// reloaded on every tick, so that changes to them take effect without restarting.
+func RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	log.Info("Starting scheduler for EventBridge rules ...")
+
+	schedules := make(map[string]*scheduled)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			triggerDueRules(ctx, schedules, now)
+		}
+	}
+}
+
+func triggerDueRules(ctx context.Context, schedules map[string]*scheduled, now time.Time) {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	rules, err := queries.ScheduledRules(ctx, db)
+	db.Close()
+
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := range rules {
+		rule := &rules[i]
+		arn := rule.GetArn(ctx)
+		seen[arn] = true
+
+		entry, ok := schedules[arn]
+		if !ok || entry.expression != rule.ScheduleExpression {
+			parsed, err := parseSchedule(rule.ScheduleExpression)
+			if err != nil {
+				log.Error("Unable to schedule rule %s: %v", rule.Name, err)
+				continue
+			}
+
+			entry = &scheduled{expression: rule.ScheduleExpression, schedule: parsed, next: parsed.Next(now)}
+			schedules[arn] = entry
+			log.Info("Rule %s is next triggered at %s", rule.Name, entry.next)
+		}
+
+		if entry.next.IsZero() || now.Before(entry.next) {
+			continue
+		}
+
+		entry.next = entry.schedule.Next(now)
+		go triggerRule(ctx, rule, scheduledEvent(ctx, rule, now))
+	}
+
+	for arn := range schedules {
+		if !seen[arn] {
+			delete(schedules, arn)
+		}
+	}
+}
+
+// scheduledEvent returns the event that EventBridge sends to targets when a rule's schedule triggers it.
+func scheduledEvent(ctx context.Context, rule *types.Rule, now time.Time) types.Event {
+	cfg := settings.FromContext(ctx)
+	return types.Event{
+		Version:    "0",
+		Id:         uuid.Generate().String(),
+		DetailType: "Scheduled Event",
+		Source:     "aws.events",
+		Account:    cfg.AccountNumber,
+		Time:       types.FormatTime(now),
+		Region:     cfg.Region,
+		Resources:  []string{rule.GetArn(ctx)},
+		Detail:     json.RawMessage("{}"),
+	}
+}
+
+// TargetResult is the outcome of delivering an event to one of a rule's targets.
+type TargetResult struct {
+	Id    string
+	Arn   string
+	Error string `json:",omitempty"`
+}
+
+// triggerRule delivers the event to each of the rule's targets, one at a time, recording each delivery.
+func triggerRule(ctx context.Context, rule *types.Rule, event types.Event) []TargetResult {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	targets, err := queries.TargetsByRule(ctx, db, rule)
+	if err != nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Unable to serialize event %s for rule %s: %v", event.Id, rule.Name, err)
+		return nil
+	}
+
+	log.Info("Rule %s was triggered by event %s, delivering to %d targets ...", rule.Name, event.Id, len(targets))
+
+	results := make([]TargetResult, len(targets))
+	for i, target := range targets {
+		results[i] = TargetResult{Id: target.Id, Arn: target.Arn}
+		delivery := types.Delivery{
+			EventId:      event.Id,
+			EventBusName: rule.EventBusName,
+			RuleName:     rule.Name,
+			TargetId:     target.Id,
+			TargetArn:    target.Arn,
+			Source:       event.Source,
+			DetailType:   event.DetailType,
+		}
+
+		input, err := targetInput(ctx, rule, target, payload)
+		if err == nil {
+			delivery.Input = string(input)
+			err = deliver(ctx, rule, target, input)
+		}
+
+		if err != nil {
+			log.Error("Unable to deliver event %s to target %s of rule %s: %v", event.Id, target.Id, rule.Name, err)
+			results[i].Error = err.Error()
+			delivery.Error = err.Error()
+		}
+
+		// the event was already delivered, so failing to record it is only logged
+		delivery.DeliveredOn = time.Now().UnixMilli()
+		queries.InsertDelivery(ctx, db, delivery)
+	}
+
+	return results
+}
+
+const TriggerRuleRegex = `^/_myaws/events/rules/[.\-_A-Za-z0-9]+/trigger$`
+
+// TriggerRule triggers the rule in the path as if its schedule was due, regardless of its schedule or state, so that
+// tests don't need to wait for it. The EventBusName query parameter chooses the rule's bus. Unlike scheduled
+// triggers, the targets are invoked before responding, with the result of each.
+func TriggerRule(response http.ResponseWriter, request *http.Request) {
+	name := strings.Split(request.URL.Path, "/")[4]
+	busName := eventBusName(request.URL.Query().Get("EventBusName"))
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	rule, err := queries.RuleByName(ctx, db, busName, name)
+	db.Close()
+
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Rule %s does not exist on EventBus %s.", name, busName)
+		http.Error(response, msg, http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	event := scheduledEvent(ctx, rule, time.Now())
+	results := triggerRule(ctx, rule, event)
+
+	utils.RespondWithJson(response, map[string]interface{}{"Event": event, "Targets": results})
+}
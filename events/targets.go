@@ -0,0 +1,286 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/events/queries"
+	"myaws/events/types"
+	"myaws/lambda"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/sqs"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxTargetsPerRule = 5
+
+var (
+	placeholderRegex  = regexp.MustCompile(`<([A-Za-z0-9_.-]+)>`)
+	functionNameRegex = regexp.MustCompile(`:function:([A-Za-z0-9_-]+)`)
+)
+
+func putTargets(response http.ResponseWriter, request *http.Request) {
+	var body types.PutTargetsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := findRule(response, request, db, body.EventBusName, body.Rule)
+	if rule == nil {
+		return
+	}
+
+	existing, err := queries.TargetsByRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	ids := make(map[string]bool)
+	for _, target := range existing {
+		ids[target.Id] = true
+	}
+
+	output := types.TargetsOutput{FailedEntries: []types.FailedEntry{}}
+	for _, target := range body.Targets {
+		if len(target.Id) == 0 || len(target.Arn) == 0 {
+			output.FailedEntries = append(output.FailedEntries, types.FailedEntry{
+				TargetId:     target.Id,
+				ErrorCode:    errorTypeValidation,
+				ErrorMessage: "Targets must have an Id and Arn",
+			})
+			continue
+		}
+
+		if !ids[target.Id] && len(ids) >= maxTargetsPerRule {
+			output.FailedEntries = append(output.FailedEntries, types.FailedEntry{
+				TargetId:     target.Id,
+				ErrorCode:    "LimitExceededException",
+				ErrorMessage: "The requested resource exceeds the maximum number allowed.",
+			})
+			continue
+		}
+
+		target.EventBusName = rule.EventBusName
+		target.RuleName = rule.Name
+		err = queries.SaveTarget(ctx, db, target)
+		if err != nil {
+			output.FailedEntries = append(output.FailedEntries, types.FailedEntry{
+				TargetId:     target.Id,
+				ErrorCode:    errorTypeInternal,
+				ErrorMessage: err.Error(),
+			})
+			continue
+		}
+
+		ids[target.Id] = true
+	}
+
+	output.FailedEntryCount = len(output.FailedEntries)
+	respondWithJson(response, output)
+}
+
+func listTargetsByRule(response http.ResponseWriter, request *http.Request) {
+	var body types.ListTargetsByRuleInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := findRule(response, request, db, body.EventBusName, body.Rule)
+	if rule == nil {
+		return
+	}
+
+	targets, err := queries.TargetsByRule(ctx, db, rule)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, map[string][]types.Target{"Targets": targets})
+}
+
+func removeTargets(response http.ResponseWriter, request *http.Request) {
+	var body types.RemoveTargetsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	rule := findRule(response, request, db, body.EventBusName, body.Rule)
+	if rule == nil {
+		return
+	}
+
+	output := types.TargetsOutput{FailedEntries: []types.FailedEntry{}}
+	for _, id := range body.Ids {
+		err := queries.DeleteTarget(ctx, db, rule, id)
+		if err != nil {
+			output.FailedEntries = append(output.FailedEntries, types.FailedEntry{
+				TargetId:     id,
+				ErrorCode:    errorTypeInternal,
+				ErrorMessage: err.Error(),
+			})
+		}
+	}
+
+	output.FailedEntryCount = len(output.FailedEntries)
+	respondWithJson(response, output)
+}
+
+// targetInput returns what the target receives for the event: the whole event, a constant Input, the part of the
+// event at the InputPath, or the InputTransformer's template filled in from the event.
+func targetInput(ctx context.Context, rule *types.Rule, target types.Target, event []byte) ([]byte, error) {
+	switch {
+	case len(target.Input) > 0:
+		return []byte(target.Input), nil
+	case len(target.InputPath) > 0:
+		value, err := extractPath(event, target.InputPath)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	case target.InputTransformer != nil:
+		return transformInput(ctx, rule, target.InputTransformer, event)
+	}
+
+	return event, nil
+}
+
+func transformInput(ctx context.Context, rule *types.Rule, transformer *types.InputTransformer, event []byte) ([]byte, error) {
+	values := map[string]interface{}{
+		"aws.events.rule-arn":   rule.GetArn(ctx),
+		"aws.events.rule-name":  rule.Name,
+		"aws.events.event.json": json.RawMessage(event),
+	}
+
+	for name, path := range transformer.InputPathsMap {
+		value, err := extractPath(event, path)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	template := transformer.InputTemplate
+	isJson := json.Valid([]byte(placeholderRegex.ReplaceAllString(template, "0")))
+	result := placeholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := values[strings.Trim(placeholder, "<>")]
+		if !ok {
+			return placeholder
+		}
+
+		if text, isText := value.(string); isText {
+			if isJson {
+				// strings are placed inside of quotes in JSON templates, so only need escaping
+				escaped, _ := json.Marshal(text)
+				return string(escaped[1 : len(escaped)-1])
+			}
+			return text
+		}
+
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	})
+
+	return []byte(result), nil
+}
+
+// extractPath returns the value at the JSONPath within the JSON document, supporting paths like $.detail.items[0].
+func extractPath(document []byte, path string) (interface{}, error) {
+	var value interface{}
+	err := json.Unmarshal(document, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		msg := log.Error("JSONPath %s must start with $", path)
+		return nil, errors.New(msg)
+	}
+
+	remaining := strings.ReplaceAll(path[1:], "[", ".[")
+	for _, part := range strings.Split(remaining, ".") {
+		if len(part) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(part, "[") {
+			index, err := strconv.Atoi(strings.Trim(part, "[]"))
+			array, ok := value.([]interface{})
+			if err != nil || !ok || index < 0 || index >= len(array) {
+				return nil, nil
+			}
+			value = array[index]
+			continue
+		}
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		value = object[part]
+	}
+
+	return value, nil
+}
+
+// deliver sends the input to the target, which is chosen by the service in its ARN.
+func deliver(ctx context.Context, rule *types.Rule, target types.Target, input []byte) error {
+	parts := strings.SplitN(target.Arn, ":", 6)
+	if len(parts) < 6 {
+		msg := log.Error("Target %s of rule %s has an invalid ARN %s", target.Id, rule.Name, target.Arn)
+		return errors.New(msg)
+	}
+
+	switch parts[2] {
+	case "lambda":
+		return invokeFunction(ctx, rule, target, input)
+	case "sqs":
+		return sqs.SendMessage(ctx, parts[5], string(input), nil)
+	}
+
+	msg := log.Error("Target %s of rule %s is a %s, which isn't supported", target.Id, rule.Name, parts[2])
+	return errors.New(msg)
+}
+
+func invokeFunction(ctx context.Context, rule *types.Rule, target types.Target, input []byte) error {
+	groups := functionNameRegex.FindStringSubmatch(target.Arn)
+	if groups == nil {
+		msg := log.Error("Target %s of rule %s isn't the ARN of a Function: %s", target.Id, rule.Name, target.Arn)
+		return errors.New(msg)
+	}
+
+	name := groups[1]
+	source := lambda.WithInvocationSource(ctx, "events:"+rule.GetArn(ctx))
+	result, err := lambda.Invoke(source, name, input)
+	if err != nil {
+		return err
+	}
+
+	if len(result.FunctionError) > 0 {
+		msg := log.Error("Function %s failed to handle event from rule %s: %s", name, rule.Name, result.Payload)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"context"
+	"myaws/settings"
+)
+
+type EventBus struct {
+	ID        int64
+	Name      string
+	CreatedOn int64
+}
+
+func (bus EventBus) GetArn(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	return "arn:aws:events:" + cfg.ArnFragment() + ":event-bus/" + bus.Name
+}
+
+type EventBusOutput struct {
+	Name string
+	Arn  string
+}
+
+func (bus EventBus) ToOutput(ctx context.Context) EventBusOutput {
+	return EventBusOutput{Name: bus.Name, Arn: bus.GetArn(ctx)}
+}
+
+// EventBusInput identifies an event bus in requests that only need its name.
+type EventBusInput struct {
+	Name       string
+	NamePrefix string
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// Delivery is the record of an event delivered to one of a rule's targets, kept so that tests can check what was
+// delivered.
+type Delivery struct {
+	ID           int64 `json:"-"`
+	EventId      string
+	EventBusName string
+	RuleName     string
+	TargetId     string
+	TargetArn    string
+	Source       string
+	DetailType   string
+	Input        string
+	Error        string `json:",omitempty"`
+	DeliveredOn  int64  `json:"-"`
+	DeliveredAt  string
+}
+
+// DeliveryFilter selects Deliveries, where empty values match any Delivery.
+type DeliveryFilter struct {
+	EventBusName string
+	RuleName     string
+	TargetId     string
+	EventId      string
+	Source       string
+	DetailType   string
+	Input        string
+	Since        int64
+	Limit        int
+}
+
+func (delivery *Delivery) SetDeliveredOn(ms int64) {
+	delivery.DeliveredOn = ms
+	delivery.DeliveredAt = time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+}
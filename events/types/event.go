@@ -0,0 +1,55 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// Event is the envelope of events that EventBridge delivers to targets.
+type Event struct {
+	Version    string          `json:"version"`
+	Id         string          `json:"id"`
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Account    string          `json:"account"`
+	Time       string          `json:"time"`
+	Region     string          `json:"region"`
+	Resources  []string        `json:"resources"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// FormatTime formats the time of an event the way EventBridge does.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+type PutEventsRequestEntry struct {
+	Source       string
+	DetailType   string
+	Detail       string
+	Resources    []string
+	Time         *float64
+	EventBusName string
+}
+
+type PutEventsInput struct {
+	Entries []PutEventsRequestEntry
+}
+
+type PutEventsResultEntry struct {
+	EventId      string `json:",omitempty"`
+	ErrorCode    string `json:",omitempty"`
+	ErrorMessage string `json:",omitempty"`
+}
+
+type PutEventsOutput struct {
+	FailedEntryCount int
+	Entries          []PutEventsResultEntry
+}
+
+type TestEventPatternInput struct {
+	EventPattern string
+	Event        string
+}
@@ -0,0 +1,323 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Pattern is a parsed event pattern, which selects the events a rule is triggered by. SNS subscription filter policies
+// use the same syntax, so it's shared.
+type Pattern struct {
+	fields map[string]fieldPattern
+}
+
+// fieldPattern matches one field of an event, either by a nested pattern when the field is an object, or by a list of
+// matchers where any of them may match.
+type fieldPattern struct {
+	nested   *Pattern
+	matchers []matcher
+}
+
+type matcher interface {
+	// matches reports whether the value of a field matches, where the field is missing when ok is false.
+	matches(value interface{}, ok bool) bool
+}
+
+// ParsePattern parses an event pattern, returning an error describing why it's invalid when it is.
+func ParsePattern(pattern string) (*Pattern, error) {
+	var value interface{}
+	err := json.Unmarshal([]byte(pattern), &value)
+	if err != nil {
+		return nil, fmt.Errorf("event pattern is not valid JSON: %v", err)
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok || len(object) == 0 {
+		return nil, errors.New("event pattern must be a non-empty JSON object")
+	}
+
+	return parseObject(object, "")
+}
+
+func parseObject(object map[string]interface{}, path string) (*Pattern, error) {
+	pattern := &Pattern{fields: make(map[string]fieldPattern, len(object))}
+	for key, value := range object {
+		fieldPath := path + key
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			nested, err := parseObject(typed, fieldPath+".")
+			if err != nil {
+				return nil, err
+			}
+			pattern.fields[key] = fieldPattern{nested: nested}
+		case []interface{}:
+			matchers, err := parseMatchers(typed, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			pattern.fields[key] = fieldPattern{matchers: matchers}
+		default:
+			return nil, fmt.Errorf("match value for %s must be an array or an object", fieldPath)
+		}
+	}
+
+	return pattern, nil
+}
+
+func parseMatchers(values []interface{}, path string) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(values))
+	for _, value := range values {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			if _, isArray := value.([]interface{}); isArray {
+				return nil, fmt.Errorf("match value for %s can't contain arrays", path)
+			}
+			matchers = append(matchers, exactMatcher{value})
+			continue
+		}
+
+		if len(object) != 1 {
+			return nil, fmt.Errorf("content filter for %s must have exactly one operator", path)
+		}
+
+		for operator, operand := range object {
+			m, err := parseOperator(operator, operand, path)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, m)
+		}
+	}
+
+	return matchers, nil
+}
+
+func parseOperator(operator string, operand interface{}, path string) (matcher, error) {
+	switch operator {
+	case "prefix":
+		prefix, ok := operand.(string)
+		if !ok {
+			return nil, fmt.Errorf("prefix for %s must be a string", path)
+		}
+		return prefixMatcher{prefix}, nil
+	case "exists":
+		exists, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("exists for %s must be true or false", path)
+		}
+		return existsMatcher{exists}, nil
+	case "anything-but":
+		return parseAnythingBut(operand, path)
+	case "numeric":
+		return parseNumeric(operand, path)
+	}
+
+	return nil, fmt.Errorf("unsupported operator %s for %s", operator, path)
+}
+
+func parseAnythingBut(operand interface{}, path string) (matcher, error) {
+	switch typed := operand.(type) {
+	case map[string]interface{}:
+		prefix, ok := typed["prefix"].(string)
+		if !ok || len(typed) != 1 {
+			return nil, fmt.Errorf("anything-but for %s only supports a prefix as an object", path)
+		}
+		return anythingButMatcher{prefix: &prefix}, nil
+	case []interface{}:
+		for _, value := range typed {
+			if !isScalar(value) {
+				return nil, fmt.Errorf("anything-but for %s must only contain strings or numbers", path)
+			}
+		}
+		return anythingButMatcher{values: typed}, nil
+	}
+
+	if !isScalar(operand) {
+		return nil, fmt.Errorf("anything-but for %s must be a string, number, array or object", path)
+	}
+	return anythingButMatcher{values: []interface{}{operand}}, nil
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64:
+		return true
+	}
+	return false
+}
+
+func parseNumeric(operand interface{}, path string) (matcher, error) {
+	values, ok := operand.([]interface{})
+	if !ok || len(values) == 0 || len(values)%2 != 0 || len(values) > 4 {
+		return nil, fmt.Errorf("numeric for %s must be one or two pairs of an operator & a number", path)
+	}
+
+	m := numericMatcher{}
+	for i := 0; i < len(values); i += 2 {
+		operator, isText := values[i].(string)
+		number, isNumber := values[i+1].(float64)
+		if !isText || !isNumber {
+			return nil, fmt.Errorf("numeric for %s must be one or two pairs of an operator & a number", path)
+		}
+
+		switch operator {
+		case "=", "<", "<=", ">", ">=":
+			m.comparisons = append(m.comparisons, comparison{operator, number})
+		default:
+			return nil, fmt.Errorf("unsupported numeric operator %s for %s", operator, path)
+		}
+	}
+
+	return m, nil
+}
+
+// Matches reports whether the JSON document, which is usually an event, matches the pattern.
+func (pattern *Pattern) Matches(document []byte) bool {
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return false
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	return pattern.matchesObject(object)
+}
+
+// matchesObject reports whether every field of the pattern matches the object, which is nil when missing.
+func (pattern *Pattern) matchesObject(object map[string]interface{}) bool {
+	for key, field := range pattern.fields {
+		value, ok := object[key]
+		if !field.matches(value, ok) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (field fieldPattern) matches(value interface{}, ok bool) bool {
+	if field.nested != nil {
+		object, _ := value.(map[string]interface{})
+		return field.nested.matchesObject(object)
+	}
+
+	// a field that's an array matches when any of its elements do
+	values, isArray := value.([]interface{})
+	if !isArray {
+		values = []interface{}{value}
+	}
+
+	for _, m := range field.matchers {
+		if _, isExists := m.(existsMatcher); isExists {
+			if m.matches(value, ok) {
+				return true
+			}
+			continue
+		}
+
+		for _, element := range values {
+			if m.matches(element, ok) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type exactMatcher struct {
+	value interface{}
+}
+
+func (m exactMatcher) matches(value interface{}, ok bool) bool {
+	return ok && value == m.value
+}
+
+type prefixMatcher struct {
+	prefix string
+}
+
+func (m prefixMatcher) matches(value interface{}, ok bool) bool {
+	text, isText := value.(string)
+	return ok && isText && strings.HasPrefix(text, m.prefix)
+}
+
+type existsMatcher struct {
+	exists bool
+}
+
+func (m existsMatcher) matches(value interface{}, ok bool) bool {
+	// exists only applies to leaves, so objects are treated like missing fields
+	if _, isObject := value.(map[string]interface{}); isObject {
+		ok = false
+	}
+
+	return ok == m.exists
+}
+
+type anythingButMatcher struct {
+	values []interface{}
+	prefix *string
+}
+
+func (m anythingButMatcher) matches(value interface{}, ok bool) bool {
+	if !ok {
+		return false
+	}
+
+	if m.prefix != nil {
+		text, isText := value.(string)
+		return isText && !strings.HasPrefix(text, *m.prefix)
+	}
+
+	for _, excluded := range m.values {
+		if value == excluded {
+			return false
+		}
+	}
+
+	return true
+}
+
+type comparison struct {
+	operator string
+	number   float64
+}
+
+type numericMatcher struct {
+	comparisons []comparison
+}
+
+func (m numericMatcher) matches(value interface{}, ok bool) bool {
+	number, isNumber := value.(float64)
+	if !ok || !isNumber {
+		return false
+	}
+
+	for _, c := range m.comparisons {
+		var matched bool
+		switch c.operator {
+		case "=":
+			matched = number == c.number
+		case "<":
+			matched = number < c.number
+		case "<=":
+			matched = number <= c.number
+		case ">":
+			matched = number > c.number
+		case ">=":
+			matched = number >= c.number
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
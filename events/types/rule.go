@@ -0,0 +1,104 @@
+package types
+
+import (
+	"context"
+	"myaws/settings"
+)
+
+const (
+	DefaultEventBusName = "default"
+
+	RuleStateEnabled  = "ENABLED"
+	RuleStateDisabled = "DISABLED"
+)
+
+// The version of the SDK used by myaws has no EventBridge client, so these types mirror the API's JSON.
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type PutRuleInput struct {
+	Name               string
+	EventBusName       string
+	ScheduleExpression string
+	EventPattern       string
+	State              string
+	Description        string
+	RoleArn            string
+	Tags               []Tag
+}
+
+type Rule struct {
+	ID                 int64
+	Name               string
+	EventBusName       string
+	ScheduleExpression string
+	EventPattern       string
+	State              string
+	Description        string
+	RoleArn            string
+	Tags               []Tag
+	CreatedOn          int64
+}
+
+// GetArn returns the ARN of the rule, which only includes the name of its event bus for custom buses.
+func (rule Rule) GetArn(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	arn := "arn:aws:events:" + cfg.ArnFragment() + ":rule/"
+	if rule.EventBusName != DefaultEventBusName {
+		arn += rule.EventBusName + "/"
+	}
+
+	return arn + rule.Name
+}
+
+func (rule Rule) IsEnabled() bool {
+	return rule.State == RuleStateEnabled
+}
+
+type RuleOutput struct {
+	Name               string
+	Arn                string
+	EventBusName       string
+	ScheduleExpression string `json:",omitempty"`
+	EventPattern       string `json:",omitempty"`
+	State              string
+	Description        string `json:",omitempty"`
+	RoleArn            string `json:",omitempty"`
+	CreatedBy          string
+}
+
+func (rule Rule) ToOutput(ctx context.Context) RuleOutput {
+	cfg := settings.FromContext(ctx)
+	return RuleOutput{
+		Name:               rule.Name,
+		Arn:                rule.GetArn(ctx),
+		EventBusName:       rule.EventBusName,
+		ScheduleExpression: rule.ScheduleExpression,
+		EventPattern:       rule.EventPattern,
+		State:              rule.State,
+		Description:        rule.Description,
+		RoleArn:            rule.RoleArn,
+		CreatedBy:          cfg.AccountNumber,
+	}
+}
+
+// RuleInput identifies a rule in requests that only need its name.
+type RuleInput struct {
+	Name         string
+	EventBusName string
+	Force        bool
+}
+
+type ListRulesInput struct {
+	EventBusName string
+	NamePrefix   string
+}
+
+type TagResourceInput struct {
+	ResourceARN string
+	Tags        []Tag
+	TagKeys     []string
+}
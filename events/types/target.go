@@ -0,0 +1,48 @@
+package types
+
+type InputTransformer struct {
+	InputPathsMap map[string]string `json:",omitempty"`
+	InputTemplate string
+}
+
+type Target struct {
+	ID               int64  `json:"-"`
+	EventBusName     string `json:"-"`
+	RuleName         string `json:"-"`
+	Id               string
+	Arn              string
+	Input            string            `json:",omitempty"`
+	InputPath        string            `json:",omitempty"`
+	InputTransformer *InputTransformer `json:",omitempty"`
+	RoleArn          string            `json:",omitempty"`
+}
+
+type PutTargetsInput struct {
+	Rule         string
+	EventBusName string
+	Targets      []Target
+}
+
+type RemoveTargetsInput struct {
+	Rule         string
+	EventBusName string
+	Ids          []string
+	Force        bool
+}
+
+type ListTargetsByRuleInput struct {
+	Rule         string
+	EventBusName string
+}
+
+type FailedEntry struct {
+	TargetId     string `json:",omitempty"`
+	EventId      string `json:",omitempty"`
+	ErrorCode    string
+	ErrorMessage string
+}
+
+type TargetsOutput struct {
+	FailedEntryCount int
+	FailedEntries    []FailedEntry
+}
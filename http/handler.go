@@ -4,6 +4,7 @@ import (
 	"io"
 	"myaws/log"
 	"myaws/settings"
+	"net"
 	"net/http"
 	"regexp"
 )
@@ -24,10 +25,23 @@ type route struct {
 	service *string
 	method  string
 	handler http.Handler
+
+	// action is the Action of the form that unsigned requests must have, when set
+	action string
+}
+
+// matchesAction reports whether the request has the form Action the route is restricted to, if any.
+func (route *route) matchesAction(r *http.Request) bool {
+	if len(route.action) == 0 {
+		return true
+	}
+
+	return r.ParseForm() == nil && r.Form.Get("Action") == route.action
 }
 
 type RegexHandler struct {
 	config        *settings.Config
+	hostRoutes    []*route
 	regexRoutes   []*route
 	serviceRoutes []*route
 }
@@ -37,11 +51,28 @@ func (h *RegexHandler) HandleRegex(pattern string, method string, handler func(h
 	if err != nil {
 		panic(err)
 	}
-	h.regexRoutes = append(h.regexRoutes, &route{regex, nil, method, http.HandlerFunc(handler)})
+	h.regexRoutes = append(h.regexRoutes, &route{regex, nil, method, http.HandlerFunc(handler), ""})
+}
+
+// HandleHost routes requests for any path and method to the handler when the hostname, without the port, matches the
+// pattern.
+func (h *RegexHandler) HandleHost(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	h.hostRoutes = append(h.hostRoutes, &route{regex, nil, "", http.HandlerFunc(handler), ""})
 }
 
 func (h *RegexHandler) HandleAuthHeader(service string, method string, handler func(http.ResponseWriter, *http.Request)) {
-	h.serviceRoutes = append(h.serviceRoutes, &route{nil, &service, method, http.HandlerFunc(handler)})
+	h.serviceRoutes = append(h.serviceRoutes, &route{nil, &service, method, http.HandlerFunc(handler), ""})
+}
+
+// HandleUnsigned routes requests without an Authorization header to the handler when the Action of their form is the
+// action, since their service can't be told from their signature.
+func (h *RegexHandler) HandleUnsigned(method string, action string, handler func(http.ResponseWriter, *http.Request)) {
+	service := ""
+	h.serviceRoutes = append(h.serviceRoutes, &route{nil, &service, method, http.HandlerFunc(handler), action})
 }
 
 func (h *RegexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -56,10 +87,32 @@ func (h *RegexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		log.Info("   %s : %s", key, value)
 	}
 
+	auth := r.Header.Get("Authorization")
+	groups := authRegex.FindStringSubmatch(auth)
+	var accessKey, service string
+	if groups != nil {
+		accessKey = groups[1]
+		service = groups[3]
+	}
+
 	ctx := h.config.NewContext(r.Context())
+	ctx = settings.NewCallerContext(ctx, accessKey)
 	r = r.Clone(ctx)
 
-	// Handle regex based Routes first
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	// Handle host based Routes first, since they own every path
+	for _, route := range h.hostRoutes {
+		if route.pattern.MatchString(host) {
+			route.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	// Handle regex based Routes next
 	for _, route := range h.regexRoutes {
 		if route.pattern.MatchString(r.URL.Path) && route.method == r.Method {
 			route.handler.ServeHTTP(w, r)
@@ -67,19 +120,13 @@ func (h *RegexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	auth := r.Header.Get("Authorization")
-	groups := authRegex.FindStringSubmatch(auth)
-	var service string
 	if groups == nil {
 		log.Error("Unable to match Authorization header: %s", auth)
-		service = ""
-	} else {
-		service = groups[3]
 	}
 
 	log.Info("")
 	for _, route := range h.serviceRoutes {
-		if *route.service == service && route.method == r.Method {
+		if *route.service == service && route.method == r.Method && route.matchesAction(r) {
 			route.handler.ServeHTTP(w, r)
 			return
 		}
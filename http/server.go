@@ -2,16 +2,29 @@ package http
 
 import (
 	"errors"
+	"myaws/apigateway"
+	"myaws/dynamodb"
+	"myaws/events"
 	"myaws/iam"
+	"myaws/kinesis"
 	"myaws/lambda"
 	"myaws/log"
+	"myaws/logs"
 	"myaws/s3"
+	"myaws/secretsmanager"
 	"myaws/settings"
+	"myaws/sns"
 	"myaws/sqs"
 	"myaws/ssm"
+	"myaws/sts"
 	"net/http"
+	"strconv"
 )
 
+// allMethods are the methods of routes that proxy any request, like Function URLs and API Gateway stages.
+var allMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions}
+
 func Serve(config *settings.Config) (srv *http.Server, err error) {
 	mux := http.NewServeMux()
 
@@ -19,9 +32,14 @@ func Serve(config *settings.Config) (srv *http.Server, err error) {
 
 	handler.HandleAuthHeader("iam", http.MethodPost, iam.Handler)
 
+	handler.HandleRegex(lambda.ListLayersRegex, http.MethodGet, lambda.ListLayers)
 	handler.HandleRegex(lambda.GetAllLayerVersionsRegex, http.MethodGet, lambda.GetAllLayerVersions)
 	handler.HandleRegex(lambda.GetLayerVersionsRegex, http.MethodGet, lambda.GetLayerVersion)
 	handler.HandleRegex(lambda.PostLayerVersionsRegex, http.MethodPost, lambda.PostLayerVersions)
+	handler.HandleRegex(lambda.DeleteLayerVersionRegex, http.MethodDelete, lambda.DeleteLayerVersion)
+	handler.HandleRegex(lambda.AddLayerVersionPermissionRegex, http.MethodPost, lambda.AddLayerVersionPermission)
+	handler.HandleRegex(lambda.GetLayerVersionPolicyRegex, http.MethodGet, lambda.GetLayerVersionPolicy)
+	handler.HandleRegex(lambda.RemoveLayerVersionPermissionRegex, http.MethodDelete, lambda.RemoveLayerVersionPermission)
 	handler.HandleRegex(lambda.GetLambdaFunctionRegex, http.MethodGet, lambda.GetLambdaFunction)
 	handler.HandleRegex(lambda.GetFunctionCodeSigningRegex, http.MethodGet, lambda.GetFunctionCodeSigning)
 	handler.HandleRegex(lambda.GetFunctionVersionsRegex, http.MethodGet, lambda.GetFunctionVersions)
@@ -30,6 +48,77 @@ func Serve(config *settings.Config) (srv *http.Server, err error) {
 	handler.HandleRegex(lambda.InvokeFunctionRegex, http.MethodPost, lambda.InvokeFunction)
 	handler.HandleRegex(lambda.PostEventSourceRegex, http.MethodPost, lambda.PostEventSource)
 	handler.HandleRegex(lambda.GetEventSourceRegex, http.MethodGet, lambda.GetEventSource)
+	handler.HandleRegex(lambda.InvocationsRegex, http.MethodGet, lambda.GetInvocations)
+	handler.HandleRegex(lambda.InvocationsRegex, http.MethodDelete, lambda.DeleteInvocations)
+	handler.HandleRegex(lambda.FunctionUrlConfigRegex, http.MethodPost, lambda.CreateFunctionUrlConfig)
+	handler.HandleRegex(lambda.FunctionUrlConfigRegex, http.MethodGet, lambda.GetFunctionUrlConfig)
+	handler.HandleRegex(lambda.FunctionUrlConfigRegex, http.MethodPut, lambda.UpdateFunctionUrlConfig)
+	handler.HandleRegex(lambda.FunctionUrlConfigRegex, http.MethodDelete, lambda.DeleteFunctionUrlConfig)
+	handler.HandleRegex(lambda.ListFunctionUrlConfigsRegex, http.MethodGet, lambda.ListFunctionUrlConfigs)
+	for _, method := range allMethods {
+		handler.HandleRegex(lambda.FunctionUrlPathRegex, method, lambda.InvokeFunctionUrlByPath)
+	}
+
+	handler.HandleHost(lambda.FunctionUrlHostRegex, lambda.InvokeFunctionUrlByHost)
+
+	handler.HandleRegex(apigateway.RestApisRegex, http.MethodPost, apigateway.CreateRestApi)
+	handler.HandleRegex(apigateway.RestApisRegex, http.MethodGet, apigateway.GetRestApis)
+	handler.HandleRegex(apigateway.RestApiRegex, http.MethodGet, apigateway.GetRestApi)
+	handler.HandleRegex(apigateway.RestApiRegex, http.MethodPatch, apigateway.UpdateRestApi)
+	handler.HandleRegex(apigateway.RestApiRegex, http.MethodDelete, apigateway.DeleteRestApi)
+	handler.HandleRegex(apigateway.ResourcesRegex, http.MethodGet, apigateway.GetResources)
+	handler.HandleRegex(apigateway.ResourceRegex, http.MethodPost, apigateway.CreateResource)
+	handler.HandleRegex(apigateway.ResourceRegex, http.MethodGet, apigateway.GetResource)
+	handler.HandleRegex(apigateway.ResourceRegex, http.MethodDelete, apigateway.DeleteResource)
+	handler.HandleRegex(apigateway.MethodRegex, http.MethodPut, apigateway.PutMethod)
+	handler.HandleRegex(apigateway.MethodRegex, http.MethodGet, apigateway.GetMethod)
+	handler.HandleRegex(apigateway.MethodRegex, http.MethodPatch, apigateway.UpdateMethod)
+	handler.HandleRegex(apigateway.MethodRegex, http.MethodDelete, apigateway.DeleteMethod)
+	handler.HandleRegex(apigateway.IntegrationRegex, http.MethodPut, apigateway.PutIntegration)
+	handler.HandleRegex(apigateway.IntegrationRegex, http.MethodGet, apigateway.GetIntegration)
+	handler.HandleRegex(apigateway.IntegrationRegex, http.MethodPatch, apigateway.UpdateIntegration)
+	handler.HandleRegex(apigateway.IntegrationRegex, http.MethodDelete, apigateway.DeleteIntegration)
+	handler.HandleRegex(apigateway.HttpApisRegex, http.MethodPost, apigateway.CreateHttpApi)
+	handler.HandleRegex(apigateway.HttpApisRegex, http.MethodGet, apigateway.GetHttpApis)
+	handler.HandleRegex(apigateway.HttpApiRegex, http.MethodGet, apigateway.GetHttpApi)
+	handler.HandleRegex(apigateway.HttpApiRegex, http.MethodPatch, apigateway.UpdateHttpApi)
+	handler.HandleRegex(apigateway.HttpApiRegex, http.MethodDelete, apigateway.DeleteHttpApi)
+	handler.HandleRegex(apigateway.IntegrationsRegex, http.MethodPost, apigateway.CreateHttpIntegration)
+	handler.HandleRegex(apigateway.IntegrationsRegex, http.MethodGet, apigateway.GetHttpIntegrations)
+	handler.HandleRegex(apigateway.HttpIntegrationRegex, http.MethodGet, apigateway.GetHttpIntegration)
+	handler.HandleRegex(apigateway.HttpIntegrationRegex, http.MethodPatch, apigateway.UpdateHttpIntegration)
+	handler.HandleRegex(apigateway.HttpIntegrationRegex, http.MethodDelete, apigateway.DeleteHttpIntegration)
+	handler.HandleRegex(apigateway.RoutesRegex, http.MethodPost, apigateway.CreateRoute)
+	handler.HandleRegex(apigateway.RoutesRegex, http.MethodGet, apigateway.GetRoutes)
+	handler.HandleRegex(apigateway.RouteRegex, http.MethodGet, apigateway.GetRoute)
+	handler.HandleRegex(apigateway.RouteRegex, http.MethodPatch, apigateway.UpdateRoute)
+	handler.HandleRegex(apigateway.RouteRegex, http.MethodDelete, apigateway.DeleteRoute)
+	for _, regex := range []string{apigateway.RestDeploymentsRegex, apigateway.HttpDeploymentsRegex} {
+		handler.HandleRegex(regex, http.MethodPost, apigateway.CreateDeployment)
+		handler.HandleRegex(regex, http.MethodGet, apigateway.GetDeployments)
+	}
+	for _, regex := range []string{apigateway.RestDeploymentRegex, apigateway.HttpDeploymentRegex} {
+		handler.HandleRegex(regex, http.MethodGet, apigateway.GetDeployment)
+		handler.HandleRegex(regex, http.MethodDelete, apigateway.DeleteDeployment)
+	}
+	for _, regex := range []string{apigateway.RestStagesRegex, apigateway.HttpStagesRegex} {
+		handler.HandleRegex(regex, http.MethodPost, apigateway.CreateStage)
+		handler.HandleRegex(regex, http.MethodGet, apigateway.GetStages)
+	}
+	for _, regex := range []string{apigateway.RestStageRegex, apigateway.HttpStageRegex} {
+		handler.HandleRegex(regex, http.MethodGet, apigateway.GetStage)
+		handler.HandleRegex(regex, http.MethodPatch, apigateway.UpdateStage)
+		handler.HandleRegex(regex, http.MethodDelete, apigateway.DeleteStage)
+	}
+	for _, method := range allMethods {
+		handler.HandleRegex(apigateway.ExecuteApiPathRegex, method, apigateway.InvokeApiByPath)
+	}
+
+	handler.HandleHost(apigateway.ExecuteApiHostRegex, apigateway.InvokeApiByHost)
+
+	handler.HandleRegex(events.TriggerRuleRegex, http.MethodPost, events.TriggerRule)
+	handler.HandleRegex(events.DeliveriesRegex, http.MethodGet, events.GetDeliveries)
+	handler.HandleRegex(events.DeliveriesRegex, http.MethodDelete, events.DeleteDeliveries)
 
 	handler.HandleAuthHeader("s3", http.MethodHead, s3.ProxyToMinio)
 	handler.HandleAuthHeader("s3", http.MethodGet, s3.ProxyToMinio)
@@ -38,13 +127,29 @@ func Serve(config *settings.Config) (srv *http.Server, err error) {
 
 	handler.HandleAuthHeader("ssm", http.MethodPost, ssm.Handler)
 
+	handler.HandleAuthHeader("events", http.MethodPost, events.Handler)
+
 	handler.HandleAuthHeader("sqs", http.MethodPost, sqs.ProxyToElasticMQ)
 
+	handler.HandleAuthHeader("sns", http.MethodPost, sns.Handler)
+
+	handler.HandleAuthHeader("dynamodb", http.MethodPost, dynamodb.Handler)
+
+	handler.HandleAuthHeader("kinesis", http.MethodPost, kinesis.Handler)
+
+	handler.HandleAuthHeader("logs", http.MethodPost, logs.Handler)
+
+	handler.HandleAuthHeader("secretsmanager", http.MethodPost, secretsmanager.Handler)
+
+	handler.HandleAuthHeader("sts", http.MethodPost, sts.Handler)
+	// AssumeRoleWithWebIdentity is the only unsigned request to myaws' services
+	handler.HandleUnsigned(http.MethodPost, sts.ActionAssumeRoleWithWebIdentity, sts.Handler)
+
 	mux.Handle("/", &handler)
-	port := 8080
+	port := config.HTTP.Port
 
 	srv = &http.Server{
-		Addr:    ":8080",
+		Addr:    ":" + strconv.Itoa(port),
 		Handler: mux,
 	}
 
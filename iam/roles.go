@@ -0,0 +1,74 @@
+package iam
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// motoAuthorization is sent with myaws' own requests to Moto, which routes them to IAM by the Authorization header
+// but doesn't check it.
+const motoAuthorization = "AWS4-HMAC-SHA256 Credential=myaws/20100508/us-east-1/iam/aws4_request, " +
+	"SignedHeaders=host, Signature=myaws"
+
+// ErrNoSuchRole is returned by GetRole when Moto doesn't hold the role.
+var ErrNoSuchRole = errors.New("no such role")
+
+type Role struct {
+	Path     string
+	RoleName string
+	RoleId   string
+	Arn      string
+}
+
+type getRoleResponse struct {
+	Role Role `xml:"GetRoleResult>Role"`
+}
+
+// GetRole asks Moto for the named role, for myaws' services that act on behalf of roles.
+func GetRole(ctx context.Context, name string) (*Role, error) {
+	cfg := settings.FromContext(ctx)
+	form := url.Values{"Action": {"GetRole"}, "Version": {"2010-05-08"}, "RoleName": {name}}
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Moto.BuildUrl("/"),
+		strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", motoAuthorization)
+
+	client := &http.Client{}
+	resp, err := client.Do(request)
+	if err != nil {
+		msg := log.Error("Unable to get role %s from Moto: %v", name, err)
+		return nil, errors.New(msg)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		msg := log.Error("Unable to read role %s from Moto: %v", name, err)
+		return nil, errors.New(msg)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNoSuchRole
+	case resp.StatusCode != http.StatusOK:
+		msg := log.Error("Moto failed to get role %s with %d: %s", name, resp.StatusCode, body)
+		return nil, errors.New(msg)
+	}
+
+	var result getRoleResponse
+	err = xml.Unmarshal(body, &result)
+	if err != nil {
+		msg := log.Error("Unable to decode role %s from Moto: %v", name, err)
+		return nil, errors.New(msg)
+	}
+
+	return &result.Role, nil
+}
@@ -0,0 +1,63 @@
+package kinesis
+
+import (
+	"encoding/json"
+	"io"
+	"myaws/log"
+	"net/http"
+)
+
+const (
+	targetPrefix = "Kinesis_20131202."
+	contentType  = "application/x-amz-json-1.1"
+)
+
+type action func(http.ResponseWriter, *http.Request)
+
+var actions = map[string]action{
+	targetPrefix + "CreateStream":          createStream,
+	targetPrefix + "DeleteStream":          deleteStream,
+	targetPrefix + "DescribeStream":        describeStream,
+	targetPrefix + "DescribeStreamSummary": describeStreamSummary,
+	targetPrefix + "ListStreams":           listStreams,
+	targetPrefix + "ListShards":            listShards,
+	targetPrefix + "PutRecord":             putRecord,
+	targetPrefix + "PutRecords":            putRecords,
+	targetPrefix + "GetShardIterator":      getShardIterator,
+	targetPrefix + "GetRecords":            getRecords,
+}
+
+// Handler serves Kinesis Data Streams' JSON protocol, dispatching on the action in the X-Amz-Target header. Clients
+// must use JSON rather than CBOR, which is what the AWS CLI & the Go SDK do.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	target := request.Header.Get("X-Amz-Target")
+	action, ok := actions[target]
+	if !ok {
+		msg := log.Error("Unsupported Kinesis action %s", target)
+		respondWithError(response, http.StatusBadRequest, errorTypeUnknownOperation, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, request *http.Request, value interface{}) bool {
+	defer request.Body.Close()
+
+	err := json.NewDecoder(request.Body).Decode(value)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeSerialization, msg)
+		return false
+	}
+
+	return true
+}
+
+func respondWithJson(response http.ResponseWriter, value interface{}) {
+	log.Info("Response: %+v", value)
+
+	response.Header().Set("Content-Type", contentType)
+	json.NewEncoder(response).Encode(value)
+}
@@ -0,0 +1,28 @@
+package kinesis
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "Kinesis",
+		Description: "Create Stream & Record Tables",
+		Query: `CREATE TABLE IF NOT EXISTS kinesis_stream (
+					id						integer primary key autoincrement,
+					name					text not null UNIQUE,
+					shard_count				integer not null,
+					stream_mode				text not null,
+					retention_period_hours	integer not null,
+					created_on				integer not null
+				);
+				CREATE TABLE IF NOT EXISTS kinesis_record (
+					id				integer primary key autoincrement,
+					stream_name		text not null,
+					shard_id		text not null,
+					partition_key	text not null,
+					data			blob not null,
+					arrived_on		integer not null
+				);
+				CREATE INDEX IF NOT EXISTS kinesis_record_shard ON kinesis_record (stream_name, shard_id, id);
+		`,
+	},
+}
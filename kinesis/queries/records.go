@@ -0,0 +1,101 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/database"
+	"myaws/kinesis/types"
+	"myaws/log"
+)
+
+func InsertRecord(ctx context.Context, db *database.Database, record *types.Record) error {
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO kinesis_record (stream_name, shard_id, partition_key, data, arrived_on) VALUES (?, ?, ?, ?, ?)`,
+		record.StreamName,
+		record.ShardId,
+		record.PartitionKey,
+		record.Data,
+		record.ArrivedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save record of stream %s: %v", record.StreamName, err)
+		return errors.New(msg)
+	}
+
+	record.ID = id
+	return nil
+}
+
+// Records returns up to limit records of the shard after the position that arrived since the time in milliseconds,
+// in the order they were put.
+func Records(ctx context.Context, db *database.Database, iterator types.ShardIterator, since int64, limit int) ([]types.Record, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT id, stream_name, shard_id, partition_key, data, arrived_on FROM kinesis_record
+				WHERE stream_name = ? AND shard_id = ? AND id > ? AND arrived_on >= ?
+				ORDER BY id LIMIT ?`,
+		iterator.StreamName,
+		iterator.ShardId,
+		iterator.Position,
+		since,
+		limit,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query records of shard %s: %v", iterator.ShardId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Record{}
+	for rows.Next() {
+		var record types.Record
+		err = rows.Scan(
+			&record.ID,
+			&record.StreamName,
+			&record.ShardId,
+			&record.PartitionKey,
+			&record.Data,
+			&record.ArrivedOn,
+		)
+		if err != nil {
+			msg := log.Error("Unable to scan record of shard %s: %v", iterator.ShardId, err)
+			return nil, errors.New(msg)
+		}
+		results = append(results, record)
+	}
+
+	return results, nil
+}
+
+// LatestPosition returns the position of the last record put into any stream, which is 0 when there are none.
+func LatestPosition(ctx context.Context, db *database.Database) (int64, error) {
+	var position sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT MAX(id) FROM kinesis_record`).Scan(&position)
+	if err != nil {
+		msg := log.Error("Unable to query the latest record: %v", err)
+		return 0, errors.New(msg)
+	}
+
+	return position.Int64, nil
+}
+
+// PositionBefore returns the position of the last record of the shard that arrived before the time in milliseconds.
+func PositionBefore(ctx context.Context, db *database.Database, streamName string, shardId string, before int64) (int64, error) {
+	var position sql.NullInt64
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT MAX(id) FROM kinesis_record WHERE stream_name = ? AND shard_id = ? AND arrived_on < ?`,
+		streamName,
+		shardId,
+		before,
+	).Scan(&position)
+	if err != nil {
+		msg := log.Error("Unable to query records of shard %s: %v", shardId, err)
+		return 0, errors.New(msg)
+	}
+
+	return position.Int64, nil
+}
@@ -0,0 +1,110 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/database"
+	"myaws/kinesis/types"
+	"myaws/log"
+)
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func InsertStream(ctx context.Context, db *database.Database, stream *types.Stream) error {
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO kinesis_stream (name, shard_count, stream_mode, retention_period_hours, created_on)
+					VALUES (?, ?, ?, ?, ?)`,
+		stream.Name,
+		stream.ShardCount,
+		stream.StreamMode,
+		stream.RetentionPeriodHours,
+		stream.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save stream %s: %v", stream.Name, err)
+		return errors.New(msg)
+	}
+
+	stream.ID = id
+	return nil
+}
+
+const selectStream = `SELECT id, name, shard_count, stream_mode, retention_period_hours, created_on FROM kinesis_stream`
+
+// StreamByName returns the stream, or sql.ErrNoRows when there is no such stream.
+func StreamByName(ctx context.Context, db *database.Database, name string) (*types.Stream, error) {
+	row := db.QueryRowContext(ctx, selectStream+` WHERE name = ?`, name)
+	return scanStream(row)
+}
+
+// Streams returns the streams whose names come after the name, ordered by name.
+func Streams(ctx context.Context, db *database.Database, after string) ([]types.Stream, error) {
+	rows, err := db.QueryContext(ctx, selectStream+` WHERE name > ? ORDER BY name`, after)
+	if err != nil {
+		msg := log.Error("Unable to query streams: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Stream{}
+	for rows.Next() {
+		stream, err := scanStream(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *stream)
+	}
+
+	return results, nil
+}
+
+func scanStream(row scanner) (*types.Stream, error) {
+	var stream types.Stream
+	err := row.Scan(
+		&stream.ID,
+		&stream.Name,
+		&stream.ShardCount,
+		&stream.StreamMode,
+		&stream.RetentionPeriodHours,
+		&stream.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan stream: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &stream, nil
+}
+
+// DeleteStream deletes the stream along with its records.
+func DeleteStream(ctx context.Context, db *database.Database, stream *types.Stream) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to delete stream %s: %v", stream.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM kinesis_record WHERE stream_name = ?`, stream.Name)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete records of stream %s: %v", stream.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM kinesis_stream WHERE id = ?`, stream.ID)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete stream %s: %v", stream.Name, err)
+		return errors.New(msg)
+	}
+
+	return tx.Commit()
+}
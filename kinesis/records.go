@@ -0,0 +1,272 @@
+package kinesis
+
+import (
+	"context"
+	"crypto/md5"
+	"math/big"
+	"myaws/database"
+	"myaws/kinesis/queries"
+	"myaws/kinesis/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"time"
+)
+
+const (
+	maxPartitionKeyLength = 256
+	maxDataSize           = 1024 * 1024
+	maxRecordsPerPut      = 500
+	maxRecordsPerGet      = 10000
+)
+
+var maxHashKey = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// shardFor returns the ID of the shard that the entry is put into, or why it can't be put.
+func shardFor(stream *types.Stream, entry types.PutRecordsRequestEntry) (string, string, bool) {
+	if len(entry.PartitionKey) < 1 || len(entry.PartitionKey) > maxPartitionKeyLength {
+		return "", "PartitionKey must have between 1 and 256 characters", false
+	}
+
+	if len(entry.Data) > maxDataSize {
+		return "", "Data must be at most 1 MiB", false
+	}
+
+	// the hash key is the MD5 hash of the partition key as a 128-bit integer, unless it's explicit
+	hash := md5.Sum([]byte(entry.PartitionKey))
+	hashKey := new(big.Int).SetBytes(hash[:])
+	if len(entry.ExplicitHashKey) > 0 {
+		var ok bool
+		hashKey, ok = new(big.Int).SetString(entry.ExplicitHashKey, 10)
+		if !ok || hashKey.Sign() < 0 || hashKey.Cmp(maxHashKey) > 0 {
+			return "", "ExplicitHashKey must be an integer between 0 and 2^128 - 1", false
+		}
+	}
+
+	shardId, ok := stream.ShardFor(hashKey)
+	if !ok {
+		return "", "No shard of the stream has the hash key " + hashKey.String(), false
+	}
+
+	return shardId, "", true
+}
+
+func insertRecord(ctx context.Context, db *database.Database, stream *types.Stream, shardId string, entry types.PutRecordsRequestEntry) (*types.Record, error) {
+	record := types.Record{
+		StreamName:   stream.Name,
+		ShardId:      shardId,
+		PartitionKey: entry.PartitionKey,
+		Data:         entry.Data,
+		ArrivedOn:    time.Now().UnixMilli(),
+	}
+
+	if record.Data == nil {
+		record.Data = []byte{}
+	}
+
+	err := queries.InsertRecord(ctx, db, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func putRecord(response http.ResponseWriter, request *http.Request) {
+	var body types.PutRecordInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body.StreamInput))
+	if stream == nil {
+		return
+	}
+
+	shardId, msg, ok := shardFor(stream, body.PutRecordsRequestEntry)
+	if !ok {
+		log.Error(msg)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	record, err := insertRecord(ctx, db, stream, shardId, body.PutRecordsRequestEntry)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	respondWithJson(response, types.PutRecordOutput{
+		ShardId:        shardId,
+		SequenceNumber: record.SequenceNumber(),
+		EncryptionType: types.EncryptionTypeNone,
+	})
+}
+
+func putRecords(response http.ResponseWriter, request *http.Request) {
+	var body types.PutRecordsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.Records) == 0 || len(body.Records) > maxRecordsPerPut {
+		msg := log.Error("PutRecords must have between 1 and %d records, not %d", maxRecordsPerPut, len(body.Records))
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body.StreamInput))
+	if stream == nil {
+		return
+	}
+
+	// invalid entries fail the whole request, while entries that can't be saved fail on their own, like in Kinesis
+	shardIds := make([]string, len(body.Records))
+	for i, entry := range body.Records {
+		shardId, msg, ok := shardFor(stream, entry)
+		if !ok {
+			log.Error("Record %d: %s", i, msg)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+			return
+		}
+		shardIds[i] = shardId
+	}
+
+	output := types.PutRecordsOutput{
+		Records:        make([]types.PutRecordsResultEntry, len(body.Records)),
+		EncryptionType: types.EncryptionTypeNone,
+	}
+
+	for i, entry := range body.Records {
+		record, err := insertRecord(ctx, db, stream, shardIds[i], entry)
+		if err != nil {
+			output.FailedRecordCount++
+			output.Records[i] = types.PutRecordsResultEntry{ErrorCode: errorTypeInternalFailure, ErrorMessage: err.Error()}
+			continue
+		}
+
+		output.Records[i] = types.PutRecordsResultEntry{ShardId: shardIds[i], SequenceNumber: record.SequenceNumber()}
+	}
+
+	respondWithJson(response, output)
+}
+
+func getShardIterator(response http.ResponseWriter, request *http.Request) {
+	var body types.GetShardIteratorInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body.StreamInput))
+	if stream == nil {
+		return
+	}
+
+	if !stream.HasShard(body.ShardId) {
+		msg := log.Error("Shard %s in stream %s under account %s does not exist", body.ShardId, stream.Name,
+			cfg.AccountNumber)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return
+	}
+
+	iterator := types.ShardIterator{StreamName: stream.Name, ShardId: body.ShardId}
+
+	var err error
+	switch body.ShardIteratorType {
+	case types.ShardIteratorTypeTrimHorizon:
+	case types.ShardIteratorTypeLatest:
+		iterator.Position, err = queries.LatestPosition(ctx, db)
+	case types.ShardIteratorTypeAtSequenceNumber, types.ShardIteratorTypeAfterSequenceNumber:
+		iterator.Position, err = types.ParsePosition(body.StartingSequenceNumber)
+		if err != nil {
+			msg := log.Error("StartingSequenceNumber %s is invalid", body.StartingSequenceNumber)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+			return
+		}
+
+		if body.ShardIteratorType == types.ShardIteratorTypeAtSequenceNumber {
+			iterator.Position--
+		}
+	case types.ShardIteratorTypeAtTimestamp:
+		before := int64(body.Timestamp * 1000)
+		iterator.Position, err = queries.PositionBefore(ctx, db, stream.Name, body.ShardId, before)
+	default:
+		msg := log.Error("ShardIteratorType %s is invalid", body.ShardIteratorType)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	respondWithJson(response, map[string]string{"ShardIterator": iterator.Encode()})
+}
+
+func getRecords(response http.ResponseWriter, request *http.Request) {
+	var body types.GetRecordsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	iterator, err := types.DecodeShardIterator(body.ShardIterator)
+	if err != nil {
+		msg := log.Error("ShardIterator %s is invalid", body.ShardIterator)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 || limit > maxRecordsPerGet {
+		limit = maxRecordsPerGet
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, iterator.StreamName)
+	if stream == nil {
+		return
+	}
+
+	records, err := queries.Records(ctx, db, iterator, stream.RetentionStart(), limit)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	output := types.GetRecordsOutput{Records: make([]types.RecordOutput, len(records))}
+	for i, record := range records {
+		output.Records[i] = record.ToOutput()
+	}
+
+	// shards are never closed, so there is always a next iterator
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		iterator.Position = last.ID
+		if len(records) == limit {
+			output.MillisBehindLatest = time.Now().UnixMilli() - last.ArrivedOn
+		}
+	}
+	output.NextShardIterator = iterator.Encode()
+
+	respondWithJson(response, output)
+}
@@ -0,0 +1,239 @@
+package kinesis
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/kinesis/queries"
+	"myaws/kinesis/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxShardCount      = 500
+	defaultStreamLimit = 100
+)
+
+var streamNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,128}$`)
+
+// streamName returns the name of the stream in a request, which may have either its name or its ARN.
+func streamName(input types.StreamInput) string {
+	if len(input.StreamName) > 0 {
+		return input.StreamName
+	}
+
+	return input.StreamARN[strings.LastIndex(input.StreamARN, "/")+1:]
+}
+
+// findStream writes an error response & returns nil when the stream doesn't exist.
+func findStream(response http.ResponseWriter, request *http.Request, db *database.Database, name string) *types.Stream {
+	ctx := request.Context()
+	stream, err := queries.StreamByName(ctx, db, name)
+	switch {
+	case err == sql.ErrNoRows:
+		cfg := settings.FromContext(ctx)
+		msg := log.Error("Stream %s under account %s not found.", name, cfg.AccountNumber)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return nil
+	}
+
+	return stream
+}
+
+func createStream(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateStreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !streamNameRegex.MatchString(body.StreamName) {
+		msg := log.Error("Stream name %s must match %s", body.StreamName, streamNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	stream := types.Stream{
+		Name:                 body.StreamName,
+		ShardCount:           types.OnDemandShardCount,
+		StreamMode:           types.StreamModeProvisioned,
+		RetentionPeriodHours: types.DefaultRetentionPeriodHours,
+		CreatedOn:            time.Now().UnixMilli(),
+	}
+
+	if body.StreamModeDetails != nil {
+		stream.StreamMode = body.StreamModeDetails.StreamMode
+	}
+
+	switch {
+	case stream.StreamMode == types.StreamModeOnDemand && body.ShardCount == nil:
+	case stream.StreamMode == types.StreamModeProvisioned && body.ShardCount != nil:
+		stream.ShardCount = *body.ShardCount
+	default:
+		msg := log.Error("ShardCount is required for PROVISIONED streams & not allowed for ON_DEMAND ones")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	if stream.ShardCount < 1 || stream.ShardCount > maxShardCount {
+		msg := log.Error("ShardCount must be between 1 and %d, not %d", maxShardCount, stream.ShardCount)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidArgument, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	_, err := queries.StreamByName(ctx, db, stream.Name)
+	switch {
+	case err == nil:
+		msg := log.Error("Stream %s under account %s already exists.", stream.Name, cfg.AccountNumber)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceInUse, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	// streams are created ACTIVE, rather than going through CREATING like they do in Kinesis
+	err = queries.InsertStream(ctx, db, &stream)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func deleteStream(response http.ResponseWriter, request *http.Request) {
+	var body types.StreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body))
+	if stream == nil {
+		return
+	}
+
+	err := queries.DeleteStream(ctx, db, stream)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func describeStream(response http.ResponseWriter, request *http.Request) {
+	var body types.StreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body))
+	if stream == nil {
+		return
+	}
+
+	respondWithJson(response, map[string]types.StreamDescription{
+		"StreamDescription": stream.ToDescription(request.Context()),
+	})
+}
+
+func describeStreamSummary(response http.ResponseWriter, request *http.Request) {
+	var body types.StreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body))
+	if stream == nil {
+		return
+	}
+
+	respondWithJson(response, map[string]types.StreamDescriptionSummary{
+		"StreamDescriptionSummary": stream.ToSummary(request.Context()),
+	})
+}
+
+func listStreams(response http.ResponseWriter, request *http.Request) {
+	var body types.ListStreamsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	streams, err := queries.Streams(ctx, db, body.ExclusiveStartStreamName)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternalFailure, err.Error())
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 {
+		limit = defaultStreamLimit
+	}
+
+	hasMore := len(streams) > limit
+	if hasMore {
+		streams = streams[:limit]
+	}
+
+	names := make([]string, len(streams))
+	summaries := make([]types.StreamSummary, len(streams))
+	for i, stream := range streams {
+		names[i] = stream.Name
+		summaries[i] = stream.ToStreamSummary(ctx)
+	}
+
+	respondWithJson(response, map[string]interface{}{
+		"StreamNames":     names,
+		"StreamSummaries": summaries,
+		"HasMoreStreams":  hasMore,
+	})
+}
+
+func listShards(response http.ResponseWriter, request *http.Request) {
+	var body types.StreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findStream(response, request, db, streamName(body))
+	if stream == nil {
+		return
+	}
+
+	// all the shards fit in one page, since streams have at most 500 of them
+	respondWithJson(response, map[string][]types.Shard{"Shards": stream.Shards()})
+}
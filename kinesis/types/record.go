@@ -0,0 +1,151 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ShardIteratorTypeTrimHorizon         = "TRIM_HORIZON"
+	ShardIteratorTypeLatest              = "LATEST"
+	ShardIteratorTypeAtSequenceNumber    = "AT_SEQUENCE_NUMBER"
+	ShardIteratorTypeAfterSequenceNumber = "AFTER_SEQUENCE_NUMBER"
+	ShardIteratorTypeAtTimestamp         = "AT_TIMESTAMP"
+)
+
+// Record is a data record put into a shard. Its sequence number is derived from its ID, which increases across all
+// the shards of all the streams.
+type Record struct {
+	ID           int64
+	StreamName   string
+	ShardId      string
+	PartitionKey string
+	Data         []byte
+	ArrivedOn    int64
+}
+
+// SequenceNumber returns the sequence number of the position, which is the ID of a record, with as many digits as
+// the sequence numbers of Kinesis.
+func SequenceNumber(position int64) string {
+	return fmt.Sprintf("%056d", position)
+}
+
+// ParsePosition returns the position of the sequence number.
+func ParsePosition(sequenceNumber string) (int64, error) {
+	trimmed := strings.TrimLeft(sequenceNumber, "0")
+	if len(trimmed) == 0 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(trimmed, 10, 64)
+}
+
+func (record Record) SequenceNumber() string {
+	return SequenceNumber(record.ID)
+}
+
+// ArrivalTimestamp returns when the record was put, in seconds since the epoch.
+func (record Record) ArrivalTimestamp() float64 {
+	return float64(record.ArrivedOn) / 1000
+}
+
+type RecordOutput struct {
+	SequenceNumber              string
+	ApproximateArrivalTimestamp float64
+	Data                        []byte
+	PartitionKey                string
+}
+
+func (record Record) ToOutput() RecordOutput {
+	return RecordOutput{
+		SequenceNumber:              record.SequenceNumber(),
+		ApproximateArrivalTimestamp: record.ArrivalTimestamp(),
+		Data:                        record.Data,
+		PartitionKey:                record.PartitionKey,
+	}
+}
+
+type PutRecordsRequestEntry struct {
+	Data            []byte
+	PartitionKey    string
+	ExplicitHashKey string
+}
+
+type PutRecordInput struct {
+	StreamInput
+	PutRecordsRequestEntry
+}
+
+type PutRecordOutput struct {
+	ShardId        string
+	SequenceNumber string
+	EncryptionType string
+}
+
+type PutRecordsInput struct {
+	StreamInput
+	Records []PutRecordsRequestEntry
+}
+
+type PutRecordsResultEntry struct {
+	ShardId        string `json:",omitempty"`
+	SequenceNumber string `json:",omitempty"`
+	ErrorCode      string `json:",omitempty"`
+	ErrorMessage   string `json:",omitempty"`
+}
+
+type PutRecordsOutput struct {
+	FailedRecordCount int
+	Records           []PutRecordsResultEntry
+	EncryptionType    string
+}
+
+type GetShardIteratorInput struct {
+	StreamInput
+	ShardId                string
+	ShardIteratorType      string
+	StartingSequenceNumber string
+	Timestamp              float64
+}
+
+type GetRecordsInput struct {
+	ShardIterator string
+	Limit         int
+}
+
+type GetRecordsOutput struct {
+	Records            []RecordOutput
+	NextShardIterator  string
+	MillisBehindLatest int64
+}
+
+// ShardIterator is where reading a shard continues from, which is after the record at the position. Clients get it
+// encoded, as an opaque string.
+type ShardIterator struct {
+	StreamName string
+	ShardId    string
+	Position   int64
+}
+
+func (iterator ShardIterator) Encode() string {
+	value, _ := json.Marshal(iterator)
+	return base64.StdEncoding.EncodeToString(value)
+}
+
+func DecodeShardIterator(value string) (ShardIterator, error) {
+	var iterator ShardIterator
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(decoded, &iterator)
+	}
+
+	if err != nil || len(iterator.StreamName) == 0 {
+		return iterator, errors.New("invalid ShardIterator")
+	}
+
+	return iterator, nil
+}
@@ -0,0 +1,214 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"myaws/settings"
+	"time"
+)
+
+const (
+	StreamModeProvisioned = "PROVISIONED"
+	StreamModeOnDemand    = "ON_DEMAND"
+
+	StreamStatusActive = "ACTIVE"
+
+	EncryptionTypeNone = "NONE"
+
+	// OnDemandShardCount is the number of shards of on-demand streams, which Kinesis starts them with
+	OnDemandShardCount = 4
+
+	DefaultRetentionPeriodHours = 24
+)
+
+// Stream is a data stream, whose shards are fixed when it's created since myaws doesn't reshard streams.
+type Stream struct {
+	ID                   int64
+	Name                 string
+	ShardCount           int32
+	StreamMode           string
+	RetentionPeriodHours int32
+	CreatedOn            int64
+}
+
+func StreamArn(ctx context.Context, name string) string {
+	cfg := settings.FromContext(ctx)
+	return "arn:aws:kinesis:" + cfg.ArnFragment() + ":stream/" + name
+}
+
+func (stream Stream) GetArn(ctx context.Context) string {
+	return StreamArn(ctx, stream.Name)
+}
+
+// ShardId returns the ID of the stream's shard at the index.
+func ShardId(index int) string {
+	return fmt.Sprintf("shardId-%012d", index)
+}
+
+// Shards returns the stream's shards, which split the range of 128-bit hash keys evenly.
+func (stream Stream) Shards() []Shard {
+	count := big.NewInt(int64(stream.ShardCount))
+	keys := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	results := make([]Shard, stream.ShardCount)
+	for i := range results {
+		start := new(big.Int).Mul(keys, big.NewInt(int64(i)))
+		start.Div(start, count)
+		end := new(big.Int).Mul(keys, big.NewInt(int64(i+1)))
+		end.Div(end, count).Sub(end, big.NewInt(1))
+
+		results[i] = Shard{
+			ShardId:             ShardId(i),
+			HashKeyRange:        HashKeyRange{StartingHashKey: start.String(), EndingHashKey: end.String()},
+			SequenceNumberRange: SequenceNumberRange{StartingSequenceNumber: SequenceNumber(0)},
+		}
+	}
+
+	return results
+}
+
+// ShardFor returns the ID of the shard whose range of hash keys has the hash key, or false when none has it.
+func (stream Stream) ShardFor(hashKey *big.Int) (string, bool) {
+	for _, shard := range stream.Shards() {
+		start, _ := new(big.Int).SetString(shard.HashKeyRange.StartingHashKey, 10)
+		end, _ := new(big.Int).SetString(shard.HashKeyRange.EndingHashKey, 10)
+		if hashKey.Cmp(start) >= 0 && hashKey.Cmp(end) <= 0 {
+			return shard.ShardId, true
+		}
+	}
+
+	return "", false
+}
+
+func (stream Stream) HasShard(shardId string) bool {
+	for _, shard := range stream.Shards() {
+		if shard.ShardId == shardId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (stream Stream) creationTimestamp() float64 {
+	return float64(stream.CreatedOn) / 1000
+}
+
+// StreamInput identifies a stream in requests, by either its name or its ARN.
+type StreamInput struct {
+	StreamName string
+	StreamARN  string
+}
+
+type CreateStreamInput struct {
+	StreamName        string
+	ShardCount        *int32
+	StreamModeDetails *StreamModeDetails
+}
+
+type StreamModeDetails struct {
+	StreamMode string
+}
+
+type ListStreamsInput struct {
+	ExclusiveStartStreamName string
+	Limit                    int
+}
+
+type HashKeyRange struct {
+	StartingHashKey string
+	EndingHashKey   string
+}
+
+type SequenceNumberRange struct {
+	StartingSequenceNumber string
+	EndingSequenceNumber   string `json:",omitempty"`
+}
+
+type Shard struct {
+	ShardId             string
+	HashKeyRange        HashKeyRange
+	SequenceNumberRange SequenceNumberRange
+}
+
+type EnhancedMetrics struct {
+	ShardLevelMetrics []string
+}
+
+type StreamDescription struct {
+	StreamName              string
+	StreamARN               string
+	StreamStatus            string
+	StreamModeDetails       StreamModeDetails
+	Shards                  []Shard
+	HasMoreShards           bool
+	RetentionPeriodHours    int32
+	StreamCreationTimestamp float64
+	EnhancedMonitoring      []EnhancedMetrics
+	EncryptionType          string
+}
+
+func (stream Stream) ToDescription(ctx context.Context) StreamDescription {
+	return StreamDescription{
+		StreamName:              stream.Name,
+		StreamARN:               stream.GetArn(ctx),
+		StreamStatus:            StreamStatusActive,
+		StreamModeDetails:       StreamModeDetails{StreamMode: stream.StreamMode},
+		Shards:                  stream.Shards(),
+		RetentionPeriodHours:    stream.RetentionPeriodHours,
+		StreamCreationTimestamp: stream.creationTimestamp(),
+		EnhancedMonitoring:      []EnhancedMetrics{{ShardLevelMetrics: []string{}}},
+		EncryptionType:          EncryptionTypeNone,
+	}
+}
+
+type StreamDescriptionSummary struct {
+	StreamName              string
+	StreamARN               string
+	StreamStatus            string
+	StreamModeDetails       StreamModeDetails
+	RetentionPeriodHours    int32
+	StreamCreationTimestamp float64
+	EnhancedMonitoring      []EnhancedMetrics
+	EncryptionType          string
+	OpenShardCount          int32
+	ConsumerCount           int32
+}
+
+func (stream Stream) ToSummary(ctx context.Context) StreamDescriptionSummary {
+	return StreamDescriptionSummary{
+		StreamName:              stream.Name,
+		StreamARN:               stream.GetArn(ctx),
+		StreamStatus:            StreamStatusActive,
+		StreamModeDetails:       StreamModeDetails{StreamMode: stream.StreamMode},
+		RetentionPeriodHours:    stream.RetentionPeriodHours,
+		StreamCreationTimestamp: stream.creationTimestamp(),
+		EnhancedMonitoring:      []EnhancedMetrics{{ShardLevelMetrics: []string{}}},
+		EncryptionType:          EncryptionTypeNone,
+		OpenShardCount:          stream.ShardCount,
+	}
+}
+
+type StreamSummary struct {
+	StreamName              string
+	StreamARN               string
+	StreamStatus            string
+	StreamModeDetails       StreamModeDetails
+	StreamCreationTimestamp float64
+}
+
+func (stream Stream) ToStreamSummary(ctx context.Context) StreamSummary {
+	return StreamSummary{
+		StreamName:              stream.Name,
+		StreamARN:               stream.GetArn(ctx),
+		StreamStatus:            StreamStatusActive,
+		StreamModeDetails:       StreamModeDetails{StreamMode: stream.StreamMode},
+		StreamCreationTimestamp: stream.creationTimestamp(),
+	}
+}
+
+// RetentionStart returns the time before which records of the stream have expired, in milliseconds.
+func (stream Stream) RetentionStart() int64 {
+	return time.Now().Add(-time.Duration(stream.RetentionPeriodHours) * time.Hour).UnixMilli()
+}
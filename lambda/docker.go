@@ -1,6 +1,7 @@
 package lambda
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -14,65 +15,231 @@ import (
 	"myaws/log"
 	"myaws/settings"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var manager *ManagerImpl
 
+const functionErrorUnhandled = "Unhandled"
+
 type Manager interface {
-	Add(name string, port int)
+	Add(name string, port int, path string)
+	AddRuntimeApi(name string, api *RuntimeApi)
 	Invoke(response http.ResponseWriter, request *http.Request)
+	InvokeFunction(ctx context.Context, name string, payload []byte) (*InvokeResult, error)
 	StartEventSource(ctx context.Context, eventSource *types.EventSource)
 }
 
+// InvokeResult is what a Function returned for a single invocation.
+type InvokeResult struct {
+	RequestId     string
+	StatusCode    int
+	Payload       []byte
+	FunctionError string
+	LogResult     string
+
+	// ExecutedVersion is the version of the Function that handled the invocation.
+	ExecutedVersion string
+}
+
 type ManagerImpl struct {
+	mutex        sync.RWMutex
 	ports        map[string]int
+	paths        map[string]string
+	apis         map[string]*RuntimeApi
+	watchers     map[string]context.CancelFunc
 	eventSources map[uuid.UUID]context.CancelFunc
+
+	starting    map[string]*sync.Mutex
+	inFlight    map[string]int
+	lastUsed    map[string]time.Time
+	memorySizes map[string]int32
 }
 
-func (manager *ManagerImpl) Add(name string, port int) {
+func getManager() *ManagerImpl {
+	if manager == nil {
+		manager = &ManagerImpl{
+			ports:        make(map[string]int),
+			paths:        make(map[string]string),
+			apis:         make(map[string]*RuntimeApi),
+			watchers:     make(map[string]context.CancelFunc),
+			eventSources: make(map[uuid.UUID]context.CancelFunc),
+			starting:     make(map[string]*sync.Mutex),
+			inFlight:     make(map[string]int),
+			lastUsed:     make(map[string]time.Time),
+			memorySizes:  make(map[string]int32),
+		}
+	}
+
+	return manager
+}
+
+// Add registers the port and path where a Function's container accepts invocations.
+func (manager *ManagerImpl) Add(name string, port int, path string) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
 	manager.ports[name] = port
+	manager.paths[name] = path
+}
+
+// AddRuntimeApi registers a Function whose runtime polls myaws for invocations through the Runtime API.
+func (manager *ManagerImpl) AddRuntimeApi(name string, api *RuntimeApi) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.apis[name] = api
+}
+
+// Remove forgets a Function, returning the port that it was using so that it can be released.
+func (manager *ManagerImpl) Remove(ctx context.Context, name string) (int, bool) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	api, ok := manager.apis[name]
+	if ok {
+		delete(manager.apis, name)
+		err := api.Close(ctx)
+		if err != nil {
+			log.Error("Unable to close Runtime API for Function %s: %v", name, err)
+		}
+		return api.port, true
+	}
+
+	port, ok := manager.ports[name]
+	delete(manager.ports, name)
+	delete(manager.paths, name)
+	return port, ok
 }
 
 func (manager *ManagerImpl) Invoke(name string, response *http.ResponseWriter, request *http.Request) {
-	log.Info("Invoking Function %s ...", name)
+	payload, err := io.ReadAll(request.Body)
+	if err != nil {
+		msg := log.Error("Unable to read payload to invoke %s: %v", name, err)
+		http.Error(*response, msg, http.StatusInternalServerError)
+		return
+	}
 
-	url := fmt.Sprintf("http://%s:%d%s", "localhost", manager.ports[name], request.URL.Path)
+	ctx := WithQualifier(request.Context(), request.URL.Query().Get("Qualifier"))
+	result, err := manager.InvokeFunction(ctx, name, payload)
+	if err != nil {
+		http.Error(*response, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	//var proxyRequestBody strings.Builder
-	//requestBody := io.TeeReader(request.Body, &proxyRequestBody)
-	//authorization := request.Header.Get("Authorization")
-	//contentType := request.Header.Get("Content-Type")
-	//target := request.Header.Get("X-Amz-Target")
+	header := (*response).Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Amzn-RequestId", result.RequestId)
+	header.Set("X-Amz-Executed-Version", result.ExecutedVersion)
+	if len(result.FunctionError) > 0 {
+		header.Set("X-Amz-Function-Error", result.FunctionError)
+	}
+	if len(result.LogResult) > 0 {
+		header.Set("X-Amz-Log-Result", result.LogResult)
+	}
 
-	proxyReq, _ := http.NewRequest(request.Method, url, request.Body)
-	//proxyReq.Header.Set("Content-Type", contentType)
-	//proxyReq.Header.Set("Authorization", authorization)
-	//if len(target) > 0 {
-	//	proxyReq.Header.Set("X-Amz-Target", target)
-	//}
+	(*response).WriteHeader(result.StatusCode)
+	(*response).Write(result.Payload)
+}
+
+// InvokeFunction synchronously invokes the named Function with the payload, starting it first if it isn't running.
+func (manager *ManagerImpl) InvokeFunction(ctx context.Context, name string, payload []byte) (*InvokeResult, error) {
+	requestId := uuid.Generate().String()
+	log.Info("Invoking Function %s with request id %s ...", name, requestId)
+
+	manager.invocationStarted(name)
+	defer manager.invocationFinished(name)
+
+	invocation := types.Invocation{
+		FunctionName: name,
+		Version:      invocationQualifier(ctx),
+		RequestId:    requestId,
+		Payload:      string(payload),
+	}
+	invocation.SetInvokedOn(time.Now().UnixMilli())
+
+	version, err := executedVersion(ctx, name)
+	if err != nil {
+		recordInvocation(ctx, invocation, nil, err)
+		return nil, err
+	}
+
+	initDuration, err := manager.ensureRunning(ctx, name)
+	if err != nil {
+		recordInvocation(ctx, invocation, nil, err)
+		return nil, err
+	}
+
+	begin := time.Now()
+	result, err := manager.invokeRunning(ctx, name, requestId, payload)
+	duration := time.Since(begin)
+
+	if err == nil {
+		result.ExecutedVersion = version
+
+		manager.mutex.RLock()
+		memorySize := manager.memorySizes[name]
+		manager.mutex.RUnlock()
+
+		logReport(requestId, duration, memorySize, initDuration)
+	}
+
+	invocation.DurationMs = float64(duration.Microseconds()) / 1000
+	invocation.InitDurationMs = float64(initDuration.Microseconds()) / 1000
+	recordInvocation(ctx, invocation, result, err)
+
+	return result, err
+}
+
+// invokeRunning invokes the Function either through its Runtime API or by proxying to the invoke endpoint of its
+// container.
+func (manager *ManagerImpl) invokeRunning(ctx context.Context, name string, requestId string, payload []byte) (*InvokeResult, error) {
+	manager.mutex.RLock()
+	api, isApi := manager.apis[name]
+	port, isRunning := manager.ports[name]
+	path := manager.paths[name]
+	manager.mutex.RUnlock()
+
+	if isApi {
+		return api.Invoke(ctx, requestId, payload)
+	}
+
+	if !isRunning {
+		msg := log.Error("... Function %s is not running", name)
+		return nil, errors.New(msg)
+	}
+
+	url := fmt.Sprintf("http://%s:%d%s", "localhost", port, path)
+	proxyReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 
 	client := &http.Client{}
 	resp, err := client.Do(proxyReq)
 	if err != nil {
 		msg := log.Error("... unable to invoke %s: %v", name, err)
-		http.Error(*response, msg, http.StatusInternalServerError)
-		return
+		return nil, errors.New(msg)
 	}
+	defer resp.Body.Close()
 
 	log.Debug("Got following response when invoking Function %s: %+v", name, resp)
 
-	for key, value := range resp.Header {
-		for _, v := range value {
-			(*response).Header().Add(key, v)
-		}
-
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		msg := log.Error("... unable to read response when invoking %s: %v", name, err)
+		return nil, errors.New(msg)
 	}
 
-	(*response).WriteHeader(resp.StatusCode)
-
-	io.Copy(*response, resp.Body)
-	resp.Body.Close()
+	return &InvokeResult{
+		RequestId:     requestId,
+		StatusCode:    resp.StatusCode,
+		Payload:       body,
+		FunctionError: resp.Header.Get("X-Amz-Function-Error"),
+		LogResult:     resp.Header.Get("X-Amz-Log-Result"),
+	}, nil
 }
 
 var credentials aws.CredentialsProviderFunc = func(ctx context.Context) (aws.Credentials, error) {
@@ -86,17 +253,55 @@ var endpointResolver aws.EndpointResolverWithOptionsFunc = func(service, region
 	}, nil
 }
 
+// Invoke synchronously invokes the named Function with the payload, starting it first if it isn't running. Other
+// services integrating with Lambda invoke Functions through it.
+func Invoke(ctx context.Context, name string, payload []byte) (*InvokeResult, error) {
+	return getManager().InvokeFunction(ctx, name, payload)
+}
+
 func StartEventSource(ctx context.Context, eventSource *types.EventSource) error {
-	return manager.StartEventSource(ctx, eventSource)
+	return getManager().StartEventSource(ctx, eventSource)
 }
 
+// StartEventSource starts polling the queue or stream of the event source, stopping any previous poller of it.
 func (manager *ManagerImpl) StartEventSource(ctx context.Context, eventSource *types.EventSource) error {
+	runCtx, cancel := context.WithCancel(ctx)
+
+	var err error
+	switch eventSource.Service() {
+	case "sqs":
+		err = startQueuePoller(runCtx, eventSource)
+	case "dynamodb":
+		err = startStreamPoller(runCtx, eventSource)
+	case "kinesis":
+		err = startKinesisPoller(runCtx, eventSource)
+	default:
+		msg := log.Error("Event Source %s isn't a queue, a DynamoDB stream or a Kinesis stream", eventSource.Arn)
+		err = errors.New(msg)
+	}
+
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if previous, ok := manager.eventSources[eventSource.UUID]; ok {
+		previous()
+	}
+	manager.eventSources[eventSource.UUID] = cancel
+
+	return nil
+}
+
+func startQueuePoller(ctx context.Context, eventSource *types.EventSource) error {
 	parts := strings.Split(eventSource.Arn, ":")
 	queueName := parts[5]
 
 	log.Info("Starting consumption from Queue %s ...", queueName)
 
-	runCtx, cancel := context.WithCancel(ctx)
 	cfg := aws.Config{
 		Region:                      "us-west-2",
 		Credentials:                 credentials,
@@ -119,13 +324,11 @@ func (manager *ManagerImpl) StartEventSource(ctx context.Context, eventSource *t
 	listQueuesOutput, err := client.ListQueues(ctx, &sqs.ListQueuesInput{QueueNamePrefix: &queueName})
 	if err != nil {
 		msg := log.Error("Unable to list queues for %s: %v", queueName, err)
-		cancel()
 		return errors.New(msg)
 	}
 
 	if len(listQueuesOutput.QueueUrls) != 1 {
 		msg := log.Error("Found %d queue urls for %s: %v", len(listQueuesOutput.QueueUrls), queueName, listQueuesOutput.QueueUrls)
-		cancel()
 		return errors.New(msg)
 	}
 
@@ -143,7 +346,7 @@ func (manager *ManagerImpl) StartEventSource(ctx context.Context, eventSource *t
 	go func() {
 		for {
 			select {
-			case <-runCtx.Done():
+			case <-ctx.Done():
 				return
 			default:
 				receiveMessageOutput, err := client.ReceiveMessage(ctx, &receiveMessageInput)
@@ -162,12 +365,11 @@ func (manager *ManagerImpl) StartEventSource(ctx context.Context, eventSource *t
 		}
 	}()
 
-	manager.eventSources[eventSource.UUID] = cancel
-
 	return nil
 }
 
 type PortPool struct {
+	mutex     *sync.Mutex
 	available map[int]bool
 }
 
@@ -176,7 +378,7 @@ var pool *PortPool
 func NewPortPool(min, max int) *PortPool {
 	available := make(map[int]bool, max-min)
 
-	pool := PortPool{available}
+	pool := PortPool{&sync.Mutex{}, available}
 	for i := min; i <= max; i++ {
 		pool.available[i] = true
 	}
@@ -185,6 +387,9 @@ func NewPortPool(min, max int) *PortPool {
 }
 
 func (pool PortPool) Get() (int, error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
 	var result = -1
 	for port, available := range pool.available {
 		if available {
@@ -202,6 +407,16 @@ func (pool PortPool) Get() (int, error) {
 	return result, nil
 }
 
+func (pool PortPool) Release(port int) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	_, ok := pool.available[port]
+	if ok {
+		pool.available[port] = true
+	}
+}
+
 func StartFunction(ctx context.Context, function *types.Function) error {
 	cfg := settings.FromContext(ctx)
 	if pool == nil {
@@ -214,47 +429,189 @@ func StartFunction(ctx context.Context, function *types.Function) error {
 		return errors.New(msg)
 	}
 
+	watchFunction(ctx, function)
+
+	manager := getManager()
+	manager.mutex.Lock()
+	manager.memorySizes[function.FunctionName] = function.MemorySize
+	manager.mutex.Unlock()
+
+	if cfg.Functions.ExecutorFor(function.FunctionName) == settings.ExecutorProcess {
+		err = startProcess(ctx, function, port)
+		if err != nil {
+			pool.Release(port)
+		}
+		return err
+	}
+
+	var api *RuntimeApi
+	if usesRuntimeApi(cfg, function) {
+		timeout := time.Duration(function.Timeout) * time.Second
+		api = NewRuntimeApi(function.FunctionName, *function.GetArn(ctx), timeout)
+		err = api.Listen(port)
+		if err != nil {
+			pool.Release(port)
+			return err
+		}
+	}
+
+	var container *docker.Container
+	var invokePath string
+	if function.IsImage() {
+		container, err = imageContainer(ctx, function, port)
+		invokePath = rieInvokePath
+	} else {
+		container, err = zipContainer(ctx, function, port)
+		invokePath = "/2015-03-31/functions/" + function.FunctionName + "/invocations"
+	}
+
+	if err == nil {
+		container.Output = newFunctionLogger(ctx, function).Output
+		_, err = docker.Start(ctx, *container, "")
+	}
+
+	if err != nil {
+		if api != nil {
+			api.Close(ctx)
+		}
+		pool.Release(port)
+		msg := log.Error("Unable to start Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	if api != nil {
+		getManager().AddRuntimeApi(function.FunctionName, api)
+	} else {
+		getManager().Add(function.FunctionName, port, invokePath)
+	}
+
+	return nil
+}
+
+// StopFunction stops the container or process running the named Function and releases its port.
+func StopFunction(ctx context.Context, name string) error {
+	log.Info("Stopping Function %s ...", name)
+
+	err := stopProcess(name)
+	if err != nil {
+		return err
+	}
+
+	err = docker.Stop(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	port, ok := getManager().Remove(ctx, name)
+	if ok && pool != nil {
+		pool.Release(port)
+	}
+
+	return nil
+}
+
+// RestartFunction stops the Function if it is running and starts it again.
+func RestartFunction(ctx context.Context, function *types.Function) error {
+	err := StopFunction(ctx, function.FunctionName)
+	if err != nil {
+		return err
+	}
+
+	return StartFunction(ctx, function)
+}
+
+func zipContainer(ctx context.Context, function *types.Function, port int) (*docker.Container, error) {
+	cfg := settings.FromContext(ctx)
+
+	runtime, ok := types.RuntimeByName(function.Runtime)
+	if !ok {
+		msg := log.Error("Unable to start Function %s: unsupported runtime %s", function.FunctionName, function.Runtime)
+		return nil, errors.New(msg)
+	}
+
+	err := docker.PullImageOnce(ctx, runtime.Image)
+	if err != nil {
+		msg := log.Error("Unable to pull image %s for Function %s: %v", runtime.Image, function.FunctionName, err)
+		return nil, errors.New(msg)
+	}
+
 	log.Info("Starting Function %s on port %d using handler %s", function.FunctionName, port, function.Handler)
 
 	container := docker.Container{
 		Name:    function.FunctionName,
-		Image:   "mlupin/docker-lambda:" + string(function.Runtime),
-		Command: []string{function.Handler},
+		Image:   runtime.Image,
+		Command: runtime.Command(function.Handler),
 		Mounts: []mount.Mount{
 			{
-				Source:      function.GetDestPath(ctx),
+				Source:      codePath(ctx, function),
 				Target:      "/var/task",
 				Type:        mount.TypeBind,
 				ReadOnly:    true,
 				Consistency: mount.ConsistencyDelegated,
 			},
 			{
-				Source:      function.GetLayerDestPath(ctx),
+				Source:      layerPath(ctx, function),
 				Target:      "/opt",
 				Type:        mount.TypeBind,
 				ReadOnly:    true,
 				Consistency: mount.ConsistencyDelegated,
 			},
 		},
-		Environment: []string{
-			"DOCKER_LAMBDA_STAY_OPEN=1",
-		},
+		Environment: append(buildEnvironment(ctx, function, cfg.DockerEndpoint()), "DOCKER_LAMBDA_STAY_OPEN=1"),
+		ExtraHosts:  []string{cfg.DockerHost + ":host-gateway"},
 		Ports: map[int]int{
 			9001: port,
 		},
 	}
 
-	_, err = docker.Start(ctx, container, "")
-	if err != nil {
-		msg := log.Error("Unable to start Function %s: %v", function.FunctionName, err)
-		return errors.New(msg)
+	if usesRuntimeApi(cfg, function) {
+		// bypass docker-lambda's init and let the runtime's bootstrap poll myaws directly
+		container.Entrypoint = bootstrapCommand(ctx, function, runtime)
+		container.Command = nil
+		container.Ports = nil
+		container.Environment = append(buildEnvironment(ctx, function, cfg.DockerEndpoint()),
+			"AWS_LAMBDA_RUNTIME_API="+runtimeApiAddress(cfg, port),
+			"_HANDLER="+function.Handler,
+			"LAMBDA_TASK_ROOT=/var/task",
+			"LAMBDA_RUNTIME_DIR=/var/runtime",
+		)
 	}
 
-	if manager == nil {
-		manager = &ManagerImpl{ports: make(map[string]int), eventSources: make(map[uuid.UUID]context.CancelFunc)}
+	return &container, nil
+}
+
+// bootstrapCommand returns the executable that implements the Runtime API client for a Function's runtime. Custom
+// runtimes look for a bootstrap in the Function's code before falling back to one provided by a Layer.
+func bootstrapCommand(ctx context.Context, function *types.Function, runtime types.Runtime) []string {
+	switch runtime.Family {
+	case types.FamilyGo:
+		return []string{"/var/task/" + function.Handler}
+	case types.FamilyProvided:
+		_, err := os.Stat(filepath.Join(codePath(ctx, function), "bootstrap"))
+		if err != nil {
+			return []string{"/opt/bootstrap"}
+		}
+		return []string{"/var/task/bootstrap"}
+	default:
+		return []string{runtime.Bootstrap}
 	}
+}
 
-	manager.Add(function.FunctionName, port)
+// usesRuntimeApi is true when the Function is started with a bootstrap that polls myaws. Runtimes without one are
+// still run through docker-lambda when the Runtime API is enabled.
+func usesRuntimeApi(cfg *settings.Config, function *types.Function) bool {
+	if !cfg.Functions.RuntimeApi {
+		return false
+	}
 
-	return nil
+	if function.IsImage() {
+		return true
+	}
+
+	runtime, ok := types.RuntimeByName(function.Runtime)
+	return ok && runtime.HasBootstrap()
+}
+
+func runtimeApiAddress(cfg *settings.Config, port int) string {
+	return cfg.DockerHost + ":" + strconv.Itoa(port)
 }
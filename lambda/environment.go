@@ -0,0 +1,61 @@
+package lambda
+
+import (
+	"context"
+	"myaws/lambda/types"
+	"myaws/settings"
+	"sort"
+	"strconv"
+)
+
+const (
+	dummyAccessKeyId     = "myaws"
+	dummySecretAccessKey = "myaws-secret"
+)
+
+// services that myaws answers, used to build AWS_ENDPOINT_URL_<SERVICE> variables
+var endpointServices = []string{"IAM", "LAMBDA", "S3", "SQS", "SSM", "STS"}
+
+// buildEnvironment returns the environment variables, formatted as KEY=VALUE, that are given to a Function's
+// container or process, where endpoint is the URL of myaws as seen by the Function. Defaults are applied first so
+// that the Function's own Environment can override them, while the variables Lambda reserves for itself always win.
+func buildEnvironment(ctx context.Context, function *types.Function, endpoint string) []string {
+	cfg := settings.FromContext(ctx)
+
+	variables := map[string]string{
+		"AWS_ACCESS_KEY_ID":     dummyAccessKeyId,
+		"AWS_SECRET_ACCESS_KEY": dummySecretAccessKey,
+		"AWS_ENDPOINT_URL":      endpoint,
+	}
+
+	for _, service := range endpointServices {
+		variables["AWS_ENDPOINT_URL_"+service] = endpoint
+	}
+
+	if function.Environment != nil {
+		for key, value := range function.Environment.Variables {
+			variables[key] = value
+		}
+	}
+
+	variables["AWS_REGION"] = cfg.Region
+	variables["AWS_DEFAULT_REGION"] = cfg.Region
+	variables["AWS_LAMBDA_FUNCTION_NAME"] = function.FunctionName
+	variables["AWS_LAMBDA_FUNCTION_VERSION"] = function.Version
+	variables["AWS_LAMBDA_FUNCTION_MEMORY_SIZE"] = strconv.Itoa(int(function.MemorySize))
+	variables["AWS_LAMBDA_FUNCTION_TIMEOUT"] = strconv.Itoa(int(function.Timeout))
+	variables["AWS_LAMBDA_LOG_GROUP_NAME"] = "/aws/lambda/" + function.FunctionName
+
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	results := make([]string, len(keys))
+	for i, key := range keys {
+		results[i] = key + "=" + variables[key]
+	}
+
+	return results
+}
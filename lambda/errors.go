@@ -0,0 +1,29 @@
+package lambda
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	errorTypeAccessDenied          = "AccessDeniedException"
+	errorTypeInvalidParameterValue = "InvalidParameterValueException"
+	errorTypeResourceConflict      = "ResourceConflictException"
+	errorTypeResourceNotFound      = "ResourceNotFoundException"
+)
+
+type errorBody struct {
+	Type    string
+	Message string `json:"message"`
+}
+
+// respondWithError writes an error the way Lambda does, so that SDKs can tell the type of error from the
+// X-Amzn-ErrorType header and show its message.
+func respondWithError(response http.ResponseWriter, statusCode int, errorType string, message string) {
+	header := response.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Amzn-ErrorType", errorType)
+	response.WriteHeader(statusCode)
+
+	json.NewEncoder(response).Encode(errorBody{Type: "User", Message: message})
+}
@@ -1,8 +1,10 @@
 package lambda
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/docker/distribution/uuid"
 	"io"
 	"myaws/database"
@@ -18,6 +20,91 @@ import (
 
 const PostEventSourceRegex = `^/2015-03-31/event-source-mappings/$`
 
+// defaultBatchSizes are the batch sizes of event sources that don't have one, by the service of their ARN.
+var defaultBatchSizes = map[string]int32{
+	"sqs":      10,
+	"dynamodb": 100,
+	"kinesis":  100,
+}
+
+// newEventSource returns the event source described by the payload, or why it can't be created.
+func newEventSource(payload lambda.CreateEventSourceMappingInput, function *types.Function) (types.EventSource, string, bool) {
+	eventSource := types.EventSource{
+		UUID:                  uuid.Generate(),
+		Enabled:               payload.Enabled == nil || *payload.Enabled,
+		Function:              function,
+		StartingPosition:      string(payload.StartingPosition),
+		MaximumRetryAttempts:  -1,
+		ParallelizationFactor: 1,
+		LastModified:          time.Now().UnixMilli(),
+	}
+
+	if payload.EventSourceArn == nil {
+		return eventSource, "EventSourceArn is required", false
+	}
+	eventSource.Arn = *payload.EventSourceArn
+
+	defaultBatchSize, ok := defaultBatchSizes[eventSource.Service()]
+	if !ok {
+		return eventSource, "EventSourceArn must be the ARN of a queue, a DynamoDB stream or a Kinesis stream", false
+	}
+
+	eventSource.BatchSize = defaultBatchSize
+	if payload.BatchSize != nil {
+		eventSource.BatchSize = *payload.BatchSize
+	}
+
+	if eventSource.Service() == "sqs" {
+		return eventSource, "", true
+	}
+
+	if eventSource.Service() == "dynamodb" && !strings.Contains(eventSource.Arn, "/stream/") {
+		return eventSource, "EventSourceArn must be the ARN of the stream of a table, not of the table", false
+	}
+
+	if payload.StartingPosition != aws.EventSourcePositionTrimHorizon &&
+		payload.StartingPosition != aws.EventSourcePositionLatest {
+		return eventSource, "StartingPosition must be TRIM_HORIZON or LATEST for streams", false
+	}
+
+	if eventSource.BatchSize < 1 || eventSource.BatchSize > 10000 {
+		return eventSource, "BatchSize must be between 1 and 10000", false
+	}
+
+	if payload.BisectBatchOnFunctionError != nil {
+		eventSource.BisectBatchOnFunctionError = *payload.BisectBatchOnFunctionError
+	}
+
+	if payload.MaximumRetryAttempts != nil {
+		eventSource.MaximumRetryAttempts = *payload.MaximumRetryAttempts
+		if eventSource.MaximumRetryAttempts < -1 || eventSource.MaximumRetryAttempts > 10000 {
+			return eventSource, "MaximumRetryAttempts must be between -1 and 10000", false
+		}
+	}
+
+	if payload.ParallelizationFactor != nil {
+		eventSource.ParallelizationFactor = *payload.ParallelizationFactor
+		if eventSource.ParallelizationFactor < 1 || eventSource.ParallelizationFactor > 10 {
+			return eventSource, "ParallelizationFactor must be between 1 and 10", false
+		}
+	}
+
+	if payload.TumblingWindowInSeconds != nil {
+		eventSource.TumblingWindowInSeconds = *payload.TumblingWindowInSeconds
+		if eventSource.TumblingWindowInSeconds < 0 || eventSource.TumblingWindowInSeconds > 900 {
+			return eventSource, "TumblingWindowInSeconds must be between 0 and 900", false
+		}
+	}
+
+	if eventSource.Service() == "dynamodb" &&
+		(eventSource.ParallelizationFactor > 1 || eventSource.TumblingWindowInSeconds > 0) {
+		return eventSource, "ParallelizationFactor & TumblingWindowInSeconds are only supported for Kinesis streams",
+			false
+	}
+
+	return eventSource, "", true
+}
+
 func PostEventSource(writer http.ResponseWriter, request *http.Request) {
 	var requestBodyBuilder strings.Builder
 	reader := io.TeeReader(request.Body, &requestBodyBuilder)
@@ -42,13 +129,11 @@ func PostEventSource(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	eventSource := types.EventSource{
-		UUID:         uuid.Generate(),
-		Enabled:      true,
-		Arn:          *payload.EventSourceArn,
-		Function:     function,
-		BatchSize:    *payload.BatchSize,
-		LastModified: time.Now().UnixMilli(),
+	eventSource, msg, ok := newEventSource(payload, function)
+	if !ok {
+		log.Error(msg)
+		respondWithError(writer, http.StatusBadRequest, errorTypeInvalidParameterValue, msg)
+		return
 	}
 
 	log.Info("Saving Event Source: %+v", eventSource)
@@ -60,6 +145,14 @@ func PostEventSource(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if eventSource.Enabled {
+		// the poller outlives the request, so it can't use the request's context
+		err = StartEventSource(cfg.NewContext(context.Background()), &eventSource)
+		if err != nil {
+			log.Error("Unable to start Event Source %s: %v", eventSource.UUID, err)
+		}
+	}
+
 	body := eventSource.ToCreateEventSourceMappingOutput(ctx)
 
 	utils.RespondWithJson(writer, body)
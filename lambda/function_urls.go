@@ -0,0 +1,372 @@
+package lambda
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"myaws/database"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	urlIdLength = 32
+
+	// InvocationSourceFunctionUrl is the source of invocations made through a Function URL.
+	InvocationSourceFunctionUrl = "function-url"
+)
+
+func urlQualifier(request *http.Request) string {
+	qualifier := request.URL.Query().Get("Qualifier")
+	if len(qualifier) == 0 {
+		return "$LATEST"
+	}
+
+	return qualifier
+}
+
+func decodeFunctionUrlConfig(response http.ResponseWriter, request *http.Request) (types.FunctionUrlConfigInput, bool) {
+	var body types.FunctionUrlConfigInput
+	decoder := json.NewDecoder(request.Body)
+	defer request.Body.Close()
+
+	err := decoder.Decode(&body)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameterValue, msg)
+		return body, false
+	}
+
+	if len(body.AuthType) > 0 && body.AuthType != types.FunctionUrlAuthTypeIam &&
+		body.AuthType != types.FunctionUrlAuthTypeNone {
+		msg := log.Error("AuthType %s must be one of %s or %s", body.AuthType, types.FunctionUrlAuthTypeIam,
+			types.FunctionUrlAuthTypeNone)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameterValue, msg)
+		return body, false
+	}
+
+	return body, true
+}
+
+// findFunctionUrl writes an error response & returns nil when the Function in the path has no URL.
+func findFunctionUrl(response http.ResponseWriter, request *http.Request, db *database.Database) *types.FunctionUrl {
+	name := getFunctionName(request.URL.Path)
+	url, err := queries.FunctionUrlByName(request.Context(), db, name, urlQualifier(request))
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("The resource you requested does not exist. Function %s has no URL", name)
+		respondWithError(response, http.StatusNotFound, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	return url
+}
+
+const FunctionUrlConfigRegex = `^/2021-10-31/functions/[A-Za-z0-9_-]+/url$`
+
+func CreateFunctionUrlConfig(response http.ResponseWriter, request *http.Request) {
+	name := getFunctionName(request.URL.Path)
+	body, ok := decodeFunctionUrlConfig(response, request)
+	if !ok {
+		return
+	}
+
+	if len(body.AuthType) == 0 {
+		msg := log.Error("AuthType is required to create a URL for Function %s", name)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameterValue, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	_, err := queries.LatestFunctionByName(ctx, db, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Function not found: %s", name)
+		respondWithError(response, http.StatusNotFound, errorTypeResourceNotFound, msg)
+		return
+	case err != nil:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	qualifier := urlQualifier(request)
+	_, err = queries.FunctionUrlByName(ctx, db, name, qualifier)
+	switch {
+	case err == nil:
+		msg := log.Error("Failed to create function url config for [functionArn = %s]. Error message:  "+
+			"FunctionUrlConfig exists for this Lambda function", name)
+		respondWithError(response, http.StatusConflict, errorTypeResourceConflict, msg)
+		return
+	case err != sql.ErrNoRows:
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	url := types.FunctionUrl{
+		UrlId:            utils.RandomId(urlIdLength),
+		FunctionName:     name,
+		Qualifier:        qualifier,
+		AuthType:         body.AuthType,
+		Cors:             body.Cors,
+		CreationTime:     now,
+		LastModifiedTime: now,
+	}
+
+	err = queries.InsertFunctionUrl(ctx, db, &url)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("Function %s can be invoked at %s", name, url.GetUrl(ctx))
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(url.ToFunctionUrlConfig(ctx))
+}
+
+func GetFunctionUrlConfig(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	url := findFunctionUrl(response, request, db)
+	if url == nil {
+		return
+	}
+
+	utils.RespondWithJson(response, url.ToFunctionUrlConfig(ctx))
+}
+
+func UpdateFunctionUrlConfig(response http.ResponseWriter, request *http.Request) {
+	body, ok := decodeFunctionUrlConfig(response, request)
+	if !ok {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	url := findFunctionUrl(response, request, db)
+	if url == nil {
+		return
+	}
+
+	if len(body.AuthType) > 0 {
+		url.AuthType = body.AuthType
+	}
+
+	if body.Cors != nil {
+		url.Cors = body.Cors
+	}
+
+	url.LastModifiedTime = time.Now().UnixMilli()
+	err := queries.UpdateFunctionUrl(ctx, db, url)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, url.ToFunctionUrlConfig(ctx))
+}
+
+func DeleteFunctionUrlConfig(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	url := findFunctionUrl(response, request, db)
+	if url == nil {
+		return
+	}
+
+	err := queries.DeleteFunctionUrl(ctx, db, url)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+const ListFunctionUrlConfigsRegex = `^/2021-10-31/functions/[A-Za-z0-9_-]+/urls$`
+
+func ListFunctionUrlConfigs(response http.ResponseWriter, request *http.Request) {
+	name := getFunctionName(request.URL.Path)
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	urls, err := queries.FunctionUrlsByName(ctx, db, name)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	configs := make([]types.FunctionUrlConfig, 0, len(urls))
+	for _, url := range urls {
+		configs = append(configs, url.ToFunctionUrlConfig(ctx))
+	}
+
+	utils.RespondWithJson(response, map[string]interface{}{"FunctionUrlConfigs": configs})
+}
+
+// FunctionUrlHostRegex matches the hostname of Function URLs, capturing the URL's ID.
+const FunctionUrlHostRegex = `^([a-z0-9]+)\.lambda-url\.[a-z0-9-]+\.localhost$`
+
+// FunctionUrlPathRegex is an alternative to the hostname of Function URLs for clients that can't resolve subdomains
+// of localhost, such as Functions running in containers: /_myaws/lambda-url/<url id>/<path>.
+const FunctionUrlPathRegex = `^/_myaws/lambda-url/[a-z0-9]+(/.*)?$`
+
+// InvokeFunctionUrlByHost invokes the Function whose URL has the hostname of the request.
+func InvokeFunctionUrlByHost(response http.ResponseWriter, request *http.Request) {
+	host := request.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	urlId := strings.SplitN(host, ".", 2)[0]
+	invokeFunctionUrl(response, request, urlId, request.URL.Path)
+}
+
+// InvokeFunctionUrlByPath invokes the Function whose URL has the ID in the path of the request.
+func InvokeFunctionUrlByPath(response http.ResponseWriter, request *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(request.URL.Path, "/_myaws/lambda-url/"), "/", 2)
+	path := "/"
+	if len(parts) == 2 {
+		path += parts[1]
+	}
+
+	invokeFunctionUrl(response, request, parts[0], path)
+}
+
+func invokeFunctionUrl(response http.ResponseWriter, request *http.Request, urlId string, path string) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	url, err := queries.FunctionUrlById(ctx, db, urlId)
+	db.Close()
+
+	switch {
+	case err == sql.ErrNoRows:
+		log.Error("No Function URL has the id %s", urlId)
+		respondWithMessage(response, http.StatusNotFound, "Not Found")
+		return
+	case err != nil:
+		log.Error("Unable to find Function URL %s: %v", urlId, err)
+		respondWithMessage(response, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	cors := url.Cors
+	origin := request.Header.Get("Origin")
+	if cors != nil && request.Method == http.MethodOptions && len(request.Header.Get("Access-Control-Request-Method")) > 0 {
+		respondToPreflight(response, request, cors)
+		return
+	}
+
+	if url.AuthType == types.FunctionUrlAuthTypeIam && len(request.Header.Get("Authorization")) == 0 {
+		log.Error("Request to Function URL of %s isn't signed", url.FunctionName)
+		respondWithMessage(response, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		log.Error("Unable to read body of request to Function URL of %s: %v", url.FunctionName, err)
+		respondWithMessage(response, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	now := time.Now()
+	event := NewHttpEvent(ctx, request, body, url.UrlId, path, strconv.FormatInt(now.UnixNano(), 36), now)
+	payload, _ := json.Marshal(event)
+	invokeCtx := WithQualifier(WithInvocationSource(ctx, InvocationSourceFunctionUrl), url.Qualifier)
+	result, err := getManager().InvokeFunction(invokeCtx, url.FunctionName, payload)
+
+	if err != nil || len(result.FunctionError) > 0 {
+		respondWithMessage(response, http.StatusBadGateway, "Internal Server Error")
+		return
+	}
+
+	if cors != nil && len(origin) > 0 && cors.AllowsOrigin(origin) {
+		header := response.Header()
+		header.Set("Access-Control-Allow-Origin", allowedOrigin(cors, origin))
+		if cors.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if len(cors.ExposeHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(cors.ExposeHeaders, ","))
+		}
+		header.Add("Vary", "Origin")
+	}
+
+	err = WriteHttpResponse(response, result.Payload)
+	if err != nil {
+		respondWithMessage(response, http.StatusBadGateway, "Internal Server Error")
+	}
+}
+
+func allowedOrigin(cors *types.Cors, origin string) string {
+	for _, allowed := range cors.AllowOrigins {
+		if allowed == "*" && !cors.AllowCredentials {
+			return "*"
+		}
+	}
+
+	return origin
+}
+
+func respondToPreflight(response http.ResponseWriter, request *http.Request, cors *types.Cors) {
+	origin := request.Header.Get("Origin")
+	method := request.Header.Get("Access-Control-Request-Method")
+	if !cors.AllowsOrigin(origin) || !cors.AllowsMethod(method) {
+		log.Info("Preflight request from %s for %s is not allowed", origin, method)
+		response.WriteHeader(http.StatusOK)
+		return
+	}
+
+	header := response.Header()
+	header.Set("Access-Control-Allow-Origin", allowedOrigin(cors, origin))
+	header.Set("Access-Control-Allow-Methods", strings.Join(cors.AllowMethods, ","))
+	if len(cors.AllowHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(cors.AllowHeaders, ","))
+	}
+	if cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge)))
+	}
+	header.Add("Vary", "Origin")
+
+	response.WriteHeader(http.StatusOK)
+}
+
+func respondWithMessage(response http.ResponseWriter, statusCode int, message string) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(map[string]string{"Message": message})
+}
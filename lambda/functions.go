@@ -1,10 +1,12 @@
 package lambda
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
@@ -44,65 +46,121 @@ func PostLambdaFunction(response http.ResponseWriter, request *http.Request) {
 	db := database.CreateConnection(cfg)
 	defer db.Close()
 
-	runtimeExists, err := queries.RuntimeExistsByName(ctx, db, body.Runtime)
-	if err != nil {
-		msg := log.Error("Error when querying runtime %s for function %s", body.Runtime, body.FunctionName)
-		http.Error(response, msg, http.StatusInternalServerError)
-		return
+	if body.PackageType == aws.PackageTypeImage {
+		if code == nil || code.ImageUri == nil {
+			msg := log.Error("Function %s has PackageType Image but no ImageUri", *body.FunctionName)
+			http.Error(response, msg, http.StatusBadRequest)
+			return
+		}
+
+		if len(body.Layers) > 0 {
+			msg := log.Error("Layers are not supported for container image Function %s", *body.FunctionName)
+			http.Error(response, msg, http.StatusBadRequest)
+			return
+		}
+	} else {
+		runtimeExists, err := queries.RuntimeExistsByName(ctx, db, body.Runtime)
+		if err != nil {
+			msg := log.Error("Error when querying runtime %s for function %s", body.Runtime, *body.FunctionName)
+			http.Error(response, msg, http.StatusInternalServerError)
+			return
+		}
+
+		if !runtimeExists {
+			msg := log.Error("Unable to find runtime %s for function %s", body.Runtime, *body.FunctionName)
+			http.Error(response, msg, http.StatusNotFound)
+			return
+		}
+
+		runtime, _ := types.RuntimeByName(body.Runtime)
+		if runtime.HandlerRequired && len(utils.StringOrEmpty(body.Handler)) == 0 {
+			msg := log.Error("Runtime %s requires a handler for function %s", body.Runtime, *body.FunctionName)
+			http.Error(response, msg, http.StatusBadRequest)
+			return
+		}
 	}
 
-	if !runtimeExists {
-		msg := log.Error("Unable to find runtime %s for function %s", body.Runtime, body.FunctionName)
-		http.Error(response, msg, http.StatusNotFound)
-		return
+	var layers []types.LambdaLayer
+	if body.PackageType != aws.PackageTypeImage {
+		codeSize, err := utils.UncompressedZipSize(code.ZipFile)
+		if err != nil {
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameterValue,
+				"Could not unzip uploaded file. Please check your file, then try to upload again.")
+			return
+		}
+
+		layers, err = validateLayers(ctx, db, body.Runtime, types.ArchitectureOf(body.Architectures), body.Layers, codeSize)
+		if err != nil {
+			respondWithValidationError(response, *body.FunctionName, err)
+			return
+		}
 	}
 
 	dbVersion, err := queries.LatestFunctionVersionByName(ctx, db, body.FunctionName)
 	if err != nil {
-		msg := log.Error("Error when finding latest version of function %s", body.FunctionName)
+		msg := log.Error("Error when finding latest version of function %s", *body.FunctionName)
 		http.Error(response, msg, http.StatusInternalServerError)
 		return
 	}
 
 	function := types.CreateFunction(&body)
 	function.Version = strconv.Itoa(dbVersion + 1)
-	rawHash := sha256.Sum256(code.ZipFile)
-	function.CodeSha256 = base64.StdEncoding.EncodeToString(rawHash[:])
-
-	// TODO : validate Layer runtime support
+	function.Layers = layers
+	markCreating(function)
 
-	err = utils.UncompressZipFileBytes(code.ZipFile, function.GetDestPath(ctx))
+	denied, err := deniedLayer(ctx, db, function.Layers)
 	if err != nil {
-		msg := fmt.Sprintf("error when saving function %s: %v", *body.FunctionName, err)
-		log.Error(msg)
+		msg := log.Error("Unable to check Layer permissions for function %s: %v", function.FunctionName, err)
 		http.Error(response, msg, http.StatusInternalServerError)
 		return
 	}
 
-	layerDestPath := function.GetLayerDestPath(ctx)
-	err = utils.CreateDirs(layerDestPath)
-	if err != nil {
-		msg := log.Error("Unable to create Layer path for Function %s: %v", function.FunctionName, err)
-		http.Error(response, msg, http.StatusInternalServerError)
+	if len(denied) > 0 {
+		msg := log.Error("User is not authorized to perform: lambda:GetLayerVersion on resource: %s", denied)
+		respondWithError(response, http.StatusForbidden, errorTypeAccessDenied, msg)
 		return
 	}
 
-	for _, layer := range function.Layers {
-		layerPath := layer.GetDestPath(ctx)
-		err = utils.UncompressZipFile(layerPath, layerDestPath)
+	if function.IsImage() {
+		function.ImageUri = *code.ImageUri
+		rawHash := sha256.Sum256([]byte(function.ImageUri))
+		function.CodeSha256 = base64.StdEncoding.EncodeToString(rawHash[:])
+	} else {
+		err = saveFunctionCode(ctx, function, code.ZipFile)
 		if err != nil {
-			msg := log.Error("error when unpacking layer %s: %v", layer.Name, err)
-			http.Error(response, msg, http.StatusInternalServerError)
+			http.Error(response, err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 
 	saved, err := queries.InsertFunction(ctx, db, function)
+	if err != nil {
+		msg := log.Error("Unable to save Function %s: %v", function.FunctionName, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	go ActivateFunction(detachedContext(ctx), saved)
+
 	result := saved.ToCreateFunctionOutput(ctx)
 
 	utils.RespondWithJson(response, result)
 }
 
+func saveFunctionCode(ctx context.Context, function *types.Function, zipFile []byte) error {
+	function.CodeSize = int64(len(zipFile))
+	rawHash := sha256.Sum256(zipFile)
+	function.CodeSha256 = base64.StdEncoding.EncodeToString(rawHash[:])
+
+	err := utils.UncompressZipFileBytes(zipFile, function.GetDestPath(ctx))
+	if err != nil {
+		msg := log.Error("error when saving function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return assembleLayers(ctx, function)
+}
+
 func getFunctionName(path string) string {
 	parts := strings.Split(path, "/")
 	return parts[3]
@@ -146,6 +204,32 @@ func PutLambdaConfiguration(response http.ResponseWriter, request *http.Request)
 		return
 	}
 
+	if function.LastUpdateStatus == aws.LastUpdateStatusInProgress {
+		msg := "The operation cannot be performed at this time. An update is in progress for resource: " +
+			*function.GetArn(ctx)
+		log.Error(msg)
+		respondWithError(response, http.StatusConflict, errorTypeResourceConflict, msg)
+		return
+	}
+
+	// the latest Function is reported as $LATEST, but is saved under (and runs from) its numbered version
+	dbVersion, err := queries.LatestFunctionVersionByName(ctx, db, &function.FunctionName)
+	if err != nil {
+		msg := log.Error("Error when finding latest version of Function %s: %v", name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	// the Layers are validated before anything is saved, so that a rejected update changes nothing
+	var layers []types.LambdaLayer
+	if body.Layers != nil {
+		layers, err = checkFunctionLayers(ctx, db, function, strconv.Itoa(dbVersion), body.Layers)
+		if err != nil {
+			respondWithValidationError(response, name, err)
+			return
+		}
+	}
+
 	if body.Environment != nil {
 		err = queries.UpsertFunctionEnvironment(ctx, db, function, body.Environment)
 		if err != nil {
@@ -155,10 +239,84 @@ func PutLambdaConfiguration(response http.ResponseWriter, request *http.Request)
 		}
 	}
 
+	if body.Layers != nil {
+		err = updateFunctionLayers(ctx, db, function, strconv.Itoa(dbVersion), layers)
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		function.Layers, err = queries.GetLayersForFunction(ctx, db, function)
+		if err != nil {
+			msg := log.Error("Unable to load Layers for Function %s: %v", name, err)
+			http.Error(response, msg, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	markUpdating(function)
+	err = queries.UpdateFunctionState(ctx, db, function)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	running := *function
+	running.Version = strconv.Itoa(dbVersion)
+	go ActivateFunction(detachedContext(ctx), &running)
+
 	result := function.ToUpdateFunctionConfigurationOutput(ctx)
 	utils.RespondWithJson(response, result)
 }
 
+// checkFunctionLayers returns the Layers with the ARNs when the latest version of the Function, saved under the
+// version, can use them.
+func checkFunctionLayers(ctx context.Context, db *database.Database, function *types.Function, version string, arns []string) ([]types.LambdaLayer, error) {
+	if function.IsImage() && len(arns) > 0 {
+		return nil, invalidParameter("Layers are not supported for container image functions.")
+	}
+
+	saved := *function
+	saved.Version = version
+
+	codeSize, err := utils.DirSize(saved.GetDestPath(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := validateLayers(ctx, db, function.Runtime, function.Architecture, arns, codeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	denied, err := deniedLayer(ctx, db, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(denied) > 0 {
+		return nil, accessDenied("User is not authorized to perform: lambda:GetLayerVersion on resource: %s", denied)
+	}
+
+	return layers, nil
+}
+
+// updateFunctionLayers saves the checked Layers of the latest version of the Function, then replaces its assembled
+// Layers.
+func updateFunctionLayers(ctx context.Context, db *database.Database, function *types.Function, version string, layers []types.LambdaLayer) error {
+	err := queries.UpdateFunctionLayers(ctx, db, function, layers)
+	if err != nil {
+		return err
+	}
+
+	saved := *function
+	saved.Version = version
+	saved.Layers = layers
+	function.Layers = layers
+
+	return assembleLayers(ctx, &saved)
+}
+
 const GetLambdaFunctionRegex = `^/2015-03-31/functions/[A-Za-z0-9_-]+$`
 
 func GetLambdaFunction(response http.ResponseWriter, request *http.Request) {
@@ -236,5 +394,52 @@ const InvokeFunctionRegex = `/2015-03-31/functions/[0-9A-Za-z_-]+/invocations`
 
 func InvokeFunction(response http.ResponseWriter, request *http.Request) {
 	name := getFunctionName(request.URL.Path)
-	manager.Invoke(name, &response, request)
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	function, err := queries.LatestFunctionByName(ctx, db, name)
+
+	qualifier := request.URL.Query().Get("Qualifier")
+	exists := true
+	if err == nil && len(qualifier) > 0 && qualifier != latestVersion {
+		exists, err = functionVersionExists(ctx, db, name, qualifier)
+	}
+	db.Close()
+
+	switch {
+	case err == sql.ErrNoRows || !exists:
+		msg := log.Error("Function not found: %s", name)
+		respondWithError(response, http.StatusNotFound, errorTypeResourceNotFound, msg)
+		return
+	case err != nil:
+		msg := log.Error("Error when querying for Function %s: %v", name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	if function.State != aws.StateActive {
+		msg := "The operation cannot be performed at this time. The function is currently in the following state: " +
+			string(function.State)
+		log.Error("Unable to invoke Function %s: %s", name, msg)
+		respondWithError(response, http.StatusConflict, errorTypeResourceConflict, msg)
+		return
+	}
+
+	getManager().Invoke(name, &response, request)
+}
+
+func functionVersionExists(ctx context.Context, db *database.Database, name string, version string) (bool, error) {
+	versions, err := queries.FunctionVersionsByName(ctx, db, name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, function := range versions {
+		if function.Version == version {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
@@ -0,0 +1,156 @@
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// HttpEventVersion is the payload format version of HttpEvents.
+	HttpEventVersion = "2.0"
+
+	// HttpEventDefault is the route key & stage of HttpEvents that aren't routed, such as those sent by Function URLs.
+	HttpEventDefault = "$default"
+
+	httpEventTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// NewHttpEvent returns the HttpEvent for the request to the path of the API or Function URL with the ID. The event
+// has the $default route & stage, which callers that route requests replace.
+func NewHttpEvent(ctx context.Context, request *http.Request, body []byte, apiId string, path string,
+	requestId string, now time.Time) types.HttpEvent {
+	cfg := settings.FromContext(ctx)
+
+	headers := make(map[string]string, len(request.Header))
+	for key, values := range request.Header {
+		if strings.EqualFold(key, "Cookie") {
+			continue
+		}
+		headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+
+	var cookies []string
+	for _, cookie := range request.Cookies() {
+		cookies = append(cookies, cookie.String())
+	}
+
+	var parameters map[string]string
+	if query := request.URL.Query(); len(query) > 0 {
+		parameters = make(map[string]string, len(query))
+		for key, values := range query {
+			parameters[key] = strings.Join(values, ",")
+		}
+	}
+
+	event := types.HttpEvent{
+		Version:               HttpEventVersion,
+		RouteKey:              HttpEventDefault,
+		RawPath:               path,
+		RawQueryString:        request.URL.RawQuery,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: parameters,
+		RequestContext: types.HttpRequestContext{
+			AccountId:    cfg.AccountNumber,
+			ApiId:        apiId,
+			DomainName:   request.Host,
+			DomainPrefix: apiId,
+			Http: types.HttpDescription{
+				Method:    request.Method,
+				Path:      path,
+				Protocol:  request.Proto,
+				SourceIp:  SourceIp(request),
+				UserAgent: request.UserAgent(),
+			},
+			RequestId: requestId,
+			RouteKey:  HttpEventDefault,
+			Stage:     HttpEventDefault,
+			Time:      now.Format(httpEventTimeFormat),
+			TimeEpoch: now.UnixMilli(),
+		},
+	}
+
+	if len(body) > 0 {
+		if IsTextContent(request.Header.Get("Content-Type")) {
+			event.Body = string(body)
+		} else {
+			event.Body = base64.StdEncoding.EncodeToString(body)
+			event.IsBase64Encoded = true
+		}
+	}
+
+	return event
+}
+
+// SourceIp returns the IP address the request was sent from.
+func SourceIp(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
+
+// IsTextContent reports whether bodies of the content type are passed to Functions as is, rather than base64 encoded.
+func IsTextContent(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return len(contentType) == 0 || strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") || strings.Contains(contentType, "xml") ||
+		strings.Contains(contentType, "javascript") || strings.Contains(contentType, "x-www-form-urlencoded")
+}
+
+// WriteHttpResponse maps what the Function returned for an HttpEvent back to HTTP. A JSON object with a statusCode is
+// a full response, while anything else is the body of a 200 response. CORS headers already set by the caller take
+// precedence over the Function's. Nothing is written when the response is malformed, so that callers can respond
+// with their own error.
+func WriteHttpResponse(response http.ResponseWriter, payload []byte) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(payload, &fields)
+	if _, ok := fields["statusCode"]; err != nil || !ok {
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(http.StatusOK)
+		response.Write(payload)
+		return nil
+	}
+
+	var result types.HttpResponse
+	err = json.Unmarshal(payload, &result)
+	if err != nil {
+		msg := log.Error("Malformed Lambda response: %s", payload)
+		return errors.New(msg)
+	}
+
+	body := []byte(result.Body)
+	if result.IsBase64Encoded {
+		body, err = base64.StdEncoding.DecodeString(result.Body)
+		if err != nil {
+			msg := log.Error("Unable to decode body returned by Function: %v", err)
+			return errors.New(msg)
+		}
+	}
+
+	header := response.Header()
+	for key, value := range result.Headers {
+		if len(header.Get(key)) == 0 || !strings.HasPrefix(strings.ToLower(key), "access-control-") {
+			header.Set(key, value)
+		}
+	}
+
+	for _, cookie := range result.Cookies {
+		header.Add("Set-Cookie", cookie)
+	}
+
+	response.WriteHeader(result.StatusCode)
+	response.Write(body)
+	return nil
+}
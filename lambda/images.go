@@ -0,0 +1,91 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"github.com/docker/docker/api/types/mount"
+	"myaws/docker"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+)
+
+const (
+	riePort       = 8080
+	rieTarget     = "/aws-lambda/aws-lambda-rie"
+	rieInvokePath = "/2015-03-31/functions/function/invocations"
+)
+
+// imageContainer builds the container for a Function with PackageType Image. The Function is invoked through the
+// Lambda Runtime Interface Emulator, either the one shipped in AWS base images or the one configured in settings.
+func imageContainer(ctx context.Context, function *types.Function, port int) (*docker.Container, error) {
+	cfg := settings.FromContext(ctx)
+
+	imageConfig, err := docker.InspectImage(ctx, function.ImageUri)
+	if err != nil {
+		log.Info("Image %s for Function %s is not available locally, pulling ...", function.ImageUri, function.FunctionName)
+		err = docker.PullImageOnce(ctx, function.ImageUri)
+		if err != nil {
+			msg := log.Error("Unable to pull image %s for Function %s: %v", function.ImageUri, function.FunctionName, err)
+			return nil, errors.New(msg)
+		}
+
+		imageConfig, err = docker.InspectImage(ctx, function.ImageUri)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entrypoint := []string(imageConfig.Entrypoint)
+	command := []string(imageConfig.Cmd)
+	workingDir := imageConfig.WorkingDir
+	if function.ImageConfig != nil {
+		if function.ImageConfig.EntryPoint != nil {
+			entrypoint = function.ImageConfig.EntryPoint
+		}
+
+		if function.ImageConfig.Command != nil {
+			command = function.ImageConfig.Command
+		}
+
+		if function.ImageConfig.WorkingDirectory != nil {
+			workingDir = utils.StringOrEmpty(function.ImageConfig.WorkingDirectory)
+		}
+	}
+
+	environment := buildEnvironment(ctx, function, cfg.DockerEndpoint())
+	ports := map[int]int{
+		riePort: port,
+	}
+
+	var mounts []mount.Mount
+	if usesRuntimeApi(cfg, function) {
+		// the image's runtime interface client polls myaws directly, so the emulator isn't needed
+		environment = append(environment, "AWS_LAMBDA_RUNTIME_API="+runtimeApiAddress(cfg, port))
+		ports = nil
+	} else if len(cfg.Functions.RiePath) > 0 {
+		entrypoint = append([]string{rieTarget}, entrypoint...)
+		mounts = append(mounts, mount.Mount{
+			Source:   cfg.Functions.RiePath,
+			Target:   rieTarget,
+			Type:     mount.TypeBind,
+			ReadOnly: true,
+		})
+	}
+
+	log.Info("Starting container image Function %s on port %d using image %s", function.FunctionName, port,
+		function.ImageUri)
+
+	return &docker.Container{
+		Name:        function.FunctionName,
+		Image:       function.ImageUri,
+		Entrypoint:  entrypoint,
+		Command:     command,
+		WorkingDir:  workingDir,
+		Mounts:      mounts,
+		Environment: environment,
+		ExtraHosts:  []string{cfg.DockerHost + ":host-gateway"},
+		Ports:       ports,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type invocationContextKey string
+
+const (
+	sourceContextKey    = invocationContextKey("source")
+	qualifierContextKey = invocationContextKey("qualifier")
+
+	latestVersion = "$LATEST"
+
+	// InvocationSourceApi is the source of invocations made through the Lambda Invoke API.
+	InvocationSourceApi = "api"
+)
+
+// WithInvocationSource records what is invoking Functions with the returned context, such as an event source's UUID,
+// so that it is saved with the invocations.
+func WithInvocationSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey, source)
+}
+
+func invocationSource(ctx context.Context) string {
+	source, ok := ctx.Value(sourceContextKey).(string)
+	if !ok {
+		return InvocationSourceApi
+	}
+
+	return source
+}
+
+// WithQualifier invokes the version of Functions named by the qualifier with the returned context, rather than
+// $LATEST.
+func WithQualifier(ctx context.Context, qualifier string) context.Context {
+	return context.WithValue(ctx, qualifierContextKey, qualifier)
+}
+
+func invocationQualifier(ctx context.Context) string {
+	qualifier, _ := ctx.Value(qualifierContextKey).(string)
+	if len(qualifier) == 0 {
+		return latestVersion
+	}
+
+	return qualifier
+}
+
+// executedVersion returns the version of the Function that an invocation with ctx runs. Only the latest version of
+// a Function is ever running, so earlier versions can't be invoked.
+func executedVersion(ctx context.Context, name string) (string, error) {
+	qualifier := invocationQualifier(ctx)
+	if qualifier == latestVersion {
+		return qualifier, nil
+	}
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	latest, err := queries.LatestFunctionVersionByName(ctx, db, &name)
+	if err != nil {
+		return "", err
+	}
+
+	if qualifier != strconv.Itoa(latest) {
+		msg := log.Error("Unable to invoke version %s of Function %s, as only its latest version %d is run", qualifier,
+			name, latest)
+		return "", errors.New(msg)
+	}
+
+	return qualifier, nil
+}
+
+// errorType returns the type of error that the Function reported in its response, like Lambda's errorType field.
+func errorType(result *InvokeResult) string {
+	var payload struct {
+		ErrorType string `json:"errorType"`
+	}
+
+	err := json.Unmarshal(result.Payload, &payload)
+	if err != nil || len(payload.ErrorType) == 0 {
+		return result.FunctionError
+	}
+
+	return payload.ErrorType
+}
+
+func recordInvocation(ctx context.Context, invocation types.Invocation, result *InvokeResult, err error) {
+	invocation.Source = invocationSource(ctx)
+	if result != nil {
+		invocation.Response = string(result.Payload)
+		invocation.StatusCode = result.StatusCode
+		invocation.FunctionError = result.FunctionError
+		if len(result.FunctionError) > 0 {
+			invocation.ErrorType = errorType(result)
+		}
+	}
+
+	if err != nil {
+		invocation.Error = err.Error()
+	}
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	// the invocation itself already happened, so failing to record it is only logged
+	queries.InsertInvocation(ctx, db, invocation)
+}
+
+func invocationFilter(request *http.Request) (types.InvocationFilter, error) {
+	query := request.URL.Query()
+	filter := types.InvocationFilter{
+		FunctionName: query.Get("FunctionName"),
+		Source:       query.Get("Source"),
+		RequestId:    query.Get("RequestId"),
+		Payload:      query.Get("Payload"),
+	}
+
+	if value := query.Get("Since"); len(value) > 0 {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since.UnixMilli()
+	}
+
+	if value := query.Get("Limit"); len(value) > 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+const InvocationsRegex = `^/_myaws/lambda/invocations$`
+
+// GetInvocations lists the recorded invocations of Functions newest first, filtered by the FunctionName, Source,
+// RequestId, Payload (a substring of the payload), Since (an RFC 3339 time) and Limit query parameters.
+func GetInvocations(response http.ResponseWriter, request *http.Request) {
+	filter, err := invocationFilter(request)
+	if err != nil {
+		msg := log.Error("Invalid filter for invocations: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	invocations, err := queries.Invocations(ctx, db, filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.RespondWithJson(response, map[string][]types.Invocation{"Invocations": invocations})
+}
+
+// DeleteInvocations forgets recorded invocations of Functions, using the same filters as GetInvocations.
+func DeleteInvocations(response http.ResponseWriter, request *http.Request) {
+	filter, err := invocationFilter(request)
+	if err != nil {
+		msg := log.Error("Invalid filter for invocations: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	err = queries.DeleteInvocations(ctx, db, filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,273 @@
+package lambda
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"myaws/database"
+	kinesisQueries "myaws/kinesis/queries"
+	kinesisTypes "myaws/kinesis/types"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"strings"
+	"sync"
+	"time"
+)
+
+const kinesisPollInterval = time.Second
+
+// kinesisPoller delivers the records of a shard of a Kinesis stream to the Function of an event source. With a
+// ParallelizationFactor above 1, the records of each poll are split in that many batches by partition key, which are
+// delivered concurrently while keeping the records of a partition key in order.
+type kinesisPoller struct {
+	eventSource *types.EventSource
+	streamName  string
+	shardId     string
+	position    int64
+
+	// windows are the current tumbling windows of the concurrent batches, which are nil between windows
+	windows []*tumblingWindow
+}
+
+type tumblingWindow struct {
+	start time.Time
+	state json.RawMessage
+}
+
+// startKinesisPoller starts polling each shard of the stream after its checkpoint, or from StartingPosition when the
+// event source has yet to process records of the shard.
+func startKinesisPoller(ctx context.Context, eventSource *types.EventSource) error {
+	streamName := eventSource.Arn[strings.LastIndex(eventSource.Arn, "/")+1:]
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream, err := kinesisQueries.StreamByName(ctx, db, streamName)
+	if err == sql.ErrNoRows {
+		msg := log.Error("Stream %s of Event Source %s does not exist", streamName, eventSource.UUID)
+		return errors.New(msg)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	checkpoints, err := queries.Checkpoints(ctx, db, eventSource.UUID.String())
+	if err != nil {
+		return err
+	}
+
+	log.Info("Starting consumption from %d shards of Stream %s ...", stream.ShardCount, streamName)
+
+	for _, shard := range stream.Shards() {
+		poller := &kinesisPoller{
+			eventSource: eventSource,
+			streamName:  streamName,
+			shardId:     shard.ShardId,
+			windows:     make([]*tumblingWindow, eventSource.ParallelizationFactor),
+		}
+
+		if sequenceNumber, ok := checkpoints[shard.ShardId]; ok {
+			poller.position, err = kinesisTypes.ParsePosition(sequenceNumber)
+		} else if eventSource.StartingPosition == kinesisTypes.ShardIteratorTypeLatest {
+			poller.position, err = kinesisQueries.LatestPosition(ctx, db)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		go poller.run(ctx)
+	}
+
+	return nil
+}
+
+func (poller *kinesisPoller) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if poller.poll(ctx) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(kinesisPollInterval):
+		}
+	}
+}
+
+// poll delivers the records put into the shard since the last poll & checkpoints the last of them, returning whether
+// more records may be waiting.
+func (poller *kinesisPoller) poll(ctx context.Context) bool {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream, err := kinesisQueries.StreamByName(ctx, db, poller.streamName)
+	if err != nil {
+		return false
+	}
+
+	factor := len(poller.windows)
+	batchSize := int(poller.eventSource.BatchSize)
+	iterator := kinesisTypes.ShardIterator{StreamName: poller.streamName, ShardId: poller.shardId, Position: poller.position}
+	records, err := kinesisQueries.Records(ctx, db, iterator, stream.RetentionStart(), batchSize*factor)
+	if err != nil {
+		return false
+	}
+
+	// records go to batches by the hash of their partition key, up to the first record whose batch is full, which is
+	// left for the next poll
+	batches := make([][]kinesisTypes.Record, factor)
+	count := 0
+	for _, record := range records {
+		hash := fnv.New32a()
+		hash.Write([]byte(record.PartitionKey))
+		i := int(hash.Sum32() % uint32(factor))
+		if len(batches[i]) == batchSize {
+			break
+		}
+
+		batches[i] = append(batches[i], record)
+		count++
+	}
+
+	var waitGroup sync.WaitGroup
+	for i := range batches {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			poller.deliver(ctx, i, batches[i])
+		}(i)
+	}
+	waitGroup.Wait()
+
+	if count == 0 || ctx.Err() != nil {
+		return false
+	}
+
+	last := records[count-1]
+	poller.position = last.ID
+	queries.SaveCheckpoint(ctx, db, poller.eventSource.UUID.String(), poller.shardId, last.SequenceNumber())
+
+	return len(records) == batchSize*factor
+}
+
+// deliver invokes the Function with the batch of records, with retries & bisecting of failing batches. With tumbling
+// windows, the records are delivered along with the state of their window, which is closed by a final invocation
+// once it has ended.
+func (poller *kinesisPoller) deliver(ctx context.Context, batch int, records []kinesisTypes.Record) {
+	eventSource := poller.eventSource
+	if eventSource.TumblingWindowInSeconds == 0 {
+		if len(records) > 0 {
+			deliverBatch(ctx, eventSource, len(records), func(start int, end int) error {
+				event := types.KinesisEvent{Records: poller.eventRecords(ctx, records[start:end])}
+				_, err := invokeForEventSource(ctx, eventSource, event, end-start)
+				return err
+			})
+		}
+		return
+	}
+
+	size := time.Duration(eventSource.TumblingWindowInSeconds) * time.Second
+	for len(records) > 0 {
+		start := time.UnixMilli(records[0].ArrivedOn).Truncate(size)
+		count := 1
+		for count < len(records) && time.UnixMilli(records[count].ArrivedOn).Truncate(size).Equal(start) {
+			count++
+		}
+
+		poller.deliverToWindow(ctx, batch, start, records[:count])
+		records = records[count:]
+	}
+
+	window := poller.windows[batch]
+	if window != nil && !time.Now().Before(window.start.Add(size)) {
+		poller.closeWindow(ctx, batch)
+	}
+}
+
+func (poller *kinesisPoller) deliverToWindow(ctx context.Context, batch int, start time.Time, records []kinesisTypes.Record) {
+	window := poller.windows[batch]
+	if window != nil && !window.start.Equal(start) {
+		poller.closeWindow(ctx, batch)
+		window = nil
+	}
+
+	if window == nil {
+		window = &tumblingWindow{start: start, state: json.RawMessage(`{}`)}
+		poller.windows[batch] = window
+	}
+
+	deliverBatch(ctx, poller.eventSource, len(records), func(start int, end int) error {
+		event := poller.windowEvent(window, poller.eventRecords(ctx, records[start:end]), false)
+		result, err := invokeForEventSource(ctx, poller.eventSource, event, end-start)
+		if err != nil {
+			return err
+		}
+
+		// the Function returns the state that it's given with the next batch of the window
+		var response struct {
+			State json.RawMessage `json:"state"`
+		}
+		if json.Unmarshal(result.Payload, &response) == nil && len(response.State) > 0 {
+			window.state = response.State
+		}
+		return nil
+	})
+}
+
+func (poller *kinesisPoller) closeWindow(ctx context.Context, batch int) {
+	window := poller.windows[batch]
+	poller.windows[batch] = nil
+
+	deliverBatch(ctx, poller.eventSource, 0, func(int, int) error {
+		event := poller.windowEvent(window, []types.KinesisEventRecord{}, true)
+		_, err := invokeForEventSource(ctx, poller.eventSource, event, 0)
+		return err
+	})
+}
+
+func (poller *kinesisPoller) windowEvent(window *tumblingWindow, records []types.KinesisEventRecord, final bool) types.KinesisTimeWindowEvent {
+	size := time.Duration(poller.eventSource.TumblingWindowInSeconds) * time.Second
+	return types.KinesisTimeWindowEvent{
+		Records: records,
+		Window: types.KinesisTimeWindow{
+			Start: window.start.UTC().Format(time.RFC3339),
+			End:   window.start.Add(size).UTC().Format(time.RFC3339),
+		},
+		State:                  window.state,
+		ShardId:                poller.shardId,
+		EventSourceARN:         poller.eventSource.Arn,
+		IsFinalInvokeForWindow: final,
+	}
+}
+
+func (poller *kinesisPoller) eventRecords(ctx context.Context, records []kinesisTypes.Record) []types.KinesisEventRecord {
+	cfg := settings.FromContext(ctx)
+	results := make([]types.KinesisEventRecord, len(records))
+	for i, record := range records {
+		results[i] = types.KinesisEventRecord{
+			Kinesis: types.KinesisRecord{
+				KinesisSchemaVersion:        "1.0",
+				PartitionKey:                record.PartitionKey,
+				SequenceNumber:              record.SequenceNumber(),
+				Data:                        record.Data,
+				ApproximateArrivalTimestamp: record.ArrivalTimestamp(),
+			},
+			EventSource:    "aws:kinesis",
+			EventVersion:   "1.0",
+			EventID:        poller.shardId + ":" + record.SequenceNumber(),
+			EventName:      "aws:kinesis:record",
+			AwsRegion:      cfg.Region,
+			EventSourceARN: poller.eventSource.Arn,
+		}
+	}
+
+	return results
+}
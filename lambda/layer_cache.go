@@ -0,0 +1,108 @@
+package lambda
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/utils"
+	"os"
+	"sync"
+)
+
+var layerCacheMutex sync.Mutex
+
+// cacheLayer extracts the Layer version into the cache, unless a Layer with the same code has been extracted already,
+// and returns the directory it was extracted into.
+func cacheLayer(ctx context.Context, layer types.LambdaLayer) (string, error) {
+	zipPath := layer.GetDestPath(ctx)
+	if len(layer.CodeSha256) == 0 {
+		content, err := os.ReadFile(zipPath)
+		if err != nil {
+			msg := log.Error("Unable to read Layer %s version %d: %v", layer.Name, layer.Version, err)
+			return "", errors.New(msg)
+		}
+
+		rawHash := sha256.Sum256(content)
+		layer.CodeSha256 = base64.StdEncoding.EncodeToString(rawHash[:])
+	}
+
+	cachePath := layer.GetCachePath(ctx)
+
+	layerCacheMutex.Lock()
+	defer layerCacheMutex.Unlock()
+
+	_, err := os.Stat(cachePath)
+	if err == nil {
+		log.Debug("Layer %s version %d is already cached in %s", layer.Name, layer.Version, cachePath)
+		return cachePath, nil
+	}
+
+	// extract next to the cache first, so that a failed extraction never looks cached
+	tempPath := cachePath + ".tmp"
+	err = os.RemoveAll(tempPath)
+	if err != nil {
+		msg := log.Error("Unable to clean up %s: %v", tempPath, err)
+		return "", errors.New(msg)
+	}
+
+	log.Info("Extracting Layer %s version %d into %s ...", layer.Name, layer.Version, cachePath)
+	err = utils.UncompressZipFile(zipPath, tempPath)
+	if err != nil {
+		os.RemoveAll(tempPath)
+		msg := log.Error("error when unpacking layer %s: %v", layer.Name, err)
+		return "", errors.New(msg)
+	}
+
+	// Functions get hard links to the cached files, so the files are read only to keep a Function that writes to its
+	// /opt from changing the Layer for every other Function
+	err = utils.MakeFilesReadOnly(tempPath)
+	if err != nil {
+		os.RemoveAll(tempPath)
+		msg := log.Error("Unable to make Layer %s read only: %v", layer.Name, err)
+		return "", errors.New(msg)
+	}
+
+	err = os.Rename(tempPath, cachePath)
+	if err != nil {
+		msg := log.Error("Unable to move Layer %s into the cache: %v", layer.Name, err)
+		return "", errors.New(msg)
+	}
+
+	return cachePath, nil
+}
+
+// assembleLayers builds the directory used as a Function's /opt from its cached Layers. Like Lambda, Layers are
+// merged in order so that files from later Layers override those from earlier ones.
+func assembleLayers(ctx context.Context, function *types.Function) error {
+	layerDestPath := function.GetLayerDestPath(ctx)
+	err := os.RemoveAll(layerDestPath)
+	if err != nil {
+		msg := log.Error("Unable to remove previous Layers of Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	err = utils.CreateDirs(layerDestPath)
+	if err != nil {
+		msg := log.Error("Unable to create Layer path for Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	for _, layer := range function.Layers {
+		cachePath, err := cacheLayer(ctx, layer)
+		if err != nil {
+			return err
+		}
+
+		err = utils.OverlayDir(cachePath, layerDestPath)
+		if err != nil {
+			msg := log.Error("Unable to add Layer %s to Function %s: %v", layer.Name, function.FunctionName, err)
+			return errors.New(msg)
+		}
+	}
+
+	log.Info("Assembled %d Layers for Function %s in %s", len(function.Layers), function.FunctionName, layerDestPath)
+	return nil
+}
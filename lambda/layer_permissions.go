@@ -0,0 +1,276 @@
+package lambda
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"myaws/database"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const layerVersionAction = "lambda:GetLayerVersion"
+
+var principalRegex = regexp.MustCompile(`^(\d{12}|\*|arn:aws:iam::\d{12}:root)$`)
+
+// findLayerVersion writes an error response & returns false when the Layer version from the request path can't be
+// loaded.
+func findLayerVersion(response http.ResponseWriter, request *http.Request, db *database.Database) (types.LambdaLayer, bool) {
+	layerName, version := getLayerNameAndVersion(request.URL.Path)
+
+	layer, err := queries.LayerByNameAndVersion(request.Context(), db, layerName, version)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Layer %s version %d does not exist", layerName, version)
+		http.Error(response, msg, http.StatusNotFound)
+		return layer, false
+	case err != nil:
+		msg := log.Error("Unable to find Layer %s version %d: %v", layerName, version, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return layer, false
+	}
+
+	return layer, true
+}
+
+// checkPolicyRevision writes an error response & returns false when the request expects a different revision of
+// the Layer version's policy.
+func checkPolicyRevision(response http.ResponseWriter, ctx context.Context, db *database.Database, layer types.LambdaLayer, expected string) bool {
+	if len(expected) == 0 {
+		return true
+	}
+
+	revision, err := queries.LayerPolicyRevision(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to check policy revision of Layer %s: %v", layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return false
+	}
+
+	if revision != expected {
+		msg := log.Error("RevisionId %s does not match the current revision %s of the policy for Layer %s:%d",
+			expected, revision, layer.Name, layer.Version)
+		http.Error(response, msg, http.StatusPreconditionFailed)
+		return false
+	}
+
+	return true
+}
+
+const AddLayerVersionPermissionRegex = `^/2018-10-31/layers/[A-Za-z0-9_-]+/versions/\d+/policy$`
+
+func AddLayerVersionPermission(response http.ResponseWriter, request *http.Request) {
+	decoder := json.NewDecoder(request.Body)
+	defer request.Body.Close()
+
+	var body lambda.AddLayerVersionPermissionInput
+	err := decoder.Decode(&body)
+	if err != nil {
+		msg := log.Error("Error when decoding body: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	action := utils.StringOrEmpty(body.Action)
+	principal := utils.StringOrEmpty(body.Principal)
+	statementId := utils.StringOrEmpty(body.StatementId)
+
+	switch {
+	case len(statementId) == 0:
+		msg := log.Error("StatementId is required to add a Layer version permission")
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	case action != layerVersionAction:
+		msg := log.Error("Action %s is not supported for Layer versions, only %s is", action, layerVersionAction)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	case !principalRegex.MatchString(principal):
+		msg := log.Error("Principal %s must be an account ID or *", principal)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	layer, ok := findLayerVersion(response, request, db)
+	if !ok {
+		return
+	}
+
+	if !checkPolicyRevision(response, ctx, db, layer, utils.StringOrEmpty(body.RevisionId)) {
+		return
+	}
+
+	permissions, err := queries.LayerPermissions(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to load policy of Layer %s: %v", layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	for _, existing := range permissions {
+		if existing.StatementId == statementId {
+			msg := log.Error("The statement id (%s) provided already exists for Layer %s:%d", statementId,
+				layer.Name, layer.Version)
+			http.Error(response, msg, http.StatusConflict)
+			return
+		}
+	}
+
+	permission := types.LayerPermission{
+		LayerID:        layer.ID,
+		StatementId:    statementId,
+		Action:         action,
+		Principal:      strings.TrimSuffix(strings.TrimPrefix(principal, "arn:aws:iam::"), ":root"),
+		OrganizationId: utils.StringOrEmpty(body.OrganizationId),
+	}
+
+	revision, err := queries.InsertLayerPermission(ctx, db, layer, permission)
+	if err != nil {
+		msg := log.Error("Unable to add permission to Layer %s: %v", layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	statement := permission.Statement(*layer.GetVersionArn(ctx))
+	result := lambda.AddLayerVersionPermissionOutput{
+		RevisionId: &revision,
+		Statement:  &statement,
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(result)
+}
+
+const GetLayerVersionPolicyRegex = `^/2018-10-31/layers/[A-Za-z0-9_-]+/versions/\d+/policy$`
+
+func GetLayerVersionPolicy(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	layer, ok := findLayerVersion(response, request, db)
+	if !ok {
+		return
+	}
+
+	permissions, err := queries.LayerPermissions(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to load policy of Layer %s: %v", layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	if len(permissions) == 0 {
+		msg := log.Error("No policy is associated with Layer %s:%d", layer.Name, layer.Version)
+		http.Error(response, msg, http.StatusNotFound)
+		return
+	}
+
+	revision, err := queries.LayerPolicyRevision(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to load policy revision of Layer %s: %v", layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	policy := types.LayerPolicy(*layer.GetVersionArn(ctx), permissions)
+	result := lambda.GetLayerVersionPolicyOutput{
+		Policy:     &policy,
+		RevisionId: &revision,
+	}
+
+	utils.RespondWithJson(response, result)
+}
+
+const RemoveLayerVersionPermissionRegex = `^/2018-10-31/layers/[A-Za-z0-9_-]+/versions/\d+/policy/[A-Za-z0-9_-]+$`
+
+func RemoveLayerVersionPermission(response http.ResponseWriter, request *http.Request) {
+	parts := strings.Split(request.URL.Path, "/")
+	statementId := parts[7]
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	layer, ok := findLayerVersion(response, request, db)
+	if !ok {
+		return
+	}
+
+	if !checkPolicyRevision(response, ctx, db, layer, request.URL.Query().Get("RevisionId")) {
+		return
+	}
+
+	err := queries.DeleteLayerPermission(ctx, db, layer, statementId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Statement %s is not found in the policy of Layer %s:%d", statementId, layer.Name,
+			layer.Version)
+		http.Error(response, msg, http.StatusNotFound)
+		return
+	case err != nil:
+		msg := log.Error("Unable to remove permission %s from Layer %s: %v", statementId, layer.Name, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// deniedLayer returns the ARN of the first Layer version that the caller's account isn't allowed to use, or an
+// empty string when it can use all of them. Layers are owned by the myaws account, so callers from that account are
+// always allowed.
+func deniedLayer(ctx context.Context, db *database.Database, layers []types.LambdaLayer) (string, error) {
+	cfg := settings.FromContext(ctx)
+	caller := settings.CallerAccount(ctx)
+	if caller == cfg.AccountNumber {
+		return "", nil
+	}
+
+	organization := cfg.OrganizationForAccount(caller)
+
+	for _, reference := range layers {
+		layer, err := queries.LayerByNameAndVersion(ctx, db, reference.Name, reference.Version)
+		if err == sql.ErrNoRows {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		permissions, err := queries.LayerPermissions(ctx, db, layer)
+		if err != nil {
+			return "", err
+		}
+
+		allowed := false
+		for _, permission := range permissions {
+			if permission.Allows(caller, organization) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			log.Info("Account %s has no permission to use Layer %s:%d", caller, layer.Name, layer.Version)
+			return *layer.GetVersionArn(ctx), nil
+		}
+	}
+
+	return "", nil
+}
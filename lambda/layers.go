@@ -17,6 +17,7 @@ import (
 	"myaws/settings"
 	"myaws/utils"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -27,11 +28,120 @@ func getLayerName(path string) string {
 	return parts[3]
 }
 
+const defaultMaxItems = 50
+
+// layerFilter holds the query parameters used to filter & paginate the List APIs for Layers.
+type layerFilter struct {
+	runtime      aws.Runtime
+	architecture aws.Architecture
+	marker       string
+	maxItems     int
+}
+
+func parseLayerFilter(request *http.Request) (layerFilter, error) {
+	query := request.URL.Query()
+	filter := layerFilter{
+		runtime:      aws.Runtime(query.Get("CompatibleRuntime")),
+		architecture: aws.Architecture(query.Get("CompatibleArchitecture")),
+		marker:       query.Get("Marker"),
+		maxItems:     defaultMaxItems,
+	}
+
+	if value := query.Get("MaxItems"); len(value) > 0 {
+		maxItems, err := strconv.Atoi(value)
+		if err != nil || maxItems < 1 {
+			return filter, fmt.Errorf("invalid MaxItems %s", value)
+		}
+		filter.maxItems = maxItems
+	}
+
+	return filter, nil
+}
+
+const ListLayersRegex = `^/2018-10-31/layers/?$`
+
+func ListLayers(response http.ResponseWriter, request *http.Request) {
+	filter, err := parseLayerFilter(request)
+	if err != nil {
+		msg := log.Error("Unable to list Layers: %v", err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	names, err := queries.LayerNames(ctx, db)
+	if err != nil {
+		msg := log.Error("Unable to list Layers: %v", err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	var items []aws.LayersListItem
+	for _, name := range names {
+		versions, err := queries.LayerByName(ctx, db, name)
+		if err != nil {
+			msg := log.Error("Unable to list versions of Layer %s: %v", name, err)
+			http.Error(response, msg, http.StatusInternalServerError)
+			return
+		}
+
+		// versions are sorted newest first, so the first match is the latest one
+		for _, layer := range versions {
+			if layer.IsCompatible(filter.runtime, filter.architecture) {
+				latest := layer.ToLayerVersionsListItem(ctx)
+				items = append(items, aws.LayersListItem{
+					LatestMatchingVersion: &latest,
+					LayerArn:              layer.GetArn(ctx),
+					LayerName:             &layer.Name,
+				})
+				break
+			}
+		}
+	}
+
+	start := 0
+	if len(filter.marker) > 0 {
+		start = len(items)
+		for i, item := range items {
+			if *item.LayerName == filter.marker {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + filter.maxItems
+	var nextMarker *string
+	if end < len(items) {
+		nextMarker = items[end].LayerName
+	} else {
+		end = len(items)
+	}
+
+	result := lambda.ListLayersOutput{
+		Layers:     append([]aws.LayersListItem{}, items[start:end]...),
+		NextMarker: nextMarker,
+	}
+
+	utils.RespondWithJson(response, result)
+}
+
 const GetAllLayerVersionsRegex = `^/2018-10-31/layers/[A-Za-z0-9_-]+/versions$`
 
 func GetAllLayerVersions(response http.ResponseWriter, request *http.Request) {
 	layerName := getLayerName(request.URL.Path)
 
+	filter, err := parseLayerFilter(request)
+	if err != nil {
+		msg := log.Error("Unable to list versions of Layer %s: %v", layerName, err)
+		http.Error(response, msg, http.StatusBadRequest)
+		return
+	}
+
 	ctx := request.Context()
 	cfg := settings.FromContext(ctx)
 	db := database.CreateConnection(cfg)
@@ -40,11 +150,39 @@ func GetAllLayerVersions(response http.ResponseWriter, request *http.Request) {
 	layers, err := queries.LayerByName(ctx, db, layerName)
 	if err != nil {
 		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var matching []types.LambdaLayer
+	for _, layer := range layers {
+		if layer.IsCompatible(filter.runtime, filter.architecture) {
+			matching = append(matching, layer)
+		}
+	}
+
+	start := 0
+	if len(filter.marker) > 0 {
+		start = len(matching)
+		for i, layer := range matching {
+			if strconv.Itoa(layer.Version) == filter.marker {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + filter.maxItems
+	var nextMarker *string
+	if end < len(matching) {
+		marker := strconv.Itoa(matching[end].Version)
+		nextMarker = &marker
+	} else {
+		end = len(matching)
 	}
 
 	result := lambda.ListLayerVersionsOutput{
-		LayerVersions: layersToAwsLayers(layers, ctx),
-		NextMarker:    nil,
+		LayerVersions: layersToAwsLayers(matching[start:end], ctx),
+		NextMarker:    nextMarker,
 	}
 
 	utils.RespondWithJson(response, result)
@@ -67,15 +205,21 @@ func GetLayerVersion(response http.ResponseWriter, request *http.Request) {
 	defer db.Close()
 
 	layer, err := queries.LayerByNameAndVersion(ctx, db, layerName, version)
-	if err != nil {
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Layer %s version %d does not exist", layerName, version)
+		http.Error(response, msg, http.StatusNotFound)
+		return
+	case err != nil:
 		log.Error(err.Error())
 		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	log.Info("... found %+v", layer)
 
 	result := lambda.GetLayerVersionOutput{
-		CompatibleArchitectures: []aws.Architecture{},
+		CompatibleArchitectures: layer.CompatibleArchitectures,
 		CompatibleRuntimes:      layer.CompatibleRuntimes,
 		Content: &aws.LayerVersionContentOutput{
 			CodeSize:   layer.CodeSize,
@@ -92,6 +236,58 @@ func GetLayerVersion(response http.ResponseWriter, request *http.Request) {
 	utils.RespondWithJson(response, result)
 }
 
+const DeleteLayerVersionRegex = `^/2018-10-31/layers/[A-Za-z0-9_-]+/versions/\d+$`
+
+// DeleteLayerVersion removes a Layer version & its stored code. Like Lambda, Functions that already reference the
+// version keep working (their copy of the Layer has already been extracted), so they only result in a warning.
+func DeleteLayerVersion(response http.ResponseWriter, request *http.Request) {
+	layerName, version := getLayerNameAndVersion(request.URL.Path)
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	layer, err := queries.LayerByNameAndVersion(ctx, db, layerName, version)
+	switch {
+	case err == sql.ErrNoRows:
+		// deleting a version that doesn't exist is not an error
+		log.Info("Layer %s version %d has already been deleted", layerName, version)
+		response.WriteHeader(http.StatusNoContent)
+		return
+	case err != nil:
+		msg := log.Error("Unable to find Layer %s version %d: %v", layerName, version, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	functions, err := queries.FunctionsUsingLayer(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to check Functions using Layer %s version %d: %v", layerName, version, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	if len(functions) > 0 {
+		log.Info("WARNING: deleting Layer %s version %d which is still used by Functions %v", layerName, version, functions)
+	}
+
+	err = queries.DeleteLayer(ctx, db, layer)
+	if err != nil {
+		msg := log.Error("Unable to delete Layer %s version %d: %v", layerName, version, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	destPath := layer.GetDestPath(ctx)
+	err = os.Remove(destPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Error("Unable to remove %s for Layer %s version %d: %v", destPath, layerName, version, err)
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
 func layersToAwsLayers(layers []types.LambdaLayer, ctx context.Context) []aws.LayerVersionsListItem {
 	results := make([]aws.LayerVersionsListItem, len(layers))
 	for i, layer := range layers {
@@ -129,9 +325,11 @@ func PostLayerVersions(response http.ResponseWriter, request *http.Request) {
 	case err == sql.ErrNoRows:
 		msg := fmt.Sprintf("unable to find all expected runtimes: %v", body.CompatibleRuntimes)
 		http.Error(response, msg, http.StatusNotFound)
+		return
 	case err != nil:
 		msg := fmt.Sprintf("error when querying runtime: %v", err)
 		http.Error(response, msg, http.StatusInternalServerError)
+		return
 	}
 
 	version, err := queries.LatestLayerByName(ctx, db, layerName)
@@ -158,6 +356,8 @@ func PostLayerVersions(response http.ResponseWriter, request *http.Request) {
 		CompatibleRuntimes: body.CompatibleRuntimes,
 		CodeSize:           int64(len(body.Content.ZipFile)),
 		CodeSha256:         hash,
+
+		CompatibleArchitectures: body.CompatibleArchitectures,
 	}
 
 	destPath := layer.GetDestPath(ctx)
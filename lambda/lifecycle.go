@@ -0,0 +1,256 @@
+package lambda
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"myaws/database"
+	"myaws/docker"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const readyPollInterval = 50 * time.Millisecond
+
+var reaperOnce sync.Once
+
+// ensureRunning starts the named Function if it isn't already running, and returns how long that took. Concurrent
+// invocations of a stopped Function share a single cold start.
+func (manager *ManagerImpl) ensureRunning(ctx context.Context, name string) (time.Duration, error) {
+	if manager.isRunning(name) {
+		return 0, nil
+	}
+
+	lock := manager.startLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if manager.isRunning(name) {
+		return 0, nil
+	}
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	function, err := runnableFunction(ctx, db, name)
+	db.Close()
+
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("Cold starting Function %s ...", name)
+	begin := time.Now()
+
+	// the cold start shouldn't be cancelled just because the invocation that triggered it was
+	startCtx := detachedContext(ctx)
+	err = StartFunction(startCtx, function)
+	if err == nil {
+		err = manager.waitUntilReady(startCtx, name, cfg.Functions.StartTimeout)
+	}
+
+	// the Function stays Active, so a failed cold start is only reported to this invocation and retried by the next
+	if err != nil {
+		StopFunction(startCtx, name)
+		return 0, err
+	}
+
+	reaperOnce.Do(func() {
+		go manager.reapIdleFunctions(startCtx)
+	})
+
+	initDuration := time.Since(begin)
+	log.Info("Function %s started in %s", name, initDuration)
+	return initDuration, nil
+}
+
+// runnableFunction loads the latest version of the Function as it is saved on disk, rather than as $LATEST.
+func runnableFunction(ctx context.Context, db *database.Database, name string) (*types.Function, error) {
+	function, err := queries.LatestFunctionByName(ctx, db, name)
+	if err == sql.ErrNoRows {
+		msg := log.Error("Function %s does not exist", name)
+		return nil, errors.New(msg)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := queries.LatestFunctionVersionByName(ctx, db, &name)
+	if err != nil {
+		return nil, err
+	}
+
+	function.Version = strconv.Itoa(version)
+	return function, nil
+}
+
+func (manager *ManagerImpl) isRunning(name string) bool {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	_, isApi := manager.apis[name]
+	_, isRunning := manager.ports[name]
+	return isApi || isRunning
+}
+
+func (manager *ManagerImpl) startLock(name string) *sync.Mutex {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	lock, ok := manager.starting[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		manager.starting[name] = lock
+	}
+
+	return lock
+}
+
+// waitUntilReady polls a Function's container until it answers HTTP requests. Docker accepts connections on the
+// published port before anything in the container listens on it, so a successful connection isn't enough. Functions
+// using the Runtime API are ready as soon as they are started, since invocations wait for their runtime to poll.
+func (manager *ManagerImpl) waitUntilReady(ctx context.Context, name string, timeout time.Duration) error {
+	manager.mutex.RLock()
+	port, ok := manager.ports[name]
+	manager.mutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/", port)
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(readyPollInterval):
+		}
+	}
+
+	msg := log.Error("Function %s did not accept invocations within %s", name, timeout)
+	return errors.New(msg)
+}
+
+// invocationStarted records that the Function is in use, so that it isn't stopped for being idle.
+func (manager *ManagerImpl) invocationStarted(name string) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.inFlight[name]++
+	manager.lastUsed[name] = time.Now()
+}
+
+func (manager *ManagerImpl) invocationFinished(name string) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.inFlight[name]--
+	manager.lastUsed[name] = time.Now()
+}
+
+// reapIdleFunctions stops Functions that haven't been invoked for longer than the idle timeout.
+func (manager *ManagerImpl) reapIdleFunctions(ctx context.Context) {
+	cfg := settings.FromContext(ctx)
+	timeout := cfg.Functions.IdleTimeout
+	if timeout <= 0 {
+		log.Info("Idle Functions will not be stopped")
+		return
+	}
+
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, name := range manager.idleFunctions(timeout) {
+				log.Info("Stopping Function %s after being idle for %s", name, timeout)
+				lock := manager.startLock(name)
+				lock.Lock()
+				err := StopFunction(ctx, name)
+				lock.Unlock()
+
+				if err != nil {
+					log.Error("Unable to stop idle Function %s: %v", name, err)
+				}
+			}
+		}
+	}
+}
+
+func (manager *ManagerImpl) idleFunctions(timeout time.Duration) []string {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	var results []string
+	for name, lastUsed := range manager.lastUsed {
+		if manager.inFlight[name] > 0 || time.Since(lastUsed) < timeout {
+			continue
+		}
+
+		// Functions that were stopped some other way are simply forgotten
+		delete(manager.lastUsed, name)
+		_, isApi := manager.apis[name]
+		_, isRunning := manager.ports[name]
+		if isApi || isRunning {
+			results = append(results, name)
+		}
+	}
+
+	return results
+}
+
+// logReport logs the summary of an invocation in the format of Lambda's REPORT line.
+func logReport(requestId string, duration time.Duration, memorySize int32, initDuration time.Duration) {
+	milliseconds := float64(duration.Microseconds()) / 1000
+	line := fmt.Sprintf("REPORT RequestId: %s\tDuration: %.2f ms\tBilled Duration: %d ms", requestId, milliseconds,
+		int64(math.Ceil(milliseconds)))
+
+	if memorySize > 0 {
+		line += fmt.Sprintf("\tMemory Size: %d MB", memorySize)
+	}
+
+	if initDuration > 0 {
+		line += fmt.Sprintf("\tInit Duration: %.2f ms", float64(initDuration.Microseconds())/1000)
+	}
+
+	log.Info(line)
+}
+
+// prepareFunction makes sure that the Function can be started later, by pulling the image that it runs in.
+func prepareFunction(ctx context.Context, function *types.Function) error {
+	cfg := settings.FromContext(ctx)
+	if function.IsImage() {
+		return docker.PullImageOnce(ctx, function.ImageUri)
+	}
+
+	runtime, ok := types.RuntimeByName(function.Runtime)
+	if !ok {
+		msg := log.Error("Function %s has an unsupported runtime %s", function.FunctionName, function.Runtime)
+		return errors.New(msg)
+	}
+
+	if cfg.Functions.ExecutorFor(function.FunctionName) == settings.ExecutorProcess {
+		return nil
+	}
+
+	return docker.PullImageOnce(ctx, runtime.Image)
+}
@@ -0,0 +1,75 @@
+package lambda
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/logs"
+	logsTypes "myaws/logs/types"
+	"myaws/settings"
+	"sync"
+	"time"
+)
+
+// logFlushInterval is how long lines of output wait to be put into CloudWatch Logs, so that they're put in batches.
+const logFlushInterval = time.Second
+
+// functionLogger puts the output of a Function's container or process into a log stream of its own, in the Function's
+// log group, like Lambda does for each of its execution environments.
+type functionLogger struct {
+	ctx        context.Context
+	groupName  string
+	streamName string
+
+	mutex  sync.Mutex
+	events []logsTypes.InputLogEvent
+
+	// putMutex keeps batches in order when putting one takes longer than the flush interval
+	putMutex sync.Mutex
+}
+
+func newFunctionLogger(ctx context.Context, function *types.Function) *functionLogger {
+	cfg := settings.FromContext(ctx)
+
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	// the output is put after the request that started the Function finishes, so it can't use the request's context
+	return &functionLogger{
+		ctx:        cfg.NewContext(context.Background()),
+		groupName:  "/aws/lambda/" + function.FunctionName,
+		streamName: time.Now().Format("2006/01/02") + "/[$LATEST]" + hex.EncodeToString(id),
+	}
+}
+
+// Output keeps the line to put into the log stream with the next batch.
+func (logger *functionLogger) Output(line string) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+
+	logger.events = append(logger.events, logsTypes.InputLogEvent{Timestamp: time.Now().UnixMilli(), Message: line})
+	if len(logger.events) == 1 {
+		time.AfterFunc(logFlushInterval, logger.flush)
+	}
+}
+
+func (logger *functionLogger) flush() {
+	logger.putMutex.Lock()
+	defer logger.putMutex.Unlock()
+
+	logger.mutex.Lock()
+	events := logger.events
+	logger.events = nil
+	logger.mutex.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	err := logs.PutEvents(logger.ctx, logger.groupName, logger.streamName, events)
+	if err != nil {
+		log.Error("Unable to put %d lines of output into log stream %s: %v", len(events), logger.streamName, err)
+	}
+}
@@ -111,4 +111,150 @@ var Migrations = []database.Migration{
 				CREATE UNIQUE INDEX uk_lambda_event_source on lambda_event_source(arn, function_id);
 		`,
 	},
+	{
+		Service:     "Lambda",
+		Description: "Add Node.js, Java, Go, Ruby, .NET & custom Runtimes",
+		Query: `INSERT OR IGNORE INTO lambda_runtime (name) VALUES
+				('dotnetcore3.1'),
+				('go1.x'),
+				('java8.al2'),
+				('java11'),
+				('nodejs12.x'),
+				('nodejs14.x'),
+				('nodejs16.x'),
+				('provided'),
+				('provided.al2'),
+				('python3.9'),
+				('ruby2.7');
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add Package Type & Image Config to Function",
+		Query: `ALTER TABLE lambda_function ADD COLUMN package_type text not null DEFAULT 'Zip';
+				ALTER TABLE lambda_function ADD COLUMN image_uri text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN image_entry_point text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN image_command text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN image_working_directory text not null DEFAULT '';
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Create Layer Architecture Table",
+		Query: `CREATE TABLE IF NOT EXISTS lambda_layer_architecture (
+					id					integer primary key autoincrement,
+					lambda_layer_id		integer not null,
+					architecture		text not null,
+					FOREIGN KEY(lambda_layer_id) REFERENCES lambda_layer(id)
+				);
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Create Layer Permission Table",
+		Query: `CREATE TABLE IF NOT EXISTS lambda_layer_permission (
+					id					integer primary key autoincrement,
+					lambda_layer_id		integer not null,
+					statement_id		text not null,
+					action				text not null,
+					principal			text not null,
+					organization_id		text not null DEFAULT '',
+					UNIQUE(lambda_layer_id, statement_id),
+					FOREIGN KEY(lambda_layer_id) REFERENCES lambda_layer(id)
+				);
+				ALTER TABLE lambda_layer ADD COLUMN policy_revision_id text not null DEFAULT '';
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add State & Last Update Status to Function",
+		Query: `ALTER TABLE lambda_function ADD COLUMN state text not null DEFAULT 'Active';
+				ALTER TABLE lambda_function ADD COLUMN state_reason text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN state_reason_code text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN last_update_status text not null DEFAULT 'Successful';
+				ALTER TABLE lambda_function ADD COLUMN last_update_status_reason text not null DEFAULT '';
+				ALTER TABLE lambda_function ADD COLUMN last_update_status_reason_code text not null DEFAULT '';
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Create Invocation Table",
+		Query: `CREATE TABLE IF NOT EXISTS lambda_invocation (
+					id					integer primary key autoincrement,
+					function_name		text not null,
+					version				text not null,
+					request_id			text not null,
+					source				text not null,
+					payload				text not null,
+					response			text not null,
+					status_code			integer not null,
+					function_error		text not null,
+					error				text not null,
+					duration_ms			real not null,
+					init_duration_ms	real not null,
+					invoked_on			integer not null
+				);
+				CREATE INDEX IF NOT EXISTS lambda_invocation_function_name ON lambda_invocation (function_name);
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Create Function URL Table",
+		Query: `CREATE TABLE IF NOT EXISTS lambda_function_url (
+					id					integer primary key autoincrement,
+					url_id				text not null UNIQUE,
+					function_name		text not null,
+					qualifier			text not null,
+					auth_type			text not null,
+					cors				text not null,
+					created_on			integer not null,
+					last_modified_on	integer not null,
+					UNIQUE(function_name, qualifier)
+				);
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add Starting Position, Bisect & Retries to Event Source",
+		Query: `ALTER TABLE lambda_event_source ADD COLUMN starting_position text not null DEFAULT '';
+				ALTER TABLE lambda_event_source ADD COLUMN bisect_batch_on_function_error integer not null DEFAULT 0;
+				ALTER TABLE lambda_event_source ADD COLUMN maximum_retry_attempts integer not null DEFAULT -1;
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add Parallelization & Tumbling Window to Event Source & Create Checkpoint Table",
+		Query: `ALTER TABLE lambda_event_source ADD COLUMN parallelization_factor integer not null DEFAULT 1;
+				ALTER TABLE lambda_event_source ADD COLUMN tumbling_window_in_seconds integer not null DEFAULT 0;
+				CREATE TABLE IF NOT EXISTS lambda_event_source_checkpoint (
+					id					integer primary key autoincrement,
+					event_source_uuid	text not null,
+					shard_id			text not null,
+					sequence_number		text not null,
+					updated_on			integer not null,
+					UNIQUE(event_source_uuid, shard_id)
+				);
+		`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add Architecture to Function",
+		Query:       `ALTER TABLE lambda_function ADD COLUMN architecture text not null DEFAULT 'x86_64';`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Add Error Type to Invocation",
+		Query:       `ALTER TABLE lambda_invocation ADD COLUMN error_type text not null DEFAULT '';`,
+	},
+	{
+		Service:     "Lambda",
+		Description: "Create Layer Latest Version Table",
+		Query: `CREATE TABLE IF NOT EXISTS lambda_layer_latest_version (
+					name			text primary key,
+					version			integer not null
+				);
+				INSERT OR IGNORE INTO lambda_layer_latest_version (name, version)
+					SELECT name, max(version) FROM lambda_layer GROUP BY name;
+		`,
+	},
 }
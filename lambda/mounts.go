@@ -0,0 +1,126 @@
+package lambda
+
+import (
+	"context"
+	"myaws/database"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"path/filepath"
+)
+
+// codePath returns the directory used as a Function's /var/task, which is either a host directory mounted through
+// settings or the Function's unpacked zip.
+func codePath(ctx context.Context, function *types.Function) string {
+	cfg := settings.FromContext(ctx)
+	path, ok := cfg.Functions.SourcePaths[function.FunctionName]
+	if !ok {
+		return function.GetDestPath(ctx)
+	}
+
+	return absolutePath(path)
+}
+
+// layerPath returns the directory used as a Function's /opt, which is either a host directory mounted through
+// settings or the Function's unpacked Layers.
+func layerPath(ctx context.Context, function *types.Function) string {
+	cfg := settings.FromContext(ctx)
+	path, ok := cfg.Functions.LayerPaths[function.FunctionName]
+	if !ok {
+		return function.GetLayerDestPath(ctx)
+	}
+
+	return absolutePath(path)
+}
+
+func absolutePath(path string) string {
+	result, err := filepath.Abs(path)
+	if err != nil {
+		log.Error("Unable to make %s absolute: %v", path, err)
+		return path
+	}
+
+	return result
+}
+
+// watchFunction restarts the Function whenever its mounted code or Layer directories change. Functions that aren't
+// mounted, or are already being watched, are ignored.
+func watchFunction(ctx context.Context, function *types.Function) {
+	cfg := settings.FromContext(ctx)
+
+	var dirs []string
+	if _, ok := cfg.Functions.SourcePaths[function.FunctionName]; ok {
+		dirs = append(dirs, codePath(ctx, function))
+	}
+
+	if _, ok := cfg.Functions.LayerPaths[function.FunctionName]; ok {
+		dirs = append(dirs, layerPath(ctx, function))
+	}
+
+	if len(dirs) == 0 {
+		return
+	}
+
+	m := getManager()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.watchers[function.FunctionName]; ok {
+		return
+	}
+
+	// watching outlives the request that started the Function, so it is only canceled on shutdown
+	watchCtx, cancel := context.WithCancel(cfg.NewContext(context.Background()))
+	m.watchers[function.FunctionName] = cancel
+
+	log.Info("Watching %v for changes to Function %s", dirs, function.FunctionName)
+
+	changes := utils.WatchDirs(watchCtx, cfg.Functions.WatchInterval, dirs...)
+	go func() {
+		for range changes {
+			restartChangedFunction(watchCtx, function.FunctionName)
+		}
+	}()
+}
+
+// restartChangedFunction restarts the latest version of a running Function. Functions that are stopped, such as
+// idle ones, pick up the changes when they are next started.
+func restartChangedFunction(ctx context.Context, name string) {
+	m := getManager()
+	lock := m.startLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if !m.isRunning(name) {
+		log.Info("Detected changes to Function %s, which isn't running", name)
+		return
+	}
+
+	log.Info("Detected changes to Function %s, restarting ...", name)
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	function, err := runnableFunction(ctx, db, name)
+	db.Close()
+
+	if err == nil {
+		err = RestartFunction(ctx, function)
+	}
+
+	if err != nil {
+		log.Error("Unable to restart Function %s after changes: %v", name, err)
+	}
+}
+
+// StopWatching stops watching the mounted directories of every Function.
+func StopWatching() {
+	m := getManager()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for name, cancel := range m.watchers {
+		cancel()
+		delete(m.watchers, name)
+	}
+}
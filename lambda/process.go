@@ -0,0 +1,196 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"io"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var processesMutex sync.Mutex
+var processes = make(map[string]*exec.Cmd)
+
+// startProcess runs a Function as a subprocess of myaws, with the Function's code and Layer directories standing in
+// for /var/task and /opt. The process talks to an in-process Runtime API, so Docker isn't needed at all.
+func startProcess(ctx context.Context, function *types.Function, port int) error {
+	cfg := settings.FromContext(ctx)
+
+	if function.IsImage() {
+		msg := log.Error("Unable to start Function %s as a process: container images require Docker", function.FunctionName)
+		return errors.New(msg)
+	}
+
+	runtime, ok := types.RuntimeByName(function.Runtime)
+	if !ok {
+		msg := log.Error("Unable to start Function %s: unsupported runtime %s", function.FunctionName, function.Runtime)
+		return errors.New(msg)
+	}
+
+	taskRoot := codePath(ctx, function)
+	layerRoot := layerPath(ctx, function)
+
+	var command []string
+	switch runtime.Family {
+	case types.FamilyGo:
+		command = []string{filepath.Join(taskRoot, function.Handler)}
+	case types.FamilyProvided:
+		command = []string{filepath.Join(taskRoot, "bootstrap")}
+		_, err := os.Stat(command[0])
+		if err != nil {
+			command = []string{filepath.Join(layerRoot, "bootstrap")}
+		}
+	case types.FamilyPython:
+		command = []string{cfg.Functions.Python, "-m", "awslambdaric", function.Handler}
+	default:
+		msg := log.Error("Unable to start Function %s as a process: runtime %s is not supported", function.FunctionName,
+			function.Runtime)
+		return errors.New(msg)
+	}
+
+	timeout := time.Duration(function.Timeout) * time.Second
+	api := NewRuntimeApi(function.FunctionName, *function.GetArn(ctx), timeout)
+	err := api.Listen(port)
+	if err != nil {
+		return err
+	}
+
+	// the Runtime API is closed when the process doesn't start, so that its port isn't left bound
+	started := false
+	defer func() {
+		if !started {
+			api.Close(ctx)
+		}
+	}()
+
+	environment := buildEnvironment(ctx, function, cfg.HTTP.BuildUrl(""))
+	environment = append(environment,
+		"AWS_LAMBDA_RUNTIME_API=localhost:"+strconv.Itoa(port),
+		"_HANDLER="+function.Handler,
+		"LAMBDA_TASK_ROOT="+taskRoot,
+		"LAMBDA_RUNTIME_DIR="+taskRoot,
+		"HOME="+os.Getenv("HOME"),
+		"PATH="+filepath.Join(layerRoot, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"LD_LIBRARY_PATH="+filepath.Join(layerRoot, "lib"),
+	)
+
+	if runtime.Family == types.FamilyPython {
+		pythonPath := []string{
+			taskRoot,
+			filepath.Join(layerRoot, "python"),
+			filepath.Join(layerRoot, "python", "lib", string(runtime.Name), "site-packages"),
+		}
+		environment = append(environment, "PYTHONPATH="+strings.Join(pythonPath, string(os.PathListSeparator)))
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = taskRoot
+	cmd.Env = environment
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		msg := log.Error("Unable to capture output of Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+	cmd.Stderr = cmd.Stdout
+
+	log.Info("Starting Function %s as process %v", function.FunctionName, command)
+	err = cmd.Start()
+	if err != nil {
+		msg := log.Error("Unable to start process for Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	started = true
+	processesMutex.Lock()
+	processes[function.FunctionName] = cmd
+	processesMutex.Unlock()
+
+	go followProcess(ctx, function.FunctionName, cmd, stdout, newFunctionLogger(ctx, function))
+
+	getManager().AddRuntimeApi(function.FunctionName, api)
+	return nil
+}
+
+// followProcess logs the output of a Function's process until it exits. A process that exits without being stopped
+// is forgotten along with its Runtime API, so that the next invocation starts the Function again.
+func followProcess(ctx context.Context, name string, cmd *exec.Cmd, output io.Reader, logger *functionLogger) {
+	for line := range utils.ReadLinesAsBytes(output) {
+		text := string(line)
+		log.Info("[PROCESS %s] %s", name, text)
+		logger.Output(text)
+	}
+
+	err := cmd.Wait()
+	log.Info("[PROCESS] Function %s exited: %v", name, err)
+
+	processesMutex.Lock()
+	exited := processes[name] == cmd
+	if exited {
+		delete(processes, name)
+	}
+	processesMutex.Unlock()
+
+	if !exited {
+		return
+	}
+
+	manager := getManager()
+	lock := manager.startLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	port, ok := manager.Remove(ctx, name)
+	if ok && pool != nil {
+		pool.Release(port)
+	}
+}
+
+func stopProcess(name string) error {
+	processesMutex.Lock()
+	cmd, ok := processes[name]
+	delete(processes, name)
+	processesMutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	err := cmd.Process.Kill()
+	if err != nil {
+		msg := log.Error("Unable to stop process for Function %s: %v", name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// ShutdownProcesses stops every Function that is running as a subprocess of myaws.
+func ShutdownProcesses() error {
+	processesMutex.Lock()
+	defer processesMutex.Unlock()
+
+	var allErrors []string
+	for name, cmd := range processes {
+		log.Info("Trying to shutdown process for Function %s...", name)
+		err := cmd.Process.Kill()
+		if err != nil {
+			allErrors = append(allErrors, log.Error("Unable to stop process for Function %s: %v", name, err))
+		}
+	}
+
+	if len(allErrors) > 0 {
+		return errors.New(strings.Join(allErrors, ","))
+	}
+
+	return nil
+}
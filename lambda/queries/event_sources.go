@@ -8,19 +8,27 @@ import (
 	"myaws/database"
 	"myaws/lambda/types"
 	"myaws/log"
+	"time"
 )
 
 func SaveEventSource(ctx context.Context, db *database.Database, eventSource types.EventSource) error {
 	_, err := db.InsertOne(
 		ctx,
-		`INSERT INTO lambda_event_source (uuid, enabled, arn, function_id, batch_size, last_modified_on)
-					VALUES (?, ?, ?, ?, ?, ?)
+		`INSERT INTO lambda_event_source (uuid, enabled, arn, function_id, batch_size, starting_position,
+					bisect_batch_on_function_error, maximum_retry_attempts, parallelization_factor,
+					tumbling_window_in_seconds, last_modified_on)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 		eventSource.UUID.String(),
 		eventSource.Enabled,
 		eventSource.Arn,
 		eventSource.Function.ID,
 		eventSource.BatchSize,
+		eventSource.StartingPosition,
+		eventSource.BisectBatchOnFunctionError,
+		eventSource.MaximumRetryAttempts,
+		eventSource.ParallelizationFactor,
+		eventSource.TumblingWindowInSeconds,
 		eventSource.LastModified,
 	)
 
@@ -45,7 +53,9 @@ func LoadEventSource(ctx context.Context, db *database.Database, id string) (*ty
 
 	row := db.QueryRowContext(
 		ctx,
-		`SELECT enabled, arn, function_id, batch_size, last_modified_on FROM lambda_event_source WHERE uuid=?`,
+		`SELECT enabled, arn, function_id, batch_size, starting_position, bisect_batch_on_function_error,
+					maximum_retry_attempts, parallelization_factor, tumbling_window_in_seconds, last_modified_on
+				FROM lambda_event_source WHERE uuid=?`,
 		id,
 	)
 
@@ -55,6 +65,11 @@ func LoadEventSource(ctx context.Context, db *database.Database, id string) (*ty
 		&eventSource.Arn,
 		&functionId,
 		&eventSource.BatchSize,
+		&eventSource.StartingPosition,
+		&eventSource.BisectBatchOnFunctionError,
+		&eventSource.MaximumRetryAttempts,
+		&eventSource.ParallelizationFactor,
+		&eventSource.TumblingWindowInSeconds,
 		&eventSource.LastModified,
 	)
 
@@ -88,3 +103,81 @@ func LoadEventSource(ctx context.Context, db *database.Database, id string) (*ty
 
 	return &eventSource, nil
 }
+
+// EnabledEventSourceIds returns the UUIDs of the enabled event sources whose ARNs are of the service.
+func EnabledEventSourceIds(ctx context.Context, db *database.Database, service string) ([]string, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT uuid FROM lambda_event_source WHERE enabled=1 AND arn LIKE ? ORDER BY id`,
+		"arn:aws:"+service+":%",
+	)
+	if err != nil {
+		msg := log.Error("Unable to query Event Sources of %s: %v", service, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []string{}
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			msg := log.Error("Unable to scan Event Source of %s: %v", service, err)
+			return nil, errors.New(msg)
+		}
+		results = append(results, id)
+	}
+
+	return results, nil
+}
+
+// SaveCheckpoint keeps the sequence number of the last record of the shard that the event source has processed.
+func SaveCheckpoint(ctx context.Context, db *database.Database, eventSourceId string, shardId string, sequenceNumber string) error {
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO lambda_event_source_checkpoint (event_source_uuid, shard_id, sequence_number, updated_on)
+					VALUES (?, ?, ?, ?)
+				ON CONFLICT(event_source_uuid, shard_id) DO UPDATE SET
+					sequence_number = excluded.sequence_number,
+					updated_on = excluded.updated_on
+		`,
+		eventSourceId,
+		shardId,
+		sequenceNumber,
+		time.Now().UnixMilli(),
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save checkpoint of Event Source %s in shard %s: %v", eventSourceId, shardId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// Checkpoints returns the sequence numbers of the last records that the event source has processed, by shard.
+func Checkpoints(ctx context.Context, db *database.Database, eventSourceId string) (map[string]string, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT shard_id, sequence_number FROM lambda_event_source_checkpoint WHERE event_source_uuid=?`,
+		eventSourceId,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query checkpoints of Event Source %s: %v", eventSourceId, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := make(map[string]string)
+	for rows.Next() {
+		var shardId, sequenceNumber string
+		err = rows.Scan(&shardId, &sequenceNumber)
+		if err != nil {
+			msg := log.Error("Unable to scan checkpoint of Event Source %s: %v", eventSourceId, err)
+			return nil, errors.New(msg)
+		}
+		results[shardId] = sequenceNumber
+	}
+
+	return results, nil
+}
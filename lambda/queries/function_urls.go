@@ -0,0 +1,162 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/lambda/types"
+	"myaws/log"
+)
+
+const selectFunctionUrl = `SELECT id, url_id, function_name, qualifier, auth_type, cors, created_on, last_modified_on
+				FROM lambda_function_url`
+
+func InsertFunctionUrl(ctx context.Context, db *database.Database, url *types.FunctionUrl) error {
+	cors, err := corsToColumn(url.Cors)
+	if err != nil {
+		return err
+	}
+
+	url.ID, err = db.InsertOne(
+		ctx,
+		`INSERT INTO lambda_function_url (url_id, function_name, qualifier, auth_type, cors, created_on,
+					last_modified_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+		`,
+		url.UrlId,
+		url.FunctionName,
+		url.Qualifier,
+		url.AuthType,
+		cors,
+		url.CreationTime,
+		url.LastModifiedTime,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save URL of Function %s: %v", url.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func UpdateFunctionUrl(ctx context.Context, db *database.Database, url *types.FunctionUrl) error {
+	cors, err := corsToColumn(url.Cors)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`UPDATE lambda_function_url SET auth_type = ?, cors = ?, last_modified_on = ? WHERE id = ?`,
+		url.AuthType,
+		cors,
+		url.LastModifiedTime,
+		url.ID,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to update URL of Function %s: %v", url.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func DeleteFunctionUrl(ctx context.Context, db *database.Database, url *types.FunctionUrl) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM lambda_function_url WHERE id = ?`, url.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete URL of Function %s: %v", url.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// FunctionUrlByName returns the URL of the Function, or sql.ErrNoRows when it doesn't have one.
+func FunctionUrlByName(ctx context.Context, db *database.Database, name string, qualifier string) (*types.FunctionUrl, error) {
+	row := db.QueryRowContext(ctx, selectFunctionUrl+` WHERE function_name = ? AND qualifier = ?`, name, qualifier)
+	return scanFunctionUrl(row)
+}
+
+// FunctionUrlById returns the URL with the ID from its hostname, or sql.ErrNoRows when there is no such URL.
+func FunctionUrlById(ctx context.Context, db *database.Database, urlId string) (*types.FunctionUrl, error) {
+	row := db.QueryRowContext(ctx, selectFunctionUrl+` WHERE url_id = ?`, urlId)
+	return scanFunctionUrl(row)
+}
+
+// FunctionUrlsByName returns every URL of the Function, ordered by qualifier.
+func FunctionUrlsByName(ctx context.Context, db *database.Database, name string) ([]types.FunctionUrl, error) {
+	rows, err := db.QueryContext(ctx, selectFunctionUrl+` WHERE function_name = ? ORDER BY qualifier`, name)
+	if err != nil {
+		msg := log.Error("Unable to query URLs of Function %s: %v", name, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.FunctionUrl{}
+	for rows.Next() {
+		url, err := scanFunctionUrl(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *url)
+	}
+
+	return results, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFunctionUrl(row scanner) (*types.FunctionUrl, error) {
+	var url types.FunctionUrl
+	var cors string
+	err := row.Scan(
+		&url.ID,
+		&url.UrlId,
+		&url.FunctionName,
+		&url.Qualifier,
+		&url.AuthType,
+		&cors,
+		&url.CreationTime,
+		&url.LastModifiedTime,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan Function URL: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	if len(cors) > 0 {
+		url.Cors = &types.Cors{}
+		err = json.Unmarshal([]byte(cors), url.Cors)
+		if err != nil {
+			msg := log.Error("Unable to parse CORS of URL for Function %s: %v", url.FunctionName, err)
+			return nil, errors.New(msg)
+		}
+	}
+
+	return &url, nil
+}
+
+func corsToColumn(cors *types.Cors) (string, error) {
+	if cors == nil {
+		return "", nil
+	}
+
+	value, err := json.Marshal(cors)
+	if err != nil {
+		msg := log.Error("Unable to serialize CORS configuration %+v: %v", cors, err)
+		return "", errors.New(msg)
+	}
+
+	return string(value), nil
+}
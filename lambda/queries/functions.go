@@ -8,10 +8,11 @@ import (
 	"myaws/database"
 	"myaws/lambda/types"
 	"myaws/log"
+	"myaws/utils"
 )
 
 func LatestFunctionVersionByName(ctx context.Context, db *database.Database, name *string) (int, error) {
-	log.Info("Querying for Lambda Function %s ...", name)
+	log.Info("Querying for Lambda Function %s ...", *name)
 
 	var dbName string
 	var dbVersion int
@@ -29,7 +30,7 @@ func LatestFunctionVersionByName(ctx context.Context, db *database.Database, nam
 		log.Info("... not found, returning version = 0.")
 		return 0, nil
 	case err != nil:
-		msg := log.Error("error when querying function version for %s: %v", name, err)
+		msg := log.Error("error when querying function version for %s: %v", *name, err)
 		return -1, errors.New(msg)
 	}
 
@@ -39,6 +40,11 @@ func LatestFunctionVersionByName(ctx context.Context, db *database.Database, nam
 }
 
 func InsertFunction(ctx context.Context, db *database.Database, function *types.Function) (*types.Function, error) {
+	entryPoint, command, workingDirectory, err := imageConfigToColumns(function.ImageConfig)
+	if err != nil {
+		msg := log.Error("unable to serialize image config for function %s: %v", function.FunctionName, err)
+		return nil, errors.New(msg)
+	}
 
 	tx, err := db.BeginTx(ctx)
 	if err != nil {
@@ -50,8 +56,11 @@ func InsertFunction(ctx context.Context, db *database.Database, function *types.
 	functionId, err := tx.InsertOne(
 		ctx,
 		`INSERT INTO lambda_function (name, version, description, handler, role, dead_letter_arn,
-					memory_size, runtime, timeout, code_sha256, code_size, last_modified_on)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+					memory_size, runtime, timeout, code_sha256, code_size, last_modified_on, package_type,
+					image_uri, image_entry_point, image_command, image_working_directory, state, state_reason,
+					state_reason_code, last_update_status, last_update_status_reason,
+					last_update_status_reason_code, architecture)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 		function.FunctionName,
 		function.Version,
@@ -65,6 +74,18 @@ func InsertFunction(ctx context.Context, db *database.Database, function *types.
 		function.CodeSha256,
 		function.CodeSize,
 		function.LastModified,
+		function.PackageType,
+		function.ImageUri,
+		entryPoint,
+		command,
+		workingDirectory,
+		function.State,
+		utils.StringOrEmpty(function.StateReason),
+		function.StateReasonCode,
+		function.LastUpdateStatus,
+		utils.StringOrEmpty(function.LastUpdateStatusReason),
+		function.LastUpdateStatusReasonCode,
+		function.Architecture,
 	)
 
 	if err != nil {
@@ -116,7 +137,7 @@ func InsertFunction(ctx context.Context, db *database.Database, function *types.
 	for key, value := range function.Environment.Variables {
 		_, err := evnStmt.ExecContext(ctx, functionId, key, value)
 		if err != nil {
-			msg := tx.Rollback("unable to add environment %s to function %s: %v", key, err)
+			msg := tx.Rollback("unable to add environment %s to function %s: %v", key, function.FunctionName, err)
 			log.Error(msg)
 			return nil, errors.New(msg)
 		}
@@ -140,18 +161,21 @@ func InsertFunction(ctx context.Context, db *database.Database, function *types.
 		Timeout:                    function.Timeout,
 		CodeSha256:                 function.CodeSha256,
 		CodeSize:                   function.CodeSize,
+		Architecture:               function.Architecture,
 		Environment:                function.Environment,
 		Tags:                       function.Tags,
 		LastModified:               function.LastModified,
-		LastUpdateStatus:           "",
-		LastUpdateStatusReason:     nil,
-		LastUpdateStatusReasonCode: "",
-		Layers:                     nil,
-		PackageType:                "",
+		LastUpdateStatus:           function.LastUpdateStatus,
+		LastUpdateStatusReason:     function.LastUpdateStatusReason,
+		LastUpdateStatusReasonCode: function.LastUpdateStatusReasonCode,
+		Layers:                     function.Layers,
+		PackageType:                function.PackageType,
+		ImageUri:                   function.ImageUri,
+		ImageConfig:                function.ImageConfig,
 		RevisionId:                 nil,
-		State:                      "",
-		StateReason:                nil,
-		StateReasonCode:            "",
+		State:                      function.State,
+		StateReason:                function.StateReason,
+		StateReasonCode:            function.StateReasonCode,
 		Version:                    function.Version,
 	}
 
@@ -162,10 +186,14 @@ func LatestFunctionByName(ctx context.Context, db *database.Database, name strin
 	log.Info("Querying for Latest Function %s ... ", name)
 
 	var function types.Function
+	var entryPoint, command, workingDirectory, stateReason, updateReason string
 	err := db.QueryRowContext(
 		ctx,
 		`SELECT id, name, version, description, handler, role, dead_letter_arn, memory_size,
-					runtime, timeout, code_sha256, code_size, last_modified_on
+					runtime, timeout, code_sha256, code_size, last_modified_on, package_type, image_uri,
+					image_entry_point, image_command, image_working_directory, state, state_reason,
+					state_reason_code, last_update_status, last_update_status_reason,
+					last_update_status_reason_code, architecture
 				FROM lambda_function WHERE name = ? ORDER BY version DESC LIMIT 1`,
 		name,
 	).Scan(
@@ -182,6 +210,18 @@ func LatestFunctionByName(ctx context.Context, db *database.Database, name strin
 		&function.CodeSha256,
 		&function.CodeSize,
 		&function.LastModified,
+		&function.PackageType,
+		&function.ImageUri,
+		&entryPoint,
+		&command,
+		&workingDirectory,
+		&function.State,
+		&stateReason,
+		&function.StateReasonCode,
+		&function.LastUpdateStatus,
+		&updateReason,
+		&function.LastUpdateStatusReasonCode,
+		&function.Architecture,
 	)
 
 	switch {
@@ -193,6 +233,15 @@ func LatestFunctionByName(ctx context.Context, db *database.Database, name strin
 		return nil, errors.New(msg)
 	}
 
+	function.ImageConfig, err = imageConfigFromColumns(entryPoint, command, workingDirectory)
+	if err != nil {
+		msg := log.Error("... unable to parse image config for Function %s: %v", name, err)
+		return nil, errors.New(msg)
+	}
+
+	function.StateReason = stringOrNil(stateReason)
+	function.LastUpdateStatusReason = stringOrNil(updateReason)
+
 	log.Info("Found Function: %+v", function)
 	log.Info("Setting Function %s version to $LATEST", name)
 
@@ -246,7 +295,10 @@ func FunctionVersionsByName(ctx context.Context, db *database.Database, name str
 	rows, err := db.QueryContext(
 		ctx,
 		`SELECT id, name, version, description, handler, role, dead_letter_arn, memory_size,
-					runtime, timeout, code_sha256, code_size, last_modified_on
+					runtime, timeout, code_sha256, code_size, last_modified_on, package_type, image_uri,
+					image_entry_point, image_command, image_working_directory, state, state_reason,
+					state_reason_code, last_update_status, last_update_status_reason,
+					last_update_status_reason_code, architecture
 				FROM lambda_function WHERE name = ?`,
 		name,
 	)
@@ -258,6 +310,7 @@ func FunctionVersionsByName(ctx context.Context, db *database.Database, name str
 
 	for rows.Next() {
 		var function types.Function
+		var entryPoint, command, workingDirectory, stateReason, updateReason string
 		err := rows.Scan(
 			&function.ID,
 			&function.FunctionName,
@@ -272,6 +325,18 @@ func FunctionVersionsByName(ctx context.Context, db *database.Database, name str
 			&function.CodeSha256,
 			&function.CodeSize,
 			&function.LastModified,
+			&function.PackageType,
+			&function.ImageUri,
+			&entryPoint,
+			&command,
+			&workingDirectory,
+			&function.State,
+			&stateReason,
+			&function.StateReasonCode,
+			&function.LastUpdateStatus,
+			&updateReason,
+			&function.LastUpdateStatusReasonCode,
+			&function.Architecture,
 		)
 
 		if err != nil {
@@ -280,6 +345,16 @@ func FunctionVersionsByName(ctx context.Context, db *database.Database, name str
 			return nil, errors.New(msg)
 		}
 
+		function.ImageConfig, err = imageConfigFromColumns(entryPoint, command, workingDirectory)
+		if err != nil {
+			progress := len(results)
+			msg := log.Error("Error when parsing image config of row %d for Function %s: %v", progress, name, err)
+			return nil, errors.New(msg)
+		}
+
+		function.StateReason = stringOrNil(stateReason)
+		function.LastUpdateStatusReason = stringOrNil(updateReason)
+
 		environment, err := GetEnvironmentForFunction(ctx, db, &function)
 		if err != nil {
 			progress := len(results)
@@ -333,47 +408,112 @@ func GetLayersForFunction(ctx context.Context, db *database.Database, function *
 	return layers, nil
 }
 
-func LatestFunctions(ctx context.Context, db *database.Database) ([]types.Function, error) {
-	log.Info("Querying for latest version of all Functions ...")
+// UpdateFunctionLayers replaces the Layers referenced by the Function.
+func UpdateFunctionLayers(ctx context.Context, db *database.Database, function *types.Function, layers []types.LambdaLayer) error {
+	log.Info("Updating Layers for Function %s ...", function.FunctionName)
 
-	rows, err := db.QueryContext(
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to change Layers for Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM lambda_function_layer WHERE function_id = ?`, function.ID)
+	if err != nil {
+		msg := tx.Rollback("Unable to remove Layers from Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	for _, layer := range layers {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO lambda_function_layer (function_id, layer_name, layer_version) VALUES (?, ?, ?)`,
+			function.ID,
+			layer.Name,
+			layer.Version,
+		)
+
+		if err != nil {
+			msg := tx.Rollback("Unable to add Layer %s to Function %s: %v", layer.Name, function.FunctionName, err)
+			return errors.New(msg)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit Layer changes for Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// UpdateFunctionState saves the state & last update status of the Function.
+func UpdateFunctionState(ctx context.Context, db *database.Database, function *types.Function) error {
+	log.Info("Setting Function %s state to %s (last update %s)", function.FunctionName, function.State,
+		function.LastUpdateStatus)
+
+	_, err := db.ExecContext(
 		ctx,
-		`SELECT name, max(version), runtime, handler FROM lambda_function GROUP BY name`,
+		`UPDATE lambda_function SET state = ?, state_reason = ?, state_reason_code = ?, last_update_status = ?,
+					last_update_status_reason = ?, last_update_status_reason_code = ?
+				WHERE id = ?`,
+		function.State,
+		utils.StringOrEmpty(function.StateReason),
+		function.StateReasonCode,
+		function.LastUpdateStatus,
+		utils.StringOrEmpty(function.LastUpdateStatusReason),
+		function.LastUpdateStatusReasonCode,
+		function.ID,
 	)
 
-	var results []types.Function
-
-	switch {
-	case err == sql.ErrNoRows:
-		log.Error("No Functions were found")
-		return results, nil
-	case err != nil:
-		msg := log.Error("Unable to find Functions: %v", err)
-		return results, errors.New(msg)
+	if err != nil {
+		msg := log.Error("Unable to save state of Function %s: %v", function.FunctionName, err)
+		return errors.New(msg)
 	}
 
-	for rows.Next() {
-		var function types.Function
+	return nil
+}
 
-		err := rows.Scan(
-			&function.FunctionName,
-			&function.Version,
-			&function.Runtime,
-			&function.Handler,
-		)
+// InterruptedFunctionNames returns the names of the Functions whose latest version is still being created or updated,
+// which can only be the case when myaws stopped before it finished.
+func InterruptedFunctionNames(ctx context.Context, db *database.Database) ([]string, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT f.name FROM lambda_function f
+				WHERE f.version = (SELECT max(l.version) FROM lambda_function l WHERE l.name = f.name)
+					AND (f.state = ? OR f.last_update_status = ?)`,
+		aws.StatePending,
+		aws.LastUpdateStatusInProgress,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query interrupted Functions: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
 
+	results := []string{}
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
 		if err != nil {
-			msg := log.Error("Unable to scan Function row #%d", len(results))
-			return results, errors.New(msg)
+			msg := log.Error("Unable to scan interrupted Function: %v", err)
+			return nil, errors.New(msg)
 		}
-
-		results = append(results, function)
+		results = append(results, name)
 	}
 
-	log.Info("... found %d Functions.", len(results))
 	return results, nil
 }
 
+func stringOrNil(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return &value
+}
+
 func UpsertFunctionEnvironment(ctx context.Context, db *database.Database, function *types.Function, environment *aws.Environment) error {
 	log.Info("Upserting Environment for Function %s ...", function.FunctionName)
 
@@ -422,7 +562,7 @@ func UpsertFunctionEnvironment(ctx context.Context, db *database.Database, funct
 	for key, value := range adds {
 		_, err = addStmt.ExecContext(ctx, function.ID, key, value)
 		if err != nil {
-			msg := tx.Rollback("Unable to add Environment %s=%s to Function %s: %v", key, value, function.ID, err)
+			msg := tx.Rollback("Unable to add Environment %s=%s to Function %d: %v", key, value, function.ID, err)
 			return errors.New(msg)
 		}
 	}
@@ -430,7 +570,7 @@ func UpsertFunctionEnvironment(ctx context.Context, db *database.Database, funct
 	for key, value := range updates {
 		_, err = updateStmt.ExecContext(ctx, value, function.ID, key)
 		if err != nil {
-			msg := tx.Rollback("Unable to update Environment %s=%s for Function %s: %v", key, value, function.ID, err)
+			msg := tx.Rollback("Unable to update Environment %s=%s for Function %d: %v", key, value, function.ID, err)
 			return errors.New(msg)
 		}
 	}
@@ -438,7 +578,7 @@ func UpsertFunctionEnvironment(ctx context.Context, db *database.Database, funct
 	for key, value := range removes {
 		_, err = removeStmt.ExecContext(ctx, function.ID, key)
 		if err != nil {
-			msg := tx.Rollback("Unable to update Environment %s=%s for Function %s: %v", key, value, function.ID, err)
+			msg := tx.Rollback("Unable to update Environment %s=%s for Function %d: %v", key, value, function.ID, err)
 			return errors.New(msg)
 		}
 	}
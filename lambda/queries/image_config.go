@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"encoding/json"
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"myaws/utils"
+)
+
+// imageConfigToColumns serializes the lists in an ImageConfig as JSON so that they can be stored in text columns.
+func imageConfigToColumns(config *aws.ImageConfig) (entryPoint string, command string, workingDirectory string, err error) {
+	if config == nil {
+		return
+	}
+
+	if config.EntryPoint != nil {
+		var b []byte
+		b, err = json.Marshal(config.EntryPoint)
+		if err != nil {
+			return
+		}
+		entryPoint = string(b)
+	}
+
+	if config.Command != nil {
+		var b []byte
+		b, err = json.Marshal(config.Command)
+		if err != nil {
+			return
+		}
+		command = string(b)
+	}
+
+	workingDirectory = utils.StringOrEmpty(config.WorkingDirectory)
+	return
+}
+
+func imageConfigFromColumns(entryPoint string, command string, workingDirectory string) (*aws.ImageConfig, error) {
+	if len(entryPoint) == 0 && len(command) == 0 && len(workingDirectory) == 0 {
+		return nil, nil
+	}
+
+	config := aws.ImageConfig{}
+	if len(entryPoint) > 0 {
+		err := json.Unmarshal([]byte(entryPoint), &config.EntryPoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(command) > 0 {
+		err := json.Unmarshal([]byte(command), &config.Command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(workingDirectory) > 0 {
+		config.WorkingDirectory = &workingDirectory
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,146 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"myaws/database"
+	"myaws/lambda/types"
+	"myaws/log"
+	"strings"
+)
+
+func InsertInvocation(ctx context.Context, db *database.Database, invocation types.Invocation) error {
+	_, err := db.InsertOne(
+		ctx,
+		`INSERT INTO lambda_invocation (function_name, version, request_id, source, payload, response, status_code,
+					function_error, error_type, error, duration_ms, init_duration_ms, invoked_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+		invocation.FunctionName,
+		invocation.Version,
+		invocation.RequestId,
+		invocation.Source,
+		invocation.Payload,
+		invocation.Response,
+		invocation.StatusCode,
+		invocation.FunctionError,
+		invocation.ErrorType,
+		invocation.Error,
+		invocation.DurationMs,
+		invocation.InitDurationMs,
+		invocation.InvokedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save invocation %s of Function %s: %v", invocation.RequestId,
+			invocation.FunctionName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// Invocations returns the Invocations matching the filter, newest first.
+func Invocations(ctx context.Context, db *database.Database, filter types.InvocationFilter) ([]types.Invocation, error) {
+	conditions, args := invocationConditions(filter)
+	query := `SELECT id, function_name, version, request_id, source, payload, response, status_code, function_error,
+					error_type, error, duration_ms, init_duration_ms, invoked_on
+				FROM lambda_invocation` + conditions + ` ORDER BY id DESC`
+
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		msg := log.Error("Unable to query invocations: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Invocation{}
+	for rows.Next() {
+		var invocation types.Invocation
+		var invokedOn int64
+		err := rows.Scan(
+			&invocation.ID,
+			&invocation.FunctionName,
+			&invocation.Version,
+			&invocation.RequestId,
+			&invocation.Source,
+			&invocation.Payload,
+			&invocation.Response,
+			&invocation.StatusCode,
+			&invocation.FunctionError,
+			&invocation.ErrorType,
+			&invocation.Error,
+			&invocation.DurationMs,
+			&invocation.InitDurationMs,
+			&invokedOn,
+		)
+
+		if err != nil {
+			msg := log.Error("Unable to scan invocation: %v", err)
+			return nil, errors.New(msg)
+		}
+
+		invocation.SetInvokedOn(invokedOn)
+		results = append(results, invocation)
+	}
+
+	// most recent were selected first, so that Limit keeps them
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+
+	return results, nil
+}
+
+// DeleteInvocations forgets the Invocations matching the filter.
+func DeleteInvocations(ctx context.Context, db *database.Database, filter types.InvocationFilter) error {
+	conditions, args := invocationConditions(filter)
+	_, err := db.ExecContext(ctx, `DELETE FROM lambda_invocation`+conditions, args...)
+	if err != nil {
+		msg := log.Error("Unable to delete invocations: %v", err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func invocationConditions(filter types.InvocationFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(filter.FunctionName) > 0 {
+		conditions = append(conditions, "function_name = ?")
+		args = append(args, filter.FunctionName)
+	}
+
+	if len(filter.Source) > 0 {
+		conditions = append(conditions, "source = ?")
+		args = append(args, filter.Source)
+	}
+
+	if len(filter.RequestId) > 0 {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, filter.RequestId)
+	}
+
+	if len(filter.Payload) > 0 {
+		conditions = append(conditions, "instr(payload, ?) > 0")
+		args = append(args, filter.Payload)
+	}
+
+	if filter.Since > 0 {
+		conditions = append(conditions, "invoked_on >= ?")
+		args = append(args, filter.Since)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
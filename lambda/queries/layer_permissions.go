@@ -0,0 +1,156 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/docker/distribution/uuid"
+	"myaws/database"
+	"myaws/lambda/types"
+	"myaws/log"
+)
+
+func LayerPolicyRevision(ctx context.Context, db *database.Database, layer types.LambdaLayer) (string, error) {
+	var revision string
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT policy_revision_id FROM lambda_layer WHERE id = ?`,
+		layer.ID,
+	).Scan(&revision)
+
+	if err != nil {
+		return "", fmt.Errorf("problem querying policy revision of layer %s:%d: %v", layer.Name, layer.Version, err)
+	}
+
+	return revision, nil
+}
+
+func LayerPermissions(ctx context.Context, db *database.Database, layer types.LambdaLayer) ([]types.LayerPermission, error) {
+	var results []types.LayerPermission
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT id, lambda_layer_id, statement_id, action, principal, organization_id
+				FROM lambda_layer_permission
+				WHERE lambda_layer_id = ?
+				ORDER BY id
+		`,
+		layer.ID,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem querying permissions of layer %s:%d: %v", layer.Name, layer.Version, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission types.LayerPermission
+		err := rows.Scan(&permission.ID, &permission.LayerID, &permission.StatementId, &permission.Action,
+			&permission.Principal, &permission.OrganizationId)
+
+		if err != nil {
+			return results, fmt.Errorf("problem parsing permissions of layer %s:%d: %v", layer.Name,
+				layer.Version, err)
+		}
+
+		results = append(results, permission)
+	}
+
+	return results, nil
+}
+
+// InsertLayerPermission adds the permission to the Layer version's policy and returns the policy's new revision.
+func InsertLayerPermission(ctx context.Context, db *database.Database, layer types.LambdaLayer, permission types.LayerPermission) (string, error) {
+	log.Info("Adding permission %s to Lambda Layer %s:%d", permission.StatementId, layer.Name, layer.Version)
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to create transaction to add permission to layer %s: %v", layer.Name, err)
+	}
+
+	_, err = tx.InsertOne(
+		ctx,
+		`INSERT INTO lambda_layer_permission (lambda_layer_id, statement_id, action, principal, organization_id)
+					VALUES (?, ?, ?, ?, ?)
+		`,
+		layer.ID,
+		permission.StatementId,
+		permission.Action,
+		permission.Principal,
+		permission.OrganizationId,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("unable to insert permission %s for layer %s: %v", permission.StatementId, layer.Name, err)
+		return "", fmt.Errorf(msg)
+	}
+
+	revision, err := updatePolicyRevision(ctx, tx, layer)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", fmt.Errorf("unable to commit permission for layer %s: %v", layer.Name, err)
+	}
+
+	return revision, nil
+}
+
+// DeleteLayerPermission removes the statement from the Layer version's policy. sql.ErrNoRows is returned when the
+// policy has no such statement.
+func DeleteLayerPermission(ctx context.Context, db *database.Database, layer types.LambdaLayer, statementId string) error {
+	log.Info("Removing permission %s from Lambda Layer %s:%d", statementId, layer.Name, layer.Version)
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create transaction to remove permission from layer %s: %v", layer.Name, err)
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM lambda_layer_permission WHERE lambda_layer_id = ? AND statement_id = ?`,
+		layer.ID,
+		statementId,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("unable to remove permission %s from layer %s: %v", statementId, layer.Name, err)
+		return fmt.Errorf(msg)
+	}
+
+	count, err := result.RowsAffected()
+	if err == nil && count == 0 {
+		tx.Rollback("permission %s not found for layer %s", statementId, layer.Name)
+		return sql.ErrNoRows
+	}
+
+	_, err = updatePolicyRevision(ctx, tx, layer)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit removing permission from layer %s: %v", layer.Name, err)
+	}
+
+	return nil
+}
+
+func updatePolicyRevision(ctx context.Context, tx *database.Transaction, layer types.LambdaLayer) (string, error) {
+	revision := uuid.Generate().String()
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE lambda_layer SET policy_revision_id = ? WHERE id = ?`,
+		revision,
+		layer.ID,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("unable to update policy revision for layer %s: %v", layer.Name, err)
+		return "", fmt.Errorf(msg)
+	}
+
+	return revision, nil
+}
@@ -12,8 +12,10 @@ import (
 	"time"
 )
 
+// the latest version of each layer is kept apart from its versions, so that deleting the latest version doesn't
+// make the next one reuse its number
 const queryLatestVersionByLayerName = `
-SELECT name, max(version) from lambda_layer where name = ?
+SELECT name, version from lambda_layer_latest_version where name = ?
 `
 
 func InsertLayer(ctx context.Context, db *database.Database, layer types.LambdaLayer, dbRuntimes *map[aws.Runtime]int) (*types.LambdaLayer, error) {
@@ -64,6 +66,39 @@ func InsertLayer(ctx context.Context, db *database.Database, layer types.LambdaL
 		}
 	}
 
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO lambda_layer_latest_version (name, version) VALUES (?, ?)
+				ON CONFLICT(name) DO UPDATE SET version = max(version, excluded.version)
+		`,
+		layer.Name,
+		layer.Version,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("unable to save latest version of layer %s: %v", layer.Name, err)
+		return nil, fmt.Errorf(msg)
+	}
+
+	architectureStmt, err := tx.PrepareContext(
+		ctx,
+		`INSERT INTO lambda_layer_architecture (lambda_layer_id, architecture) VALUES (?, ?)`,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("unable to prepare statement for inserting layer architectures for %s: %v", layer.Name, err)
+		return nil, fmt.Errorf(msg)
+	}
+	defer architectureStmt.Close()
+
+	for _, architecture := range layer.CompatibleArchitectures {
+		_, err := architectureStmt.ExecContext(ctx, layerId, architecture)
+		if err != nil {
+			msg := tx.Rollback("unable to insert architecture %s for layer %s: %v", architecture, layer.Name, err)
+			return nil, fmt.Errorf(msg)
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, fmt.Errorf("unable to commit layer %s: %v", layer.Name, err)
@@ -78,6 +113,8 @@ func InsertLayer(ctx context.Context, db *database.Database, layer types.LambdaL
 		CompatibleRuntimes: layer.CompatibleRuntimes,
 		CodeSize:           layer.CodeSize,
 		CodeSha256:         layer.CodeSha256,
+
+		CompatibleArchitectures: layer.CompatibleArchitectures,
 	}
 
 	return &result, nil
@@ -86,13 +123,17 @@ func InsertLayer(ctx context.Context, db *database.Database, layer types.LambdaL
 func LayerByName(ctx context.Context, db *database.Database, name string) ([]types.LambdaLayer, error) {
 	var results []types.LambdaLayer
 	rows, err := db.QueryContext(
-		ctx, `SELECT ll.id, ll.name, ll.description, ll.version, ll.created_on, GROUP_CONCAT(r.name) AS runtimes,
+		ctx, `SELECT ll.id, ll.name, ll.description, ll.version, ll.created_on,
+						IFNULL(GROUP_CONCAT(r.name), '') AS runtimes,
+						(SELECT IFNULL(GROUP_CONCAT(lla.architecture), '') FROM lambda_layer_architecture lla
+							WHERE lla.lambda_layer_id = ll.id) AS architectures,
     					ll.code_size, ll.code_sha256
-					FROM lambda_runtime AS r
-					INNER JOIN lambda_layer_runtime llr ON r.id = llr.lambda_runtime_id
-					INNER JOIN lambda_layer ll ON llr.lambda_layer_id = ll.id
+					FROM lambda_layer AS ll
+					LEFT JOIN lambda_layer_runtime llr ON ll.id = llr.lambda_layer_id
+					LEFT JOIN lambda_runtime r ON r.id = llr.lambda_runtime_id
 					WHERE ll.name = ?
-					GROUP BY llr.lambda_layer_id;
+					GROUP BY ll.id
+					ORDER BY ll.version DESC;
 		`,
 		name,
 	)
@@ -107,9 +148,9 @@ func LayerByName(ctx context.Context, db *database.Database, name string) ([]typ
 	for rows.Next() {
 		var result types.LambdaLayer
 		var createdOn int64
-		var runtimes string
+		var runtimes, architectures string
 		err := rows.Scan(&result.ID, &result.Name, &result.Description, &result.Version, &createdOn, &runtimes,
-			&result.CodeSize, &result.CodeSha256)
+			&architectures, &result.CodeSize, &result.CodeSha256)
 
 		if err != nil {
 			return results, fmt.Errorf("problem parsing results when querying all versions for layer %s: %v", name, err)
@@ -117,6 +158,7 @@ func LayerByName(ctx context.Context, db *database.Database, name string) ([]typ
 
 		result.CreatedOn = time.UnixMilli(createdOn).Format("2006-01-02T15:04:05.999-0700")
 		result.CompatibleRuntimes = stringToRuntimes(runtimes)
+		result.CompatibleArchitectures = stringToArchitectures(architectures)
 
 		log.Info("got row when querying lambda layer %s: %+v", name, result)
 		results = append(results, result)
@@ -129,19 +171,22 @@ func LayerByName(ctx context.Context, db *database.Database, name string) ([]typ
 func LayerByNameAndVersion(ctx context.Context, db *database.Database, name string, version int) (types.LambdaLayer, error) {
 	var result types.LambdaLayer
 	var createdOn int64
-	var runtimes string
+	var runtimes, architectures string
 
 	log.Info("Querying for Layer by Name and Version: %s / %d ...", name, version)
 
 	err := db.QueryRowContext(
 		ctx,
-		`SELECT ll.id, ll.name, ll.description, ll.version, ll.created_on, GROUP_CONCAT(r.name) AS runtimes,
+		`SELECT ll.id, ll.name, ll.description, ll.version, ll.created_on,
+					IFNULL(GROUP_CONCAT(r.name), '') AS runtimes,
+					(SELECT IFNULL(GROUP_CONCAT(lla.architecture), '') FROM lambda_layer_architecture lla
+						WHERE lla.lambda_layer_id = ll.id) AS architectures,
        				ll.code_size, ll.code_sha256
-				FROM lambda_runtime AS r
-				INNER JOIN lambda_layer_runtime llr ON r.id = llr.lambda_runtime_id
-				INNER JOIN lambda_layer ll ON llr.lambda_layer_id = ll.id
+				FROM lambda_layer AS ll
+				LEFT JOIN lambda_layer_runtime llr ON ll.id = llr.lambda_layer_id
+				LEFT JOIN lambda_runtime r ON r.id = llr.lambda_runtime_id
 				WHERE ll.name = ? AND ll.version = ?
-				GROUP BY llr.lambda_layer_id;
+				GROUP BY ll.id;
 		`,
 		name,
 		version,
@@ -152,10 +197,15 @@ func LayerByNameAndVersion(ctx context.Context, db *database.Database, name stri
 		&result.Version,
 		&createdOn,
 		&runtimes,
+		&architectures,
 		&result.CodeSize,
 		&result.CodeSha256,
 	)
 
+	if err == sql.ErrNoRows {
+		return result, err
+	}
+
 	if err != nil {
 		return result, fmt.Errorf("problem parsing results when querying version %d for layer %s: %v",
 			version, name, err)
@@ -163,12 +213,17 @@ func LayerByNameAndVersion(ctx context.Context, db *database.Database, name stri
 
 	result.CreatedOn = time.UnixMilli(createdOn).Format("2006-01-02T15:04:05.999-0700")
 	result.CompatibleRuntimes = stringToRuntimes(runtimes)
+	result.CompatibleArchitectures = stringToArchitectures(architectures)
 
 	return result, nil
 }
 
 func stringToRuntimes(runtime string) []aws.Runtime {
 	log.Debug("converting %s to list of runtimes", runtime)
+	if len(runtime) == 0 {
+		return []aws.Runtime{}
+	}
+
 	split := strings.Split(runtime, ",")
 	runtimes := make([]aws.Runtime, len(split))
 	for i, value := range split {
@@ -178,6 +233,103 @@ func stringToRuntimes(runtime string) []aws.Runtime {
 	return runtimes
 }
 
+func stringToArchitectures(architecture string) []aws.Architecture {
+	if len(architecture) == 0 {
+		return []aws.Architecture{}
+	}
+
+	split := strings.Split(architecture, ",")
+	architectures := make([]aws.Architecture, len(split))
+	for i, value := range split {
+		architectures[i] = aws.Architecture(value)
+	}
+
+	return architectures
+}
+
+func LayerNames(ctx context.Context, db *database.Database) ([]string, error) {
+	var results []string
+	rows, err := db.QueryContext(ctx, `SELECT DISTINCT name FROM lambda_layer ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("problem querying for layer names: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return results, fmt.Errorf("problem parsing results when querying layer names: %v", err)
+		}
+
+		results = append(results, name)
+	}
+
+	return results, nil
+}
+
+// FunctionsUsingLayer returns the names of Functions that have a version referencing the Layer version.
+func FunctionsUsingLayer(ctx context.Context, db *database.Database, layer types.LambdaLayer) ([]string, error) {
+	var results []string
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT DISTINCT lf.name FROM lambda_function_layer AS lfl
+					JOIN lambda_function AS lf ON lfl.function_id = lf.id
+				WHERE lfl.layer_name = ? AND lfl.layer_version = ?
+		`,
+		layer.Name,
+		layer.Version,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("problem querying functions using layer %s:%d: %v", layer.Name, layer.Version, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return results, fmt.Errorf("problem parsing functions using layer %s:%d: %v", layer.Name,
+				layer.Version, err)
+		}
+
+		results = append(results, name)
+	}
+
+	return results, nil
+}
+
+func DeleteLayer(ctx context.Context, db *database.Database, layer types.LambdaLayer) error {
+	log.Info("Deleting Lambda Layer %s version %d", layer.Name, layer.Version)
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create transaction to delete layer %s: %v", layer.Name, err)
+	}
+
+	statements := []string{
+		`DELETE FROM lambda_layer_runtime WHERE lambda_layer_id = ?`,
+		`DELETE FROM lambda_layer_architecture WHERE lambda_layer_id = ?`,
+		`DELETE FROM lambda_layer_permission WHERE lambda_layer_id = ?`,
+		`DELETE FROM lambda_layer WHERE id = ?`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.ExecContext(ctx, statement, layer.ID)
+		if err != nil {
+			msg := tx.Rollback("unable to delete layer %s version %d: %v", layer.Name, layer.Version, err)
+			return fmt.Errorf(msg)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("unable to commit deleting layer %s: %v", layer.Name, err)
+	}
+
+	return nil
+}
+
 func LatestLayerByName(ctx context.Context, db *database.Database, name string) (int, error) {
 	var dbName sql.NullString
 	var dbVersion sql.NullInt32
@@ -28,7 +28,7 @@ func RuntimeExistsByName(ctx context.Context, db *database.Database, runtime aws
 		return false, errors.New(msg)
 	}
 
-	log.Info("... found %s with id=%s", name, id)
+	log.Info("... found %s with id=%d", name, id)
 	return true, nil
 }
 
@@ -0,0 +1,257 @@
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/docker/distribution/uuid"
+	"io"
+	"myaws/log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const runtimeApiPrefix = "/2018-06-01/runtime/"
+
+type invocationResult struct {
+	payload   []byte
+	errorType string
+}
+
+type invocation struct {
+	requestId string
+	payload   []byte
+	deadline  time.Time
+	result    chan invocationResult
+}
+
+// RuntimeApi implements the Lambda Runtime API for a single Function, so that any runtime's bootstrap can poll
+// myaws for invocations directly.
+type RuntimeApi struct {
+	functionName string
+	functionArn  string
+	timeout      time.Duration
+
+	pending chan *invocation
+
+	mutex     sync.Mutex
+	inFlight  map[string]*invocation
+	initError *invocationResult
+
+	port   int
+	server *http.Server
+}
+
+func NewRuntimeApi(functionName string, functionArn string, timeout time.Duration) *RuntimeApi {
+	return &RuntimeApi{
+		functionName: functionName,
+		functionArn:  functionArn,
+		timeout:      timeout,
+		pending:      make(chan *invocation),
+		inFlight:     make(map[string]*invocation),
+	}
+}
+
+// Listen starts serving the Runtime API on the given port in the background.
+func (api *RuntimeApi) Listen(port int) error {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		msg := log.Error("Unable to listen on port %d for Runtime API of Function %s: %v", port, api.functionName, err)
+		return errors.New(msg)
+	}
+
+	api.port = port
+	api.server = &http.Server{Handler: api}
+	go func() {
+		e := api.server.Serve(listener)
+		if e != nil && e != http.ErrServerClosed {
+			log.Error("Runtime API for Function %s stopped: %v", api.functionName, e)
+		}
+	}()
+
+	log.Info("Runtime API for Function %s listening on port %d", api.functionName, port)
+	return nil
+}
+
+func (api *RuntimeApi) Close(ctx context.Context) error {
+	if api.server == nil {
+		return nil
+	}
+
+	return api.server.Shutdown(ctx)
+}
+
+// Invoke queues the payload for the Function's runtime and waits until it responds, reports an error, or the
+// Function's timeout elapses.
+func (api *RuntimeApi) Invoke(ctx context.Context, requestId string, payload []byte) (*InvokeResult, error) {
+	api.mutex.Lock()
+	initError := api.initError
+	api.mutex.Unlock()
+
+	// like Lambda, callers are only told the Function failed, while its error type is in the payload
+	if initError != nil {
+		return &InvokeResult{
+			RequestId:     requestId,
+			StatusCode:    http.StatusOK,
+			Payload:       initError.payload,
+			FunctionError: functionErrorUnhandled,
+		}, nil
+	}
+
+	inv := &invocation{
+		requestId: requestId,
+		payload:   payload,
+		deadline:  time.Now().Add(api.timeout),
+		result:    make(chan invocationResult, 1),
+	}
+
+	api.mutex.Lock()
+	api.inFlight[requestId] = inv
+	api.mutex.Unlock()
+
+	timer := time.NewTimer(api.timeout)
+	defer timer.Stop()
+
+	select {
+	case api.pending <- inv:
+	case <-timer.C:
+		api.forget(requestId)
+		return api.timedOut(requestId), nil
+	case <-ctx.Done():
+		api.forget(requestId)
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-inv.result:
+		invokeResult := InvokeResult{RequestId: requestId, StatusCode: http.StatusOK, Payload: result.payload}
+		if len(result.errorType) > 0 {
+			invokeResult.FunctionError = functionErrorUnhandled
+		}
+		return &invokeResult, nil
+	case <-timer.C:
+		api.forget(requestId)
+		return api.timedOut(requestId), nil
+	case <-ctx.Done():
+		api.forget(requestId)
+		return nil, ctx.Err()
+	}
+}
+
+// forget drops an invocation that is no longer waited for, so that a late result from the runtime is rejected.
+func (api *RuntimeApi) forget(requestId string) {
+	api.mutex.Lock()
+	delete(api.inFlight, requestId)
+	api.mutex.Unlock()
+}
+
+func (api *RuntimeApi) timedOut(requestId string) *InvokeResult {
+	message := fmt.Sprintf("%s Task timed out after %.2f seconds", requestId, api.timeout.Seconds())
+	log.Error("Function %s: %s", api.functionName, message)
+	payload, _ := json.Marshal(map[string]string{"errorMessage": message})
+	return &InvokeResult{
+		RequestId:     requestId,
+		StatusCode:    http.StatusOK,
+		Payload:       payload,
+		FunctionError: functionErrorUnhandled,
+	}
+}
+
+func (api *RuntimeApi) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	path := strings.TrimPrefix(request.URL.Path, runtimeApiPrefix)
+	parts := strings.Split(path, "/")
+
+	switch {
+	case request.Method == http.MethodGet && path == "invocation/next":
+		api.next(response, request)
+	case request.Method == http.MethodPost && len(parts) == 3 && parts[0] == "invocation" && parts[2] == "response":
+		api.complete(response, request, parts[1], "")
+	case request.Method == http.MethodPost && len(parts) == 3 && parts[0] == "invocation" && parts[2] == "error":
+		errorType := request.Header.Get("Lambda-Runtime-Function-Error-Type")
+		if len(errorType) == 0 {
+			errorType = functionErrorUnhandled
+		}
+		api.complete(response, request, parts[1], errorType)
+	case request.Method == http.MethodPost && path == "init/error":
+		api.initFailed(response, request)
+	default:
+		log.Error("Runtime API for Function %s cannot handle %s %s", api.functionName, request.Method,
+			request.URL.Path)
+		http.NotFound(response, request)
+	}
+}
+
+func (api *RuntimeApi) next(response http.ResponseWriter, request *http.Request) {
+	var inv *invocation
+	select {
+	case inv = <-api.pending:
+	case <-request.Context().Done():
+		return
+	}
+
+	traceId := "Root=1-" + strconv.FormatInt(time.Now().Unix(), 16) + "-" + strings.ReplaceAll(uuid.Generate().String(), "-", "")[:24]
+
+	header := response.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("Lambda-Runtime-Aws-Request-Id", inv.requestId)
+	header.Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixMilli(), 10))
+	header.Set("Lambda-Runtime-Invoked-Function-Arn", api.functionArn)
+	header.Set("Lambda-Runtime-Trace-Id", traceId)
+
+	response.WriteHeader(http.StatusOK)
+	response.Write(inv.payload)
+}
+
+func (api *RuntimeApi) complete(response http.ResponseWriter, request *http.Request, requestId string, errorType string) {
+	payload, err := io.ReadAll(request.Body)
+	if err != nil {
+		msg := log.Error("Unable to read result of invocation %s for Function %s: %v", requestId,
+			api.functionName, err)
+		http.Error(response, msg, http.StatusInternalServerError)
+		return
+	}
+
+	api.mutex.Lock()
+	inv, ok := api.inFlight[requestId]
+	delete(api.inFlight, requestId)
+	api.mutex.Unlock()
+
+	if !ok {
+		log.Error("Function %s reported a result for unknown invocation %s", api.functionName, requestId)
+		respondWithRuntimeApiStatus(response, http.StatusBadRequest, "InvalidRequestID")
+		return
+	}
+
+	if len(errorType) > 0 {
+		log.Info("Function %s reported error %s for invocation %s", api.functionName, errorType, requestId)
+	}
+
+	inv.result <- invocationResult{payload: payload, errorType: errorType}
+	respondWithRuntimeApiStatus(response, http.StatusAccepted, "OK")
+}
+
+func (api *RuntimeApi) initFailed(response http.ResponseWriter, request *http.Request) {
+	payload, _ := io.ReadAll(request.Body)
+	errorType := request.Header.Get("Lambda-Runtime-Function-Error-Type")
+	if len(errorType) == 0 {
+		errorType = functionErrorUnhandled
+	}
+	log.Error("Function %s failed to initialize (%s): %s", api.functionName, errorType, payload)
+
+	api.mutex.Lock()
+	api.initError = &invocationResult{payload: payload, errorType: errorType}
+	api.mutex.Unlock()
+
+	respondWithRuntimeApiStatus(response, http.StatusAccepted, "OK")
+}
+
+func respondWithRuntimeApiStatus(response http.ResponseWriter, statusCode int, status string) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(map[string]string{"status": status})
+}
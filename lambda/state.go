@@ -0,0 +1,137 @@
+package lambda
+
+import (
+	"context"
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"myaws/database"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+)
+
+const (
+	stateReasonCreating = "The function is being created."
+	stateReasonUpdating = "The function is being updated."
+)
+
+// detachedContext returns a context with the same settings as ctx that isn't cancelled along with it, for work that
+// outlives the request that started it.
+func detachedContext(ctx context.Context) context.Context {
+	cfg := settings.FromContext(ctx)
+	return cfg.NewContext(context.Background())
+}
+
+// markCreating sets the state of a Function that hasn't been started yet.
+func markCreating(function *types.Function) {
+	reason := stateReasonCreating
+	function.State = aws.StatePending
+	function.StateReason = &reason
+	function.StateReasonCode = aws.StateReasonCodeCreating
+	function.LastUpdateStatus = aws.LastUpdateStatusInProgress
+	function.LastUpdateStatusReason = nil
+	function.LastUpdateStatusReasonCode = ""
+}
+
+// markUpdating sets the last update status of a Function whose changes are being applied.
+func markUpdating(function *types.Function) {
+	reason := stateReasonUpdating
+	function.LastUpdateStatus = aws.LastUpdateStatusInProgress
+	function.LastUpdateStatusReason = &reason
+	function.LastUpdateStatusReasonCode = ""
+}
+
+// ActivateFunction gets a new version of, or changes to, the Function ready, and records whether it became Active or
+// Failed. A running Function is restarted with them, while others start on their next invocation. The Function is only
+// marked Failed when it can't be prepared: failing to restart it is reported by the next invocation instead, which
+// tries to start it again.
+func ActivateFunction(ctx context.Context, function *types.Function) error {
+	err := prepareFunction(ctx, function)
+	if err != nil {
+		markFailed(function, err)
+	} else {
+		markActive(function)
+	}
+
+	saveFunctionState(ctx, function)
+	if err != nil {
+		return err
+	}
+
+	manager := getManager()
+	lock := manager.startLock(function.FunctionName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if !manager.isRunning(function.FunctionName) {
+		return StopFunction(ctx, function.FunctionName)
+	}
+
+	err = RestartFunction(ctx, function)
+	if err != nil {
+		log.Error("Unable to restart Function %s with its changes: %v", function.FunctionName, err)
+		StopFunction(ctx, function.FunctionName)
+	}
+
+	return nil
+}
+
+// RecoverFunctions activates the Functions whose creation or update was interrupted when myaws last stopped, since
+// they would otherwise stay Pending or InProgress, rejecting invocations & updates for good.
+func RecoverFunctions(ctx context.Context) {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	names, err := queries.InterruptedFunctionNames(ctx, db)
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		function, err := runnableFunction(ctx, db, name)
+		if err != nil {
+			continue
+		}
+
+		log.Info("Activating Function %s again, since myaws stopped before it was %s", name, aws.StateActive)
+		go ActivateFunction(ctx, function)
+	}
+}
+
+func markActive(function *types.Function) {
+	function.State = aws.StateActive
+	function.StateReason = nil
+	function.StateReasonCode = ""
+	function.LastUpdateStatus = aws.LastUpdateStatusSuccessful
+	function.LastUpdateStatusReason = nil
+	function.LastUpdateStatusReasonCode = ""
+}
+
+func markFailed(function *types.Function, err error) {
+	reason := err.Error()
+	stateCode := aws.StateReasonCodeInternalError
+	updateCode := aws.LastUpdateStatusReasonCodeInternalError
+	if function.IsImage() {
+		stateCode = aws.StateReasonCodeInvalidImage
+		updateCode = aws.LastUpdateStatusReasonCodeInvalidImage
+	}
+
+	function.State = aws.StateFailed
+	function.StateReason = &reason
+	function.StateReasonCode = stateCode
+	function.LastUpdateStatus = aws.LastUpdateStatusFailed
+	function.LastUpdateStatusReason = &reason
+	function.LastUpdateStatusReasonCode = updateCode
+}
+
+func saveFunctionState(ctx context.Context, function *types.Function) {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	err := queries.UpdateFunctionState(ctx, db, function)
+	if err != nil {
+		log.Error("Function %s is %s but that couldn't be saved: %v", function.FunctionName, function.State, err)
+	}
+}
@@ -0,0 +1,265 @@
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/dynamodb"
+	dynamodbTypes "myaws/dynamodb/types"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/settings"
+	"time"
+)
+
+const (
+	streamPollInterval  = time.Second
+	streamRetryInterval = time.Second
+)
+
+// streamPoller delivers the records of a DynamoDB stream to the Function of an event source, a batch at a time &
+// in order within each shard.
+type streamPoller struct {
+	eventSource *types.EventSource
+
+	// initialShards existed when the poller started & are read from StartingPosition, while shards created later
+	// are read from their beginning. Shards with a checkpoint are read after it instead.
+	initialShards map[string]bool
+	iterators     map[string]string
+	finished      map[string]bool
+
+	// sequenceNumbers are the checkpoints of the shards, the last record of each that was delivered.
+	sequenceNumbers map[string]string
+}
+
+func startStreamPoller(ctx context.Context, eventSource *types.EventSource) error {
+	log.Info("Starting consumption from Stream %s ...", eventSource.Arn)
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	checkpoints, err := queries.Checkpoints(ctx, db, eventSource.UUID.String())
+	db.Close()
+	if err != nil {
+		return err
+	}
+
+	poller := &streamPoller{
+		eventSource:     eventSource,
+		initialShards:   make(map[string]bool),
+		iterators:       make(map[string]string),
+		finished:        make(map[string]bool),
+		sequenceNumbers: checkpoints,
+	}
+
+	// the stream is described before polling so that a missing stream fails the start of the event source
+	shards, err := poller.shards(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, shard := range shards {
+		poller.initialShards[shard.ShardId] = true
+	}
+
+	go func() {
+		for {
+			poller.poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamPollInterval):
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (poller *streamPoller) shards(ctx context.Context) ([]dynamodbTypes.Shard, error) {
+	input := dynamodbTypes.DescribeStreamInput{StreamArn: poller.eventSource.Arn}
+	var output dynamodbTypes.DescribeStreamOutput
+	err := dynamodb.StreamsRequest(ctx, "DescribeStream", input, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.StreamDescription.Shards, nil
+}
+
+// shardIterator returns an iterator that continues after the last record delivered from the shard.
+func (poller *streamPoller) shardIterator(ctx context.Context, shard dynamodbTypes.Shard) (string, error) {
+	input := dynamodbTypes.GetShardIteratorInput{
+		StreamArn:         poller.eventSource.Arn,
+		ShardId:           shard.ShardId,
+		ShardIteratorType: dynamodbTypes.ShardIteratorTypeTrimHorizon,
+	}
+
+	if sequenceNumber, ok := poller.sequenceNumbers[shard.ShardId]; ok {
+		input.ShardIteratorType = dynamodbTypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = sequenceNumber
+	} else if poller.initialShards[shard.ShardId] {
+		input.ShardIteratorType = poller.eventSource.StartingPosition
+	}
+
+	var output dynamodbTypes.GetShardIteratorOutput
+	err := dynamodb.StreamsRequest(ctx, "GetShardIterator", input, &output)
+	return output.ShardIterator, err
+}
+
+// poll delivers the records added to each shard since the last poll. Child shards are only read once their parent
+// has been read to its end, so that the changes to an item are delivered in order.
+func (poller *streamPoller) poll(ctx context.Context) {
+	shards, err := poller.shards(ctx)
+	if err != nil {
+		return
+	}
+
+	known := make(map[string]bool, len(shards))
+	for _, shard := range shards {
+		known[shard.ShardId] = true
+	}
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	for _, shard := range shards {
+		parent := shard.ParentShardId
+		if poller.finished[shard.ShardId] || (len(parent) > 0 && known[parent] && !poller.finished[parent]) {
+			continue
+		}
+
+		poller.readShard(ctx, db, shard)
+	}
+}
+
+// readShard delivers batches of records from the shard until it has no more records for now, saving a checkpoint
+// after each batch so that a restarted poller continues where this one stopped.
+func (poller *streamPoller) readShard(ctx context.Context, db *database.Database, shard dynamodbTypes.Shard) {
+	for ctx.Err() == nil {
+		iterator, ok := poller.iterators[shard.ShardId]
+		if !ok {
+			var err error
+			iterator, err = poller.shardIterator(ctx, shard)
+			if err != nil {
+				return
+			}
+		}
+
+		input := dynamodbTypes.GetRecordsInput{ShardIterator: iterator, Limit: poller.eventSource.BatchSize}
+		var output dynamodbTypes.GetRecordsOutput
+		err := dynamodb.StreamsRequest(ctx, "GetRecords", input, &output)
+		if err != nil {
+			// iterators expire, so the next poll gets a new one after the last delivered record
+			delete(poller.iterators, shard.ShardId)
+			return
+		}
+
+		if len(output.Records) > 0 {
+			poller.deliver(ctx, output.Records)
+			if ctx.Err() != nil {
+				return
+			}
+
+			sequenceNumber := output.Records[len(output.Records)-1].SequenceNumber()
+			poller.sequenceNumbers[shard.ShardId] = sequenceNumber
+			queries.SaveCheckpoint(ctx, db, poller.eventSource.UUID.String(), shard.ShardId, sequenceNumber)
+		}
+
+		if len(output.NextShardIterator) == 0 {
+			log.Info("Finished reading shard %s of Stream %s", shard.ShardId, poller.eventSource.Arn)
+			delete(poller.iterators, shard.ShardId)
+			poller.finished[shard.ShardId] = true
+			return
+		}
+
+		poller.iterators[shard.ShardId] = output.NextShardIterator
+		if len(output.Records) < int(poller.eventSource.BatchSize) {
+			return
+		}
+	}
+}
+
+// deliver invokes the Function with the records of the DynamoDB stream, with retries & bisecting of failing batches.
+func (poller *streamPoller) deliver(ctx context.Context, records []dynamodbTypes.StreamRecord) {
+	deliverBatch(ctx, poller.eventSource, len(records), func(start int, end int) error {
+		return poller.invoke(ctx, records[start:end])
+	})
+}
+
+func (poller *streamPoller) invoke(ctx context.Context, records []dynamodbTypes.StreamRecord) error {
+	cfg := settings.FromContext(ctx)
+	eventSource := poller.eventSource
+
+	event := types.DynamoDBEvent{Records: make([]dynamodbTypes.StreamRecord, len(records))}
+	for i, record := range records {
+		record.AwsRegion = cfg.Region
+		record.EventSourceARN = eventSource.Arn
+		event.Records[i] = record
+	}
+
+	_, err := invokeForEventSource(ctx, eventSource, event, len(records))
+	return err
+}
+
+// deliverBatch invokes the Function of a stream's event source with the batch of records until it succeeds or runs
+// out of retries, with invoke being given the range of records to invoke with. When bisecting is enabled, a failing
+// batch is split in two halves that are delivered separately, so that a bad record ends up retried on its own.
+func deliverBatch(ctx context.Context, eventSource *types.EventSource, size int, invoke func(start int, end int) error) {
+	var deliver func(start int, end int)
+	deliver = func(start int, end int) {
+		for retries := int32(0); ; retries++ {
+			err := invoke(start, end)
+			if err == nil {
+				return
+			}
+
+			if eventSource.BisectBatchOnFunctionError && end-start > 1 {
+				middle := start + (end-start)/2
+				deliver(start, middle)
+				deliver(middle, end)
+				return
+			}
+
+			if eventSource.MaximumRetryAttempts >= 0 && retries >= eventSource.MaximumRetryAttempts {
+				log.Error("Discarding %d records of %s after %d retries", end-start, eventSource.Arn, retries)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamRetryInterval):
+			}
+		}
+	}
+
+	deliver(0, size)
+}
+
+// invokeForEventSource invokes the Function of the event source with the event, which has the number of records,
+// returning an error when the Function fails to handle them.
+func invokeForEventSource(ctx context.Context, eventSource *types.EventSource, event interface{}, count int) (*InvokeResult, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		msg := log.Error("Unable to encode records of %s: %v", eventSource.Arn, err)
+		return nil, errors.New(msg)
+	}
+
+	name := eventSource.Function.FunctionName
+	result, err := Invoke(WithInvocationSource(ctx, eventSource.UUID.String()), name, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.FunctionError) > 0 {
+		msg := log.Error("Function %s failed to handle %d records of %s: %s", name, count, eventSource.Arn,
+			result.Payload)
+		return nil, errors.New(msg)
+	}
+
+	return result, nil
+}
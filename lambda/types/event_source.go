@@ -2,29 +2,72 @@ package types
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/docker/distribution/uuid"
+	dynamodbTypes "myaws/dynamodb/types"
+	"strings"
 	"time"
 )
 
 type EventSource struct {
-	ID           int64
-	UUID         uuid.UUID
-	Enabled      bool
-	Arn          string
-	Function     *Function
-	BatchSize    int32
-	LastModified int64
+	ID                         int64
+	UUID                       uuid.UUID
+	Enabled                    bool
+	Arn                        string
+	Function                   *Function
+	BatchSize                  int32
+	StartingPosition           string
+	BisectBatchOnFunctionError bool
+	MaximumRetryAttempts       int32
+	ParallelizationFactor      int32
+	TumblingWindowInSeconds    int32
+	LastModified               int64
+}
+
+// Service returns the service of the event source's ARN, such as sqs or dynamodb.
+func (eventSource EventSource) Service() string {
+	parts := strings.Split(eventSource.Arn, ":")
+	if len(parts) < 3 {
+		return ""
+	}
+
+	return parts[2]
+}
+
+// streamSettings are the settings of an event source that only apply to streams, which are nil for queues.
+type streamSettings struct {
+	bisect           *bool
+	retries          *int32
+	parallelization  *int32
+	tumblingWindow   *int32
+	startingPosition aws.EventSourcePosition
+}
+
+func (eventSource EventSource) streamSettings() streamSettings {
+	if eventSource.Service() == "sqs" {
+		return streamSettings{}
+	}
+
+	return streamSettings{
+		bisect:           &eventSource.BisectBatchOnFunctionError,
+		retries:          &eventSource.MaximumRetryAttempts,
+		parallelization:  &eventSource.ParallelizationFactor,
+		tumblingWindow:   &eventSource.TumblingWindowInSeconds,
+		startingPosition: aws.EventSourcePosition(eventSource.StartingPosition),
+	}
 }
 
 func (eventSource EventSource) ToCreateEventSourceMappingOutput(ctx context.Context) lambda.CreateEventSourceMappingOutput {
 	id := eventSource.UUID.String()
 	lastModified := time.UnixMilli(eventSource.LastModified)
 	state := "Enabled"
+	stream := eventSource.streamSettings()
 
 	return lambda.CreateEventSourceMappingOutput{
 		BatchSize:                      &eventSource.BatchSize,
-		BisectBatchOnFunctionError:     nil,
+		BisectBatchOnFunctionError:     stream.bisect,
 		DestinationConfig:              nil,
 		EventSourceArn:                 &eventSource.Arn,
 		FilterCriteria:                 nil,
@@ -34,17 +77,17 @@ func (eventSource EventSource) ToCreateEventSourceMappingOutput(ctx context.Cont
 		LastProcessingResult:           nil,
 		MaximumBatchingWindowInSeconds: nil,
 		MaximumRecordAgeInSeconds:      nil,
-		MaximumRetryAttempts:           nil,
-		ParallelizationFactor:          nil,
+		MaximumRetryAttempts:           stream.retries,
+		ParallelizationFactor:          stream.parallelization,
 		Queues:                         nil,
 		SelfManagedEventSource:         nil,
 		SourceAccessConfigurations:     nil,
-		StartingPosition:               "",
+		StartingPosition:               stream.startingPosition,
 		StartingPositionTimestamp:      nil,
 		State:                          &state,
 		StateTransitionReason:          nil,
 		Topics:                         nil,
-		TumblingWindowInSeconds:        nil,
+		TumblingWindowInSeconds:        stream.tumblingWindow,
 		UUID:                           &id,
 	}
 }
@@ -53,10 +96,11 @@ func (eventSource EventSource) ToGetEventSourceMappingOutput(ctx context.Context
 	id := eventSource.UUID.String()
 	lastModified := time.UnixMilli(eventSource.LastModified)
 	state := "Enabled"
+	stream := eventSource.streamSettings()
 
 	return lambda.GetEventSourceMappingOutput{
 		BatchSize:                      &eventSource.BatchSize,
-		BisectBatchOnFunctionError:     nil,
+		BisectBatchOnFunctionError:     stream.bisect,
 		DestinationConfig:              nil,
 		EventSourceArn:                 &eventSource.Arn,
 		FilterCriteria:                 nil,
@@ -66,17 +110,62 @@ func (eventSource EventSource) ToGetEventSourceMappingOutput(ctx context.Context
 		LastProcessingResult:           nil,
 		MaximumBatchingWindowInSeconds: nil,
 		MaximumRecordAgeInSeconds:      nil,
-		MaximumRetryAttempts:           nil,
-		ParallelizationFactor:          nil,
+		MaximumRetryAttempts:           stream.retries,
+		ParallelizationFactor:          stream.parallelization,
 		Queues:                         nil,
 		SelfManagedEventSource:         nil,
 		SourceAccessConfigurations:     nil,
-		StartingPosition:               "",
+		StartingPosition:               stream.startingPosition,
 		StartingPositionTimestamp:      nil,
 		State:                          &state,
 		StateTransitionReason:          nil,
 		Topics:                         nil,
-		TumblingWindowInSeconds:        nil,
+		TumblingWindowInSeconds:        stream.tumblingWindow,
 		UUID:                           &id,
 	}
 }
+
+// DynamoDBEvent is what Functions receive from the stream of a DynamoDB table.
+type DynamoDBEvent struct {
+	Records []dynamodbTypes.StreamRecord
+}
+
+// KinesisEvent is what Functions receive from a Kinesis stream.
+type KinesisEvent struct {
+	Records []KinesisEventRecord
+}
+
+// KinesisTimeWindowEvent is what Functions receive from a Kinesis stream when their event source has tumbling
+// windows. The state is what the Function returned for the previous batch of the window.
+type KinesisTimeWindowEvent struct {
+	Records                 []KinesisEventRecord
+	Window                  KinesisTimeWindow `json:"window"`
+	State                   json.RawMessage   `json:"state"`
+	ShardId                 string            `json:"shardId"`
+	EventSourceARN          string            `json:"eventSourceARN"`
+	IsFinalInvokeForWindow  bool              `json:"isFinalInvokeForWindow"`
+	IsWindowTerminatedEarly bool              `json:"isWindowTerminatedEarly"`
+}
+
+type KinesisTimeWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type KinesisEventRecord struct {
+	Kinesis        KinesisRecord `json:"kinesis"`
+	EventSource    string        `json:"eventSource"`
+	EventVersion   string        `json:"eventVersion"`
+	EventID        string        `json:"eventID"`
+	EventName      string        `json:"eventName"`
+	AwsRegion      string        `json:"awsRegion"`
+	EventSourceARN string        `json:"eventSourceARN"`
+}
+
+type KinesisRecord struct {
+	KinesisSchemaVersion        string  `json:"kinesisSchemaVersion"`
+	PartitionKey                string  `json:"partitionKey"`
+	SequenceNumber              string  `json:"sequenceNumber"`
+	Data                        []byte  `json:"data"`
+	ApproximateArrivalTimestamp float64 `json:"approximateArrivalTimestamp"`
+}
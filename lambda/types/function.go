@@ -25,6 +25,9 @@ type Function struct {
 	CodeSha256    string
 	CodeSize      int64
 
+	// The instruction set architecture that the function supports, x86_64 unless arm64 was requested.
+	Architecture aws.Architecture
+
 	Environment *aws.Environment
 	Tags        map[string]string
 
@@ -49,6 +52,12 @@ type Function struct {
 	// .zip file archive.
 	PackageType aws.PackageType
 
+	// The URI of the container image when PackageType is Image.
+	ImageUri string
+
+	// Overrides for the container image's ENTRYPOINT, CMD and WORKDIR.
+	ImageConfig *aws.ImageConfig
+
 	// The latest updated revision of the function or alias.
 	RevisionId *string
 
@@ -78,11 +87,16 @@ func CreateFunction(input *lambda.CreateFunctionInput) *Function {
 		layers[i] = LayerFromArn(layer)
 	}
 
+	packageType := input.PackageType
+	if len(packageType) == 0 {
+		packageType = aws.PackageTypeZip
+	}
+
 	return &Function{
 		FunctionName:  *input.FunctionName,
 		Role:          *input.Role,
 		Description:   utils.StringOrEmpty(input.Description),
-		Handler:       *input.Handler,
+		Handler:       utils.StringOrEmpty(input.Handler),
 		DeadLetterArn: deadLetterArn,
 		Layers:        layers,
 		MemorySize:    utils.Int32OrDefault(input.MemorySize, 128),
@@ -91,14 +105,46 @@ func CreateFunction(input *lambda.CreateFunctionInput) *Function {
 		Environment:   EnvironmentOrEmpty(input.Environment),
 		Tags:          input.Tags,
 		LastModified:  time.Now().UnixMilli(),
+		PackageType:   packageType,
+		ImageConfig:   input.ImageConfig,
+		Architecture:  ArchitectureOf(input.Architectures),
+	}
+}
+
+// ArchitectureOf returns the architecture a Function is created for, which defaults to x86_64.
+func ArchitectureOf(architectures []aws.Architecture) aws.Architecture {
+	if len(architectures) == 0 {
+		return aws.ArchitectureX8664
+	}
+
+	return architectures[0]
+}
+
+func (f *Function) architectures() []aws.Architecture {
+	if len(f.Architecture) == 0 {
+		return nil
+	}
+
+	return []aws.Architecture{f.Architecture}
+}
+
+func (f *Function) IsImage() bool {
+	return f.PackageType == aws.PackageTypeImage
+}
+
+func (f *Function) imageConfigResponse() *aws.ImageConfigResponse {
+	if f.ImageConfig == nil {
+		return nil
 	}
+
+	return &aws.ImageConfigResponse{ImageConfig: f.ImageConfig}
 }
 
 func (f Function) ToCreateFunctionOutput(ctx context.Context) *lambda.CreateFunctionOutput {
 	lastModified := time.UnixMilli(f.LastModified).Format(timeFormat)
 
 	return &lambda.CreateFunctionOutput{
-		Architectures:    nil,
+		Architectures:    f.architectures(),
 		CodeSha256:       &f.CodeSha256,
 		CodeSize:         f.CodeSize,
 		DeadLetterConfig: &aws.DeadLetterConfig{TargetArn: &f.DeadLetterArn},
@@ -111,24 +157,24 @@ func (f Function) ToCreateFunctionOutput(ctx context.Context) *lambda.CreateFunc
 		FunctionArn:                nil,
 		FunctionName:               &f.FunctionName,
 		Handler:                    &f.Handler,
-		ImageConfigResponse:        nil,
+		ImageConfigResponse:        f.imageConfigResponse(),
 		KMSKeyArn:                  nil,
 		LastModified:               &lastModified,
-		LastUpdateStatus:           "",
-		LastUpdateStatusReason:     nil,
-		LastUpdateStatusReasonCode: "",
+		LastUpdateStatus:           f.LastUpdateStatus,
+		LastUpdateStatusReason:     f.LastUpdateStatusReason,
+		LastUpdateStatusReasonCode: f.LastUpdateStatusReasonCode,
 		Layers:                     layersToAws(f.Layers, ctx),
 		MasterArn:                  nil,
 		MemorySize:                 &f.MemorySize,
-		PackageType:                "Zip",
+		PackageType:                f.PackageType,
 		RevisionId:                 nil,
 		Role:                       &f.Role,
 		Runtime:                    f.Runtime,
 		SigningJobArn:              nil,
 		SigningProfileVersionArn:   nil,
-		State:                      aws.StateActive,
-		StateReason:                nil,
-		StateReasonCode:            "",
+		State:                      f.State,
+		StateReason:                f.StateReason,
+		StateReasonCode:            f.StateReasonCode,
 		Timeout:                    &f.Timeout,
 		TracingConfig:              nil,
 		Version:                    &f.Version,
@@ -156,7 +202,7 @@ func (f *Function) ToFunctionConfiguration(ctx context.Context) *aws.FunctionCon
 		environment = &aws.EnvironmentResponse{Variables: f.Environment.Variables}
 	}
 	return &aws.FunctionConfiguration{
-		Architectures:              nil,
+		Architectures:              f.architectures(),
 		CodeSha256:                 &f.CodeSha256,
 		CodeSize:                   f.CodeSize,
 		DeadLetterConfig:           nil,
@@ -166,24 +212,24 @@ func (f *Function) ToFunctionConfiguration(ctx context.Context) *aws.FunctionCon
 		FunctionArn:                f.GetArn(ctx),
 		FunctionName:               &f.FunctionName,
 		Handler:                    &f.Handler,
-		ImageConfigResponse:        nil,
+		ImageConfigResponse:        f.imageConfigResponse(),
 		KMSKeyArn:                  nil,
 		LastModified:               &lastModified,
-		LastUpdateStatus:           "",
-		LastUpdateStatusReason:     nil,
-		LastUpdateStatusReasonCode: "",
+		LastUpdateStatus:           f.LastUpdateStatus,
+		LastUpdateStatusReason:     f.LastUpdateStatusReason,
+		LastUpdateStatusReasonCode: f.LastUpdateStatusReasonCode,
 		Layers:                     layers,
 		MasterArn:                  nil,
 		MemorySize:                 &f.MemorySize,
-		PackageType:                "Zip",
+		PackageType:                f.PackageType,
 		RevisionId:                 nil,
 		Role:                       &f.Role,
 		Runtime:                    f.Runtime,
 		SigningJobArn:              nil,
 		SigningProfileVersionArn:   nil,
-		State:                      aws.StateActive,
-		StateReason:                nil,
-		StateReasonCode:            "",
+		State:                      f.State,
+		StateReason:                f.StateReason,
+		StateReasonCode:            f.StateReasonCode,
 		Timeout:                    &f.Timeout,
 		TracingConfig:              nil,
 		Version:                    &f.Version,
@@ -194,6 +240,12 @@ func (f *Function) ToFunctionConfiguration(ctx context.Context) *aws.FunctionCon
 func (f *Function) ToGetFunctionOutput(ctx context.Context) *lambda.GetFunctionOutput {
 	config := f.ToFunctionConfiguration(ctx)
 	code := aws.FunctionCodeLocation{}
+	if f.IsImage() {
+		repositoryType := "ECR"
+		code.ImageUri = &f.ImageUri
+		code.ResolvedImageUri = &f.ImageUri
+		code.RepositoryType = &repositoryType
+	}
 	one := int32(-1)
 	concurrency := aws.Concurrency{ReservedConcurrentExecutions: &one}
 	return &lambda.GetFunctionOutput{
@@ -218,7 +270,7 @@ func (f *Function) ToUpdateFunctionConfigurationOutput(ctx context.Context) *lam
 	}
 
 	return &lambda.UpdateFunctionConfigurationOutput{
-		Architectures:              nil,
+		Architectures:              f.architectures(),
 		CodeSha256:                 &f.CodeSha256,
 		CodeSize:                   f.CodeSize,
 		DeadLetterConfig:           nil,
@@ -228,24 +280,24 @@ func (f *Function) ToUpdateFunctionConfigurationOutput(ctx context.Context) *lam
 		FunctionArn:                f.GetArn(ctx),
 		FunctionName:               &f.FunctionName,
 		Handler:                    &f.Handler,
-		ImageConfigResponse:        nil,
+		ImageConfigResponse:        f.imageConfigResponse(),
 		KMSKeyArn:                  nil,
 		LastModified:               &lastModified,
-		LastUpdateStatus:           aws.LastUpdateStatusSuccessful,
-		LastUpdateStatusReason:     nil,
-		LastUpdateStatusReasonCode: "",
+		LastUpdateStatus:           f.LastUpdateStatus,
+		LastUpdateStatusReason:     f.LastUpdateStatusReason,
+		LastUpdateStatusReasonCode: f.LastUpdateStatusReasonCode,
 		Layers:                     layers,
 		MasterArn:                  nil,
 		MemorySize:                 &f.MemorySize,
-		PackageType:                "Zip",
+		PackageType:                f.PackageType,
 		RevisionId:                 nil,
 		Role:                       &f.Role,
 		Runtime:                    f.Runtime,
 		SigningJobArn:              nil,
 		SigningProfileVersionArn:   nil,
-		State:                      aws.StateActive,
-		StateReason:                nil,
-		StateReasonCode:            "",
+		State:                      f.State,
+		StateReason:                f.StateReason,
+		StateReasonCode:            f.StateReasonCode,
 		Timeout:                    &f.Timeout,
 		TracingConfig:              nil,
 		Version:                    &f.Version,
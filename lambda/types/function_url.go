@@ -0,0 +1,97 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"myaws/settings"
+	"strings"
+)
+
+const (
+	FunctionUrlAuthTypeIam  = "AWS_IAM"
+	FunctionUrlAuthTypeNone = "NONE"
+)
+
+// Cors is the cross-origin resource sharing configuration of a Function URL. The version of the SDK used by myaws
+// predates Function URLs, so these types mirror the API's JSON.
+type Cors struct {
+	AllowCredentials bool     `json:",omitempty"`
+	AllowHeaders     []string `json:",omitempty"`
+	AllowMethods     []string `json:",omitempty"`
+	AllowOrigins     []string `json:",omitempty"`
+	ExposeHeaders    []string `json:",omitempty"`
+	MaxAge           int32    `json:",omitempty"`
+}
+
+// AllowsOrigin reports whether browsers on the origin may call the Function URL.
+func (cors Cors) AllowsOrigin(origin string) bool {
+	return containsFold(cors.AllowOrigins, origin)
+}
+
+// AllowsMethod reports whether browsers may call the Function URL with the HTTP method.
+func (cors Cors) AllowsMethod(method string) bool {
+	return containsFold(cors.AllowMethods, method)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, allowed := range values {
+		if allowed == "*" || strings.EqualFold(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type FunctionUrlConfigInput struct {
+	AuthType string
+	Cors     *Cors
+}
+
+type FunctionUrl struct {
+	ID               int64
+	UrlId            string
+	FunctionName     string
+	Qualifier        string
+	AuthType         string
+	Cors             *Cors
+	CreationTime     int64
+	LastModifiedTime int64
+}
+
+type FunctionUrlConfig struct {
+	AuthType         string
+	Cors             *Cors `json:",omitempty"`
+	CreationTime     string
+	FunctionArn      string
+	FunctionUrl      string
+	LastModifiedTime string
+}
+
+// GetUrl returns the URL that invokes the Function. It uses a subdomain of localhost like Lambda uses a subdomain of
+// on.aws, which resolves to myaws without any DNS setup.
+func (url FunctionUrl) GetUrl(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	return fmt.Sprintf("%s://%s.lambda-url.%s.localhost:%d/", cfg.HTTP.Protocol, url.UrlId, cfg.Region, cfg.HTTP.Port)
+}
+
+func (url FunctionUrl) GetFunctionArn(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	arn := "arn:aws:lambda:" + cfg.ArnFragment() + ":function:" + url.FunctionName
+	if len(url.Qualifier) > 0 && url.Qualifier != "$LATEST" {
+		arn += ":" + url.Qualifier
+	}
+
+	return arn
+}
+
+func (url FunctionUrl) ToFunctionUrlConfig(ctx context.Context) FunctionUrlConfig {
+	return FunctionUrlConfig{
+		AuthType:         url.AuthType,
+		Cors:             url.Cors,
+		CreationTime:     timeMillisToString(url.CreationTime),
+		FunctionArn:      url.GetFunctionArn(ctx),
+		FunctionUrl:      url.GetUrl(ctx),
+		LastModifiedTime: timeMillisToString(url.LastModifiedTime),
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+// HttpEvent is the payload format version 2.0 event that Functions receive from their URL and from HTTP API
+// integrations.
+type HttpEvent struct {
+	Version               string             `json:"version"`
+	RouteKey              string             `json:"routeKey"`
+	RawPath               string             `json:"rawPath"`
+	RawQueryString        string             `json:"rawQueryString"`
+	Cookies               []string           `json:"cookies,omitempty"`
+	Headers               map[string]string  `json:"headers"`
+	QueryStringParameters map[string]string  `json:"queryStringParameters,omitempty"`
+	PathParameters        map[string]string  `json:"pathParameters,omitempty"`
+	StageVariables        map[string]string  `json:"stageVariables,omitempty"`
+	RequestContext        HttpRequestContext `json:"requestContext"`
+	Body                  string             `json:"body,omitempty"`
+	IsBase64Encoded       bool               `json:"isBase64Encoded"`
+}
+
+type HttpDescription struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Protocol  string `json:"protocol"`
+	SourceIp  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+type HttpRequestContext struct {
+	AccountId    string          `json:"accountId"`
+	ApiId        string          `json:"apiId"`
+	DomainName   string          `json:"domainName"`
+	DomainPrefix string          `json:"domainPrefix"`
+	Http         HttpDescription `json:"http"`
+	RequestId    string          `json:"requestId"`
+	RouteKey     string          `json:"routeKey"`
+	Stage        string          `json:"stage"`
+	Time         string          `json:"time"`
+	TimeEpoch    int64           `json:"timeEpoch"`
+}
+
+// HttpResponse is what Functions return for HttpEvents when the response includes a status code.
+type HttpResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Cookies         []string          `json:"cookies"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
@@ -0,0 +1,35 @@
+package types
+
+// Invocation is the record of a single invocation of a Function, kept so that tests can check what was invoked.
+type Invocation struct {
+	ID             int64 `json:"-"`
+	FunctionName   string
+	Version        string
+	RequestId      string
+	Source         string
+	Payload        string
+	Response       string
+	StatusCode     int
+	FunctionError  string `json:",omitempty"`
+	ErrorType      string `json:",omitempty"`
+	Error          string `json:",omitempty"`
+	DurationMs     float64
+	InitDurationMs float64 `json:",omitempty"`
+	InvokedOn      int64   `json:"-"`
+	InvokedAt      string
+}
+
+// InvocationFilter selects Invocations, where empty values match any Invocation.
+type InvocationFilter struct {
+	FunctionName string
+	Source       string
+	RequestId    string
+	Payload      string
+	Since        int64
+	Limit        int
+}
+
+func (invocation *Invocation) SetInvokedOn(ms int64) {
+	invocation.InvokedOn = ms
+	invocation.InvokedAt = timeMillisToString(ms)
+}
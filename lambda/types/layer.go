@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"myaws/settings"
@@ -19,6 +21,37 @@ type LambdaLayer struct {
 	CompatibleRuntimes []types.Runtime
 	CodeSize           int64
 	CodeSha256         string
+
+	CompatibleArchitectures []types.Architecture
+}
+
+// IsCompatible reports whether the Layer version declares that it is compatible with the runtime and architecture.
+// Empty values match any Layer, but Layers that don't declare what they are compatible with never match a value.
+func (layer LambdaLayer) IsCompatible(runtime types.Runtime, architecture types.Architecture) bool {
+	if len(runtime) > 0 && !containsRuntime(layer.CompatibleRuntimes, runtime) {
+		return false
+	}
+
+	if len(architecture) > 0 {
+		for _, compatible := range layer.CompatibleArchitectures {
+			if compatible == architecture {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func containsRuntime(runtimes []types.Runtime, runtime types.Runtime) bool {
+	for _, r := range runtimes {
+		if r == runtime {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (layer LambdaLayer) GetDestPath(ctx context.Context) string {
@@ -27,6 +60,19 @@ func (layer LambdaLayer) GetDestPath(ctx context.Context) string {
 	return filepath.Join(cfg.DataPath(), "lambda", "layers", layer.Name, fileName)
 }
 
+// GetCachePath returns the directory that the Layer's code is extracted into. It is keyed by the code's hash so that
+// identical Layer versions are only extracted once.
+func (layer LambdaLayer) GetCachePath(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	key := layer.CodeSha256
+	raw, err := base64.StdEncoding.DecodeString(layer.CodeSha256)
+	if err == nil {
+		key = hex.EncodeToString(raw)
+	}
+
+	return filepath.Join(cfg.DataPath(), "lambda", "layer-cache", key)
+}
+
 func (layer LambdaLayer) GetArn(ctx context.Context) *string {
 	cfg := settings.FromContext(ctx)
 	result := "arn:aws:lambda:" + cfg.Region + ":" + cfg.AccountNumber + ":layer:" + layer.Name
@@ -55,7 +101,7 @@ func LayerFromArn(arn string) LambdaLayer {
 
 func (layer LambdaLayer) ToPublishLayerVersionOutput(ctx context.Context) *lambda.PublishLayerVersionOutput {
 	return &lambda.PublishLayerVersionOutput{
-		CompatibleArchitectures: []types.Architecture{},
+		CompatibleArchitectures: layer.CompatibleArchitectures,
 		CompatibleRuntimes:      layer.CompatibleRuntimes,
 		Content: &types.LayerVersionContentOutput{
 			CodeSize:   layer.CodeSize,
@@ -72,7 +118,7 @@ func (layer LambdaLayer) ToPublishLayerVersionOutput(ctx context.Context) *lambd
 
 func (layer LambdaLayer) ToLayerVersionsListItem(ctx context.Context) types.LayerVersionsListItem {
 	return types.LayerVersionsListItem{
-		CompatibleArchitectures: []types.Architecture{},
+		CompatibleArchitectures: layer.CompatibleArchitectures,
 		CompatibleRuntimes:      layer.CompatibleRuntimes,
 		CreatedDate:             &layer.CreatedOn,
 		Description:             &layer.Description,
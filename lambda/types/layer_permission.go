@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/json"
+)
+
+const (
+	layerPolicyVersion = "2012-10-17"
+	layerPolicyId      = "default"
+)
+
+// LayerPermission is a statement in the resource-based policy of a Layer version, granting an account (or every
+// account with "*") usage of the version.
+type LayerPermission struct {
+	ID             int64
+	LayerID        int64
+	StatementId    string
+	Action         string
+	Principal      string
+	OrganizationId string
+}
+
+// Allows reports whether the account, which belongs to the organization when organizationId isn't empty, is granted
+// usage of the Layer version.
+func (permission LayerPermission) Allows(account string, organizationId string) bool {
+	if permission.Principal != "*" && permission.Principal != account {
+		return false
+	}
+
+	return len(permission.OrganizationId) == 0 || permission.OrganizationId == organizationId
+}
+
+type policyStatement struct {
+	Sid       string
+	Effect    string
+	Principal interface{}
+	Action    string
+	Resource  string
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+type policyDocument struct {
+	Version   string
+	Id        string
+	Statement []policyStatement
+}
+
+func (permission LayerPermission) toStatement(resource string) policyStatement {
+	var principal interface{} = "*"
+	if permission.Principal != "*" {
+		principal = map[string]string{"AWS": "arn:aws:iam::" + permission.Principal + ":root"}
+	}
+
+	statement := policyStatement{
+		Sid:       permission.StatementId,
+		Effect:    "Allow",
+		Principal: principal,
+		Action:    permission.Action,
+		Resource:  resource,
+	}
+
+	if len(permission.OrganizationId) > 0 {
+		statement.Condition = map[string]map[string]string{
+			"StringEquals": {"aws:PrincipalOrgID": permission.OrganizationId},
+		}
+	}
+
+	return statement
+}
+
+// Statement returns the permission as a JSON policy statement for the Layer version ARN.
+func (permission LayerPermission) Statement(resource string) string {
+	value, _ := json.Marshal(permission.toStatement(resource))
+	return string(value)
+}
+
+// LayerPolicy returns the JSON policy document made up of the permissions for the Layer version ARN.
+func LayerPolicy(resource string, permissions []LayerPermission) string {
+	document := policyDocument{
+		Version:   layerPolicyVersion,
+		Id:        layerPolicyId,
+		Statement: make([]policyStatement, len(permissions)),
+	}
+
+	for i, permission := range permissions {
+		document.Statement[i] = permission.toStatement(resource)
+	}
+
+	value, _ := json.Marshal(document)
+	return string(value)
+}
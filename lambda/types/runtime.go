@@ -0,0 +1,118 @@
+package types
+
+import (
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"strings"
+)
+
+const (
+	runtimeImagePrefix = "mlupin/docker-lambda:"
+	runtimeBootstrap   = "/var/runtime/bootstrap"
+)
+
+const (
+	FamilyDotnet   = "dotnet"
+	FamilyGo       = "go"
+	FamilyJava     = "java"
+	FamilyNodejs   = "nodejs"
+	FamilyProvided = "provided"
+	FamilyPython   = "python"
+	FamilyRuby     = "ruby"
+)
+
+// Runtime describes how Functions using a particular AWS runtime are run by myaws.
+type Runtime struct {
+	Name aws.Runtime
+
+	// Image is the Docker image used to run Functions of this Runtime.
+	Image string
+
+	// Family groups runtimes that share invocation conventions, e.g. python3.7 and python3.8.
+	Family string
+
+	// HandlerRequired is false for custom runtimes, where the bootstrap executable decides what to run.
+	HandlerRequired bool
+
+	// Bootstrap is the path to the Runtime API client in the runtime's image. It is empty for images that predate
+	// the Runtime API, and for runtimes where the Function's code provides the bootstrap.
+	Bootstrap string
+}
+
+// HasBootstrap is true when Functions of this Runtime can poll the Runtime API, through either the image's bootstrap
+// or their own.
+func (runtime Runtime) HasBootstrap() bool {
+	return len(runtime.Bootstrap) > 0 || runtime.Family == FamilyGo || runtime.Family == FamilyProvided
+}
+
+// Command returns the container command used to start a Function with the given handler.
+func (runtime Runtime) Command(handler string) []string {
+	switch runtime.Family {
+	case FamilyProvided:
+		// the bootstrap decides what to run, so the handler is only passed through when there is one
+		if len(handler) == 0 {
+			return nil
+		}
+		return []string{handler}
+	case FamilyGo:
+		// the handler is the name of the executable in /var/task
+		return []string{strings.TrimPrefix(handler, "./")}
+	case FamilyJava:
+		// handlers are package.Class::method, where classes implementing RequestHandler may leave out the method
+		if !strings.Contains(handler, "::") {
+			handler += "::handleRequest"
+		}
+		return []string{handler}
+	default:
+		// dotnet uses Assembly::Namespace.Class::Method, interpreted runtimes use module.function
+		return []string{handler}
+	}
+}
+
+func newRuntime(name aws.Runtime, family string) Runtime {
+	runtime := Runtime{
+		Name:            name,
+		Image:           runtimeImagePrefix + string(name),
+		Family:          family,
+		HandlerRequired: family != FamilyProvided,
+	}
+
+	if family != FamilyGo && family != FamilyProvided {
+		runtime.Bootstrap = runtimeBootstrap
+	}
+
+	return runtime
+}
+
+// withoutBootstrap marks runtimes whose images only support being invoked through docker-lambda.
+func (runtime Runtime) withoutBootstrap() Runtime {
+	runtime.Bootstrap = ""
+	return runtime
+}
+
+var runtimes = []Runtime{
+	newRuntime(aws.RuntimeDotnetcore31, FamilyDotnet),
+	newRuntime(aws.RuntimeGo1x, FamilyGo),
+	newRuntime(aws.RuntimeJava8al2, FamilyJava),
+	newRuntime(aws.RuntimeJava11, FamilyJava),
+	newRuntime(aws.RuntimeNodejs12x, FamilyNodejs),
+	newRuntime(aws.RuntimeNodejs14x, FamilyNodejs),
+	newRuntime(aws.Runtime("nodejs16.x"), FamilyNodejs),
+	newRuntime(aws.RuntimeProvided, FamilyProvided),
+	newRuntime(aws.RuntimeProvidedal2, FamilyProvided),
+	newRuntime(aws.RuntimePython36, FamilyPython).withoutBootstrap(),
+	newRuntime(aws.RuntimePython37, FamilyPython).withoutBootstrap(),
+	newRuntime(aws.RuntimePython38, FamilyPython),
+	newRuntime(aws.RuntimePython39, FamilyPython),
+	newRuntime(aws.RuntimeRuby27, FamilyRuby),
+}
+
+// RuntimeByName returns the Runtime for the AWS runtime name, and false if myaws does not support it.
+func RuntimeByName(name aws.Runtime) (Runtime, bool) {
+	for _, runtime := range runtimes {
+		if runtime.Name == name {
+			return runtime, true
+		}
+	}
+
+	return Runtime{}, false
+}
@@ -0,0 +1,114 @@
+package lambda
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	aws "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"myaws/database"
+	"myaws/lambda/queries"
+	"myaws/lambda/types"
+	"myaws/log"
+	"myaws/utils"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+const (
+	maxLayers       = 5
+	maxUnzippedSize = 262144000
+)
+
+var layerVersionArnRegex = regexp.MustCompile(`^arn:aws:lambda:[a-z0-9-]+:\d{12}:layer:[A-Za-z0-9_-]+:\d+$`)
+
+// requestError is returned when a request is rejected the way Lambda would reject it, as opposed to failing for
+// reasons that are myaws' fault.
+type requestError struct {
+	statusCode int
+	errorType  string
+	message    string
+}
+
+func (err requestError) Error() string {
+	return err.message
+}
+
+func invalidParameter(format string, v ...interface{}) error {
+	return requestError{
+		statusCode: http.StatusBadRequest,
+		errorType:  errorTypeInvalidParameterValue,
+		message:    fmt.Sprintf(format, v...),
+	}
+}
+
+func accessDenied(format string, v ...interface{}) error {
+	return requestError{
+		statusCode: http.StatusForbidden,
+		errorType:  errorTypeAccessDenied,
+		message:    fmt.Sprintf(format, v...),
+	}
+}
+
+// respondWithValidationError rejects the request when err came from validation, or fails it otherwise.
+func respondWithValidationError(response http.ResponseWriter, name string, err error) {
+	if rejected, ok := err.(requestError); ok {
+		log.Error("Invalid request for Function %s: %s", name, rejected.message)
+		respondWithError(response, rejected.statusCode, rejected.errorType, rejected.message)
+		return
+	}
+
+	msg := log.Error("Unable to validate request for Function %s: %v", name, err)
+	http.Error(response, msg, http.StatusInternalServerError)
+}
+
+// validateLayers loads the Layer versions referenced by a Function and checks that Lambda would accept them for the
+// runtime & architecture, given the unzipped size of the Function's own code.
+func validateLayers(ctx context.Context, db *database.Database, runtime aws.Runtime, architecture aws.Architecture, arns []string, codeSize int64) ([]types.LambdaLayer, error) {
+	if len(arns) > maxLayers {
+		return nil, invalidParameter("Cannot reference more than %d layers.", maxLayers)
+	}
+
+	layers := make([]types.LambdaLayer, len(arns))
+	totalSize := codeSize
+	for i, arn := range arns {
+		if !layerVersionArnRegex.MatchString(arn) {
+			return nil, invalidParameter("Layer version %s does not exist.", arn)
+		}
+
+		reference := types.LayerFromArn(arn)
+		layer, err := queries.LayerByNameAndVersion(ctx, db, reference.Name, reference.Version)
+		if err == sql.ErrNoRows {
+			return nil, invalidParameter("Layer version %s does not exist.", arn)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Layers that don't declare what they are compatible with can be used with anything
+		if len(layer.CompatibleRuntimes) > 0 && !layer.IsCompatible(runtime, "") {
+			return nil, invalidParameter("Layer version %s is not compatible with the runtime %s.", arn, runtime)
+		}
+
+		if len(layer.CompatibleArchitectures) > 0 && !layer.IsCompatible("", architecture) {
+			return nil, invalidParameter("Layer version %s is not compatible with the architecture %s.", arn,
+				architecture)
+		}
+
+		size, err := utils.UncompressedZipFileSize(layer.GetDestPath(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		totalSize += size
+		layers[i] = layer
+	}
+
+	if totalSize > maxUnzippedSize {
+		return nil, invalidParameter("Function code combined with layers exceeds the maximum allowed size of %s "+
+			"bytes. The actual size is %s bytes.", strconv.Itoa(maxUnzippedSize), strconv.FormatInt(totalSize, 10))
+	}
+
+	return layers, nil
+}
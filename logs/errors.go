@@ -0,0 +1,27 @@
+package logs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	errorTypeResourceNotFound      = "ResourceNotFoundException"
+	errorTypeResourceAlreadyExists = "ResourceAlreadyExistsException"
+	errorTypeInvalidParameter      = "InvalidParameterException"
+	errorTypeUnknownOperation      = "UnknownOperationException"
+	errorTypeServiceUnavailable    = "ServiceUnavailableException"
+)
+
+type errorBody struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// respondWithError writes an error the way JSON protocol services do, with the type of error in the body.
+func respondWithError(response http.ResponseWriter, statusCode int, errorType string, message string) {
+	response.Header().Set("Content-Type", contentType)
+	response.WriteHeader(statusCode)
+
+	json.NewEncoder(response).Encode(errorBody{Type: errorType, Message: message})
+}
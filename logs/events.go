@@ -0,0 +1,303 @@
+package logs
+
+import (
+	"context"
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/queries"
+	"myaws/logs/types"
+	"myaws/settings"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxEventsPerPut  = 10000
+	maxEventsPerPage = 10000
+
+	// eventsPerScan is how many events FilterLogEvents reads at a time to match against its filter pattern
+	eventsPerScan = 1000
+
+	forwardTokenPrefix  = "f/"
+	backwardTokenPrefix = "b/"
+)
+
+// insertEvents puts the events into the stream, returning the ID of the last of them.
+func insertEvents(ctx context.Context, db *database.Database, stream *types.LogStream, events []types.InputLogEvent) (int64, error) {
+	now := time.Now().UnixMilli()
+	results := make([]types.LogEvent, len(events))
+	for i, event := range events {
+		results[i] = types.LogEvent{Timestamp: event.Timestamp, Message: event.Message, IngestionTime: now}
+	}
+
+	return queries.InsertLogEvents(ctx, db, stream, results)
+}
+
+// PutEvents puts the events into the stream of the log group, creating either when it doesn't exist yet.
+func PutEvents(ctx context.Context, groupName string, streamName string, events []types.InputLogEvent) error {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	now := time.Now().UnixMilli()
+	_, err := queries.LogGroupByName(ctx, db, groupName)
+	if err == sql.ErrNoRows {
+		// another writer may have created the group in the meantime, so failing to insert it isn't an error yet
+		queries.InsertLogGroup(ctx, db, &types.LogGroup{Name: groupName, CreatedOn: now})
+		_, err = queries.LogGroupByName(ctx, db, groupName)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	stream, err := queries.LogStreamByName(ctx, db, groupName, streamName)
+	if err == sql.ErrNoRows {
+		queries.InsertLogStream(ctx, db, &types.LogStream{GroupName: groupName, Name: streamName, CreatedOn: now})
+		stream, err = queries.LogStreamByName(ctx, db, groupName, streamName)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = insertEvents(ctx, db, stream, events)
+	return err
+}
+
+func putLogEvents(response http.ResponseWriter, request *http.Request) {
+	var body types.PutLogEventsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.LogEvents) == 0 || len(body.LogEvents) > maxEventsPerPut {
+		msg := log.Error("PutLogEvents must have between 1 and %d events, not %d", maxEventsPerPut, len(body.LogEvents))
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	if findLogGroup(response, request, db, body.LogGroupName) == nil {
+		return
+	}
+
+	stream := findLogStream(response, request, db, types.LogStreamInput{
+		LogGroupName:  body.LogGroupName,
+		LogStreamName: body.LogStreamName,
+	})
+	if stream == nil {
+		return
+	}
+
+	// sequence tokens are no longer required by CloudWatch Logs, so the one of the request isn't checked
+	id, err := insertEvents(ctx, db, stream, body.LogEvents)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	respondWithJson(response, types.PutLogEventsOutput{NextSequenceToken: strconv.FormatInt(id, 10)})
+}
+
+// parseToken returns the ID in the token of GetLogEvents, & whether it reads forward, or false when it's invalid.
+func parseToken(token string) (int64, bool, bool) {
+	forward := strings.HasPrefix(token, forwardTokenPrefix)
+	if !forward && !strings.HasPrefix(token, backwardTokenPrefix) {
+		return 0, false, false
+	}
+
+	id, err := strconv.ParseInt(token[len(forwardTokenPrefix):], 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, false
+	}
+
+	return id, forward, true
+}
+
+// getLogEvents reads either forward from the oldest event, or backward from the latest, like CloudWatch Logs does.
+// Tokens hold the ID of the event the next page starts after, when reading forward, or before, when reading backward.
+func getLogEvents(response http.ResponseWriter, request *http.Request) {
+	var body types.GetLogEventsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 || limit > maxEventsPerPage {
+		limit = maxEventsPerPage
+	}
+
+	filter := types.EventFilter{
+		GroupName:   body.LogGroupName,
+		StreamNames: []string{body.LogStreamName},
+		StartTime:   body.StartTime,
+		EndTime:     body.EndTime,
+		Limit:       limit,
+		Descending:  !body.StartFromHead,
+	}
+
+	if len(body.NextToken) > 0 {
+		id, forward, ok := parseToken(body.NextToken)
+		if !ok {
+			msg := log.Error("The specified nextToken is invalid: %s", body.NextToken)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+			return
+		}
+
+		filter.Descending = !forward
+		if forward {
+			filter.After = id
+		} else {
+			filter.Before = id
+		}
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	stream := findLogStream(response, request, db, types.LogStreamInput{
+		LogGroupName:  body.LogGroupName,
+		LogStreamName: body.LogStreamName,
+	})
+	if stream == nil {
+		return
+	}
+
+	events, err := queries.LogEvents(ctx, db, filter)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	if filter.Descending {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	// without events, the tokens stay where the request read from, so reading forward again finds later events
+	first, last := filter.After+1, filter.After
+	if filter.Descending {
+		first, last = filter.Before, filter.Before-1
+		if filter.Before == 0 {
+			first, last = 0, 0
+		}
+	}
+
+	output := types.GetLogEventsOutput{Events: make([]types.OutputLogEvent, len(events))}
+	for i, event := range events {
+		output.Events[i] = event.ToOutput()
+	}
+
+	if len(events) > 0 {
+		first, last = events[0].ID, events[len(events)-1].ID
+	}
+
+	output.NextForwardToken = forwardTokenPrefix + strconv.FormatInt(last, 10)
+	output.NextBackwardToken = backwardTokenPrefix + strconv.FormatInt(first, 10)
+	respondWithJson(response, output)
+}
+
+func filterLogEvents(response http.ResponseWriter, request *http.Request) {
+	var body types.FilterLogEventsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.LogStreamNames) > 0 && len(body.LogStreamNamePrefix) > 0 {
+		msg := log.Error("logStreamNames & logStreamNamePrefix can't both be specified")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	pattern, err := parseFilterPattern(body.FilterPattern)
+	if err != nil {
+		msg := log.Error("Invalid filter pattern %s: %v", body.FilterPattern, err)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 || limit > maxEventsPerPage {
+		limit = maxEventsPerPage
+	}
+
+	// the token of the next page is the ID of the last event that was matched
+	filter := types.EventFilter{
+		GroupName:        body.LogGroupName,
+		StreamNames:      body.LogStreamNames,
+		StreamNamePrefix: body.LogStreamNamePrefix,
+		StartTime:        body.StartTime,
+		EndTime:          body.EndTime,
+		Limit:            eventsPerScan,
+	}
+
+	if len(body.NextToken) > 0 {
+		filter.After, err = strconv.ParseInt(body.NextToken, 10, 64)
+		if err != nil {
+			msg := log.Error("The specified nextToken is invalid: %s", body.NextToken)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+			return
+		}
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	if findLogGroup(response, request, db, body.LogGroupName) == nil {
+		return
+	}
+
+	output := types.FilterLogEventsOutput{Events: []types.FilteredLogEvent{}}
+	searched := []string{}
+	found := make(map[string]bool)
+	for len(output.Events) < limit {
+		events, err := queries.LogEvents(ctx, db, filter)
+		if err != nil {
+			respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+			return
+		}
+
+		for _, event := range events {
+			filter.After = event.ID
+			if !found[event.StreamName] {
+				found[event.StreamName] = true
+				searched = append(searched, event.StreamName)
+			}
+
+			if pattern.matches(event.Message) {
+				output.Events = append(output.Events, event.ToFiltered())
+				if len(output.Events) == limit {
+					output.NextToken = strconv.FormatInt(event.ID, 10)
+					break
+				}
+			}
+		}
+
+		if len(events) < eventsPerScan {
+			break
+		}
+	}
+
+	output.SearchedLogStreams = make([]types.SearchedLogStream, len(searched))
+	for i, name := range searched {
+		output.SearchedLogStreams[i] = types.SearchedLogStream{
+			LogStreamName:      name,
+			SearchedCompletely: len(output.NextToken) == 0,
+		}
+	}
+
+	respondWithJson(response, output)
+}
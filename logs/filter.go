@@ -0,0 +1,79 @@
+package logs
+
+import (
+	"errors"
+	"strings"
+)
+
+// filterPattern matches the messages of log events against the terms of a filter pattern. Only the syntax for
+// unstructured events is supported: a message must have every term, any one of the terms prefixed with ?, and none
+// of the terms prefixed with -. Terms are case-sensitive, & terms with spaces are quoted.
+type filterPattern struct {
+	required []string
+	optional []string
+	excluded []string
+}
+
+func parseFilterPattern(pattern string) (*filterPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if strings.HasPrefix(pattern, "{") || strings.HasPrefix(pattern, "[") {
+		return nil, errors.New("JSON & space-delimited filter patterns aren't supported")
+	}
+
+	var result filterPattern
+	for len(pattern) > 0 {
+		terms := &result.required
+		switch pattern[0] {
+		case '?':
+			terms = &result.optional
+			pattern = pattern[1:]
+		case '-':
+			terms = &result.excluded
+			pattern = pattern[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(pattern, `"`) {
+			end := strings.Index(pattern[1:], `"`)
+			if end < 0 {
+				return nil, errors.New("unterminated quote in filter pattern")
+			}
+			term, pattern = pattern[1:end+1], pattern[end+2:]
+		} else {
+			end := strings.IndexAny(pattern, " \t")
+			if end < 0 {
+				end = len(pattern)
+			}
+			term, pattern = pattern[:end], pattern[end:]
+		}
+
+		if len(term) > 0 {
+			*terms = append(*terms, term)
+		}
+		pattern = strings.TrimSpace(pattern)
+	}
+
+	return &result, nil
+}
+
+func (pattern *filterPattern) matches(message string) bool {
+	for _, term := range pattern.excluded {
+		if strings.Contains(message, term) {
+			return false
+		}
+	}
+
+	for _, term := range pattern.required {
+		if !strings.Contains(message, term) {
+			return false
+		}
+	}
+
+	for _, term := range pattern.optional {
+		if strings.Contains(message, term) {
+			return true
+		}
+	}
+
+	return len(pattern.optional) == 0
+}
@@ -0,0 +1,132 @@
+package logs
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/queries"
+	"myaws/logs/types"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const maxLogGroupsPerPage = 50
+
+var logGroupNameRegex = regexp.MustCompile(`^[.\-_/#A-Za-z0-9]{1,512}$`)
+
+// findLogGroup writes an error response & returns nil when the log group doesn't exist.
+func findLogGroup(response http.ResponseWriter, request *http.Request, db *database.Database, name string) *types.LogGroup {
+	group, err := queries.LogGroupByName(request.Context(), db, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("The specified log group does not exist: %s", name)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return nil
+	}
+
+	return group
+}
+
+func createLogGroup(response http.ResponseWriter, request *http.Request) {
+	var body types.LogGroupInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !logGroupNameRegex.MatchString(body.LogGroupName) {
+		msg := log.Error("Log group name %s must match %s", body.LogGroupName, logGroupNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	_, err := queries.LogGroupByName(ctx, db, body.LogGroupName)
+	switch {
+	case err == nil:
+		msg := log.Error("The specified log group already exists: %s", body.LogGroupName)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceAlreadyExists, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	group := types.LogGroup{Name: body.LogGroupName, CreatedOn: time.Now().UnixMilli()}
+	err = queries.InsertLogGroup(ctx, db, &group)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func deleteLogGroup(response http.ResponseWriter, request *http.Request) {
+	var body types.LogGroupInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	group := findLogGroup(response, request, db, body.LogGroupName)
+	if group == nil {
+		return
+	}
+
+	err := queries.DeleteLogGroup(ctx, db, group)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func describeLogGroups(response http.ResponseWriter, request *http.Request) {
+	var body types.DescribeLogGroupsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 || limit > maxLogGroupsPerPage {
+		limit = maxLogGroupsPerPage
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	// the token of the next page is the name of the last group of the page
+	groups, err := queries.LogGroups(ctx, db, body.LogGroupNamePrefix, body.NextToken, limit+1)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	output := types.DescribeLogGroupsOutput{LogGroups: []types.LogGroupOutput{}}
+	if len(groups) > limit {
+		groups = groups[:limit]
+		output.NextToken = groups[limit-1].Name
+	}
+
+	for _, group := range groups {
+		output.LogGroups = append(output.LogGroups, group.ToOutput(ctx))
+	}
+
+	respondWithJson(response, output)
+}
@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"encoding/json"
+	"io"
+	"myaws/log"
+	"net/http"
+)
+
+const (
+	targetPrefix = "Logs_20140328."
+	contentType  = "application/x-amz-json-1.1"
+)
+
+type action func(http.ResponseWriter, *http.Request)
+
+var actions = map[string]action{
+	targetPrefix + "CreateLogGroup":     createLogGroup,
+	targetPrefix + "DeleteLogGroup":     deleteLogGroup,
+	targetPrefix + "DescribeLogGroups":  describeLogGroups,
+	targetPrefix + "CreateLogStream":    createLogStream,
+	targetPrefix + "DescribeLogStreams": describeLogStreams,
+	targetPrefix + "PutLogEvents":       putLogEvents,
+	targetPrefix + "GetLogEvents":       getLogEvents,
+	targetPrefix + "FilterLogEvents":    filterLogEvents,
+}
+
+// Handler serves CloudWatch Logs' JSON protocol, dispatching on the action in the X-Amz-Target header.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	target := request.Header.Get("X-Amz-Target")
+	action, ok := actions[target]
+	if !ok {
+		msg := log.Error("Unsupported CloudWatch Logs action %s", target)
+		respondWithError(response, http.StatusBadRequest, errorTypeUnknownOperation, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, request *http.Request, value interface{}) bool {
+	defer request.Body.Close()
+
+	err := json.NewDecoder(request.Body).Decode(value)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return false
+	}
+
+	return true
+}
+
+// respondWithJson writes the response without logging it, unlike other services, since it may have many log events.
+func respondWithJson(response http.ResponseWriter, value interface{}) {
+	response.Header().Set("Content-Type", contentType)
+	json.NewEncoder(response).Encode(value)
+}
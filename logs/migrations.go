@@ -0,0 +1,32 @@
+package logs
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "Logs",
+		Description: "Create Log Group, Log Stream & Log Event Tables",
+		Query: `CREATE TABLE IF NOT EXISTS logs_group (
+					id				integer primary key autoincrement,
+					name			text not null UNIQUE,
+					created_on		integer not null
+				);
+				CREATE TABLE IF NOT EXISTS logs_stream (
+					id				integer primary key autoincrement,
+					group_name		text not null,
+					name			text not null,
+					created_on		integer not null,
+					UNIQUE(group_name, name)
+				);
+				CREATE TABLE IF NOT EXISTS logs_event (
+					id				integer primary key autoincrement,
+					group_name		text not null,
+					stream_name		text not null,
+					timestamp		integer not null,
+					message			text not null,
+					ingestion_time	integer not null
+				);
+				CREATE INDEX IF NOT EXISTS logs_event_stream ON logs_event (group_name, stream_name, id);
+		`,
+	},
+}
@@ -0,0 +1,115 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/types"
+	"strings"
+)
+
+// InsertLogEvents saves the events of the stream, returning the ID of the last of them.
+func InsertLogEvents(ctx context.Context, db *database.Database, stream *types.LogStream, events []types.LogEvent) (int64, error) {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to put events into %s: %v", stream.Name, err)
+		return 0, errors.New(msg)
+	}
+
+	var id int64
+	for _, event := range events {
+		id, err = tx.InsertOne(
+			ctx,
+			`INSERT INTO logs_event (group_name, stream_name, timestamp, message, ingestion_time) VALUES (?, ?, ?, ?, ?)`,
+			stream.GroupName,
+			stream.Name,
+			event.Timestamp,
+			event.Message,
+			event.IngestionTime,
+		)
+
+		if err != nil {
+			msg := tx.Rollback("Unable to put events into %s: %v", stream.Name, err)
+			return 0, errors.New(msg)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit events of %s: %v", stream.Name, err)
+		return 0, errors.New(msg)
+	}
+
+	return id, nil
+}
+
+// LogEvents returns the events selected by the filter.
+func LogEvents(ctx context.Context, db *database.Database, filter types.EventFilter) ([]types.LogEvent, error) {
+	conditions := []string{`group_name = ?`, `id > ?`}
+	args := []interface{}{filter.GroupName, filter.After}
+
+	if filter.Before > 0 {
+		conditions = append(conditions, `id < ?`)
+		args = append(args, filter.Before)
+	}
+
+	if len(filter.StreamNames) > 0 {
+		conditions = append(conditions, `stream_name IN (?`+strings.Repeat(`, ?`, len(filter.StreamNames)-1)+`)`)
+		for _, name := range filter.StreamNames {
+			args = append(args, name)
+		}
+	}
+
+	if len(filter.StreamNamePrefix) > 0 {
+		conditions = append(conditions, `substr(stream_name, 1, length(?)) = ?`)
+		args = append(args, filter.StreamNamePrefix, filter.StreamNamePrefix)
+	}
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, `timestamp >= ?`)
+		args = append(args, *filter.StartTime)
+	}
+
+	if filter.EndTime != nil {
+		conditions = append(conditions, `timestamp <= ?`)
+		args = append(args, *filter.EndTime)
+	}
+
+	order := `id`
+	if filter.Descending {
+		order = `id DESC`
+	}
+
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT id, group_name, stream_name, timestamp, message, ingestion_time FROM logs_event
+				WHERE `+strings.Join(conditions, ` AND `)+` ORDER BY `+order+` LIMIT ?`,
+		append(args, filter.Limit)...,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query events of log group %s: %v", filter.GroupName, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.LogEvent{}
+	for rows.Next() {
+		var event types.LogEvent
+		err = rows.Scan(
+			&event.ID,
+			&event.GroupName,
+			&event.StreamName,
+			&event.Timestamp,
+			&event.Message,
+			&event.IngestionTime,
+		)
+		if err != nil {
+			msg := log.Error("Unable to scan event of log group %s: %v", filter.GroupName, err)
+			return nil, errors.New(msg)
+		}
+		results = append(results, event)
+	}
+
+	return results, nil
+}
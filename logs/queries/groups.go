@@ -0,0 +1,107 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/types"
+)
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func InsertLogGroup(ctx context.Context, db *database.Database, group *types.LogGroup) error {
+	id, err := db.InsertOne(ctx, `INSERT INTO logs_group (name, created_on) VALUES (?, ?)`, group.Name, group.CreatedOn)
+	if err != nil {
+		msg := log.Error("Unable to save log group %s: %v", group.Name, err)
+		return errors.New(msg)
+	}
+
+	group.ID = id
+	return nil
+}
+
+const selectLogGroup = `SELECT g.id, g.name, g.created_on,
+				COALESCE((SELECT SUM(length(e.message)) FROM logs_event e WHERE e.group_name = g.name), 0)
+			FROM logs_group g`
+
+// LogGroupByName returns the log group, or sql.ErrNoRows when there is no such group.
+func LogGroupByName(ctx context.Context, db *database.Database, name string) (*types.LogGroup, error) {
+	row := db.QueryRowContext(ctx, selectLogGroup+` WHERE g.name = ?`, name)
+	return scanLogGroup(row)
+}
+
+// LogGroups returns up to limit log groups whose names start with the prefix & come after the name, ordered by name.
+func LogGroups(ctx context.Context, db *database.Database, prefix string, after string, limit int) ([]types.LogGroup, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		selectLogGroup+` WHERE substr(g.name, 1, length(?)) = ? AND g.name > ? ORDER BY g.name LIMIT ?`,
+		prefix,
+		prefix,
+		after,
+		limit,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query log groups: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.LogGroup{}
+	for rows.Next() {
+		group, err := scanLogGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *group)
+	}
+
+	return results, nil
+}
+
+func scanLogGroup(row scanner) (*types.LogGroup, error) {
+	var group types.LogGroup
+	err := row.Scan(&group.ID, &group.Name, &group.CreatedOn, &group.StoredBytes)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan log group: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &group, nil
+}
+
+// DeleteLogGroup deletes the log group along with its streams & their events.
+func DeleteLogGroup(ctx context.Context, db *database.Database, group *types.LogGroup) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to delete log group %s: %v", group.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM logs_event WHERE group_name = ?`, group.Name)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete events of log group %s: %v", group.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM logs_stream WHERE group_name = ?`, group.Name)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete streams of log group %s: %v", group.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM logs_group WHERE id = ?`, group.ID)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete log group %s: %v", group.Name, err)
+		return errors.New(msg)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,107 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/types"
+)
+
+func InsertLogStream(ctx context.Context, db *database.Database, stream *types.LogStream) error {
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO logs_stream (group_name, name, created_on) VALUES (?, ?, ?)`,
+		stream.GroupName,
+		stream.Name,
+		stream.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save log stream %s of group %s: %v", stream.Name, stream.GroupName, err)
+		return errors.New(msg)
+	}
+
+	stream.ID = id
+	return nil
+}
+
+// selectLogStream selects log streams along with the statistics of their events, which are null for streams
+// without events.
+const selectLogStream = `SELECT s.id, s.group_name, s.name, s.created_on, COALESCE(MIN(e.timestamp), 0),
+				COALESCE(MAX(e.timestamp), 0), COALESCE(MAX(e.ingestion_time), 0), COALESCE(SUM(length(e.message)), 0)
+			FROM logs_stream s
+			LEFT JOIN logs_event e ON e.group_name = s.group_name AND e.stream_name = s.name`
+
+// LogStreamByName returns the log stream, or sql.ErrNoRows when there is no such stream.
+func LogStreamByName(ctx context.Context, db *database.Database, groupName string, name string) (*types.LogStream, error) {
+	row := db.QueryRowContext(ctx, selectLogStream+` WHERE s.group_name = ? AND s.name = ? GROUP BY s.id`,
+		groupName, name)
+	return scanLogStream(row)
+}
+
+// LogStreams returns up to limit log streams of the group whose names start with the prefix, skipping the first
+// offset of them, ordered by name or by the time of their last event.
+func LogStreams(ctx context.Context, db *database.Database, input types.DescribeLogStreamsInput, offset int, limit int) ([]types.LogStream, error) {
+	order := `s.name`
+	if input.OrderBy == types.OrderByLastEventTime {
+		order = `MAX(e.timestamp)`
+	}
+
+	if input.Descending {
+		order += ` DESC`
+	}
+
+	rows, err := db.QueryContext(
+		ctx,
+		selectLogStream+` WHERE s.group_name = ? AND substr(s.name, 1, length(?)) = ?
+				GROUP BY s.id ORDER BY `+order+`, s.id LIMIT ? OFFSET ?`,
+		input.LogGroupName,
+		input.LogStreamNamePrefix,
+		input.LogStreamNamePrefix,
+		limit,
+		offset,
+	)
+	if err != nil {
+		msg := log.Error("Unable to query log streams of group %s: %v", input.LogGroupName, err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.LogStream{}
+	for rows.Next() {
+		stream, err := scanLogStream(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *stream)
+	}
+
+	return results, nil
+}
+
+func scanLogStream(row scanner) (*types.LogStream, error) {
+	var stream types.LogStream
+	err := row.Scan(
+		&stream.ID,
+		&stream.GroupName,
+		&stream.Name,
+		&stream.CreatedOn,
+		&stream.FirstEventTimestamp,
+		&stream.LastEventTimestamp,
+		&stream.LastIngestionTime,
+		&stream.StoredBytes,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan log stream: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	return &stream, nil
+}
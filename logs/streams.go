@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/logs/queries"
+	"myaws/logs/types"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const maxLogStreamsPerPage = 50
+
+var logStreamNameRegex = regexp.MustCompile(`^[^:*]{1,512}$`)
+
+// findLogStream writes an error response & returns nil when the log stream doesn't exist.
+func findLogStream(response http.ResponseWriter, request *http.Request, db *database.Database, input types.LogStreamInput) *types.LogStream {
+	stream, err := queries.LogStreamByName(request.Context(), db, input.LogGroupName, input.LogStreamName)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("The specified log stream does not exist: %s", input.LogStreamName)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return nil
+	}
+
+	return stream
+}
+
+func createLogStream(response http.ResponseWriter, request *http.Request) {
+	var body types.LogStreamInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !logStreamNameRegex.MatchString(body.LogStreamName) {
+		msg := log.Error("Log stream name %s must match %s", body.LogStreamName, logStreamNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	if findLogGroup(response, request, db, body.LogGroupName) == nil {
+		return
+	}
+
+	_, err := queries.LogStreamByName(ctx, db, body.LogGroupName, body.LogStreamName)
+	switch {
+	case err == nil:
+		msg := log.Error("The specified log stream already exists: %s", body.LogStreamName)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceAlreadyExists, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	stream := types.LogStream{GroupName: body.LogGroupName, Name: body.LogStreamName, CreatedOn: time.Now().UnixMilli()}
+	err = queries.InsertLogStream(ctx, db, &stream)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	respondWithJson(response, struct{}{})
+}
+
+func describeLogStreams(response http.ResponseWriter, request *http.Request) {
+	var body types.DescribeLogStreamsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if body.OrderBy == types.OrderByLastEventTime && len(body.LogStreamNamePrefix) > 0 {
+		msg := log.Error("Cannot order by LastEventTime with a logStreamNamePrefix.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	limit := body.Limit
+	if limit <= 0 || limit > maxLogStreamsPerPage {
+		limit = maxLogStreamsPerPage
+	}
+
+	// the token of the next page is the number of streams before it
+	offset := 0
+	if len(body.NextToken) > 0 {
+		var err error
+		offset, err = strconv.Atoi(body.NextToken)
+		if err != nil {
+			msg := log.Error("The specified nextToken is invalid: %s", body.NextToken)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+			return
+		}
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	if findLogGroup(response, request, db, body.LogGroupName) == nil {
+		return
+	}
+
+	streams, err := queries.LogStreams(ctx, db, body, offset, limit+1)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeServiceUnavailable, err.Error())
+		return
+	}
+
+	output := types.DescribeLogStreamsOutput{LogStreams: []types.LogStreamOutput{}}
+	if len(streams) > limit {
+		streams = streams[:limit]
+		output.NextToken = strconv.Itoa(offset + limit)
+	}
+
+	for _, stream := range streams {
+		output.LogStreams = append(output.LogStreams, stream.ToOutput(ctx))
+	}
+
+	respondWithJson(response, output)
+}
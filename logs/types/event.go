@@ -0,0 +1,108 @@
+package types
+
+import "strconv"
+
+// LogEvent is a message logged to a stream, whose ID increases in the order events are put.
+type LogEvent struct {
+	ID            int64
+	GroupName     string
+	StreamName    string
+	Timestamp     int64
+	Message       string
+	IngestionTime int64
+}
+
+type InputLogEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+type OutputLogEvent struct {
+	Timestamp     int64  `json:"timestamp"`
+	Message       string `json:"message"`
+	IngestionTime int64  `json:"ingestionTime"`
+}
+
+func (event LogEvent) ToOutput() OutputLogEvent {
+	return OutputLogEvent{Timestamp: event.Timestamp, Message: event.Message, IngestionTime: event.IngestionTime}
+}
+
+type FilteredLogEvent struct {
+	LogStreamName string `json:"logStreamName"`
+	Timestamp     int64  `json:"timestamp"`
+	Message       string `json:"message"`
+	IngestionTime int64  `json:"ingestionTime"`
+	EventId       string `json:"eventId"`
+}
+
+func (event LogEvent) ToFiltered() FilteredLogEvent {
+	return FilteredLogEvent{
+		LogStreamName: event.StreamName,
+		Timestamp:     event.Timestamp,
+		Message:       event.Message,
+		IngestionTime: event.IngestionTime,
+		EventId:       strconv.FormatInt(event.ID, 10),
+	}
+}
+
+type PutLogEventsInput struct {
+	LogGroupName  string          `json:"logGroupName"`
+	LogStreamName string          `json:"logStreamName"`
+	LogEvents     []InputLogEvent `json:"logEvents"`
+}
+
+type PutLogEventsOutput struct {
+	NextSequenceToken string `json:"nextSequenceToken"`
+}
+
+type GetLogEventsInput struct {
+	LogGroupName  string `json:"logGroupName"`
+	LogStreamName string `json:"logStreamName"`
+	StartTime     *int64 `json:"startTime"`
+	EndTime       *int64 `json:"endTime"`
+	NextToken     string `json:"nextToken"`
+	Limit         int    `json:"limit"`
+	StartFromHead bool   `json:"startFromHead"`
+}
+
+type GetLogEventsOutput struct {
+	Events            []OutputLogEvent `json:"events"`
+	NextForwardToken  string           `json:"nextForwardToken"`
+	NextBackwardToken string           `json:"nextBackwardToken"`
+}
+
+type FilterLogEventsInput struct {
+	LogGroupName        string   `json:"logGroupName"`
+	LogStreamNames      []string `json:"logStreamNames"`
+	LogStreamNamePrefix string   `json:"logStreamNamePrefix"`
+	StartTime           *int64   `json:"startTime"`
+	EndTime             *int64   `json:"endTime"`
+	FilterPattern       string   `json:"filterPattern"`
+	NextToken           string   `json:"nextToken"`
+	Limit               int      `json:"limit"`
+}
+
+type SearchedLogStream struct {
+	LogStreamName      string `json:"logStreamName"`
+	SearchedCompletely bool   `json:"searchedCompletely"`
+}
+
+type FilterLogEventsOutput struct {
+	Events             []FilteredLogEvent  `json:"events"`
+	SearchedLogStreams []SearchedLogStream `json:"searchedLogStreams"`
+	NextToken          string              `json:"nextToken,omitempty"`
+}
+
+// EventFilter selects the events of a log group that are read. Events are read in the order they were put, after the
+// event with the ID After, or before the event with the ID Before when it's set.
+type EventFilter struct {
+	GroupName        string
+	StreamNames      []string
+	StreamNamePrefix string
+	StartTime        *int64
+	EndTime          *int64
+	After            int64
+	Before           int64
+	Limit            int
+	Descending       bool
+}
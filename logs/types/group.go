@@ -0,0 +1,53 @@
+package types
+
+import (
+	"context"
+	"myaws/settings"
+)
+
+// The version of the SDK used by myaws has no CloudWatch Logs client, so these types mirror the API's JSON, whose
+// members are in camel case.
+
+type LogGroup struct {
+	ID          int64
+	Name        string
+	CreatedOn   int64
+	StoredBytes int64
+}
+
+func (group LogGroup) GetArn(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	return "arn:aws:logs:" + cfg.ArnFragment() + ":log-group:" + group.Name
+}
+
+type LogGroupOutput struct {
+	LogGroupName      string `json:"logGroupName"`
+	CreationTime      int64  `json:"creationTime"`
+	MetricFilterCount int    `json:"metricFilterCount"`
+	Arn               string `json:"arn"`
+	StoredBytes       int64  `json:"storedBytes"`
+}
+
+func (group LogGroup) ToOutput(ctx context.Context) LogGroupOutput {
+	return LogGroupOutput{
+		LogGroupName: group.Name,
+		CreationTime: group.CreatedOn,
+		Arn:          group.GetArn(ctx) + ":*",
+		StoredBytes:  group.StoredBytes,
+	}
+}
+
+type LogGroupInput struct {
+	LogGroupName string `json:"logGroupName"`
+}
+
+type DescribeLogGroupsInput struct {
+	LogGroupNamePrefix string `json:"logGroupNamePrefix"`
+	NextToken          string `json:"nextToken"`
+	Limit              int    `json:"limit"`
+}
+
+type DescribeLogGroupsOutput struct {
+	LogGroups []LogGroupOutput `json:"logGroups"`
+	NextToken string           `json:"nextToken,omitempty"`
+}
@@ -0,0 +1,66 @@
+package types
+
+import "context"
+
+const (
+	OrderByLogStreamName = "LogStreamName"
+	OrderByLastEventTime = "LastEventTime"
+)
+
+// LogStream is a sequence of log events, along with statistics of its events.
+type LogStream struct {
+	ID                  int64
+	GroupName           string
+	Name                string
+	CreatedOn           int64
+	FirstEventTimestamp int64
+	LastEventTimestamp  int64
+	LastIngestionTime   int64
+	StoredBytes         int64
+}
+
+func (stream LogStream) GetArn(ctx context.Context) string {
+	group := LogGroup{Name: stream.GroupName}
+	return group.GetArn(ctx) + ":log-stream:" + stream.Name
+}
+
+type LogStreamOutput struct {
+	LogStreamName       string `json:"logStreamName"`
+	CreationTime        int64  `json:"creationTime"`
+	FirstEventTimestamp int64  `json:"firstEventTimestamp,omitempty"`
+	LastEventTimestamp  int64  `json:"lastEventTimestamp,omitempty"`
+	LastIngestionTime   int64  `json:"lastIngestionTime,omitempty"`
+	Arn                 string `json:"arn"`
+	StoredBytes         int64  `json:"storedBytes"`
+}
+
+func (stream LogStream) ToOutput(ctx context.Context) LogStreamOutput {
+	return LogStreamOutput{
+		LogStreamName:       stream.Name,
+		CreationTime:        stream.CreatedOn,
+		FirstEventTimestamp: stream.FirstEventTimestamp,
+		LastEventTimestamp:  stream.LastEventTimestamp,
+		LastIngestionTime:   stream.LastIngestionTime,
+		Arn:                 stream.GetArn(ctx),
+		StoredBytes:         stream.StoredBytes,
+	}
+}
+
+type LogStreamInput struct {
+	LogGroupName  string `json:"logGroupName"`
+	LogStreamName string `json:"logStreamName"`
+}
+
+type DescribeLogStreamsInput struct {
+	LogGroupName        string `json:"logGroupName"`
+	LogStreamNamePrefix string `json:"logStreamNamePrefix"`
+	OrderBy             string `json:"orderBy"`
+	Descending          bool   `json:"descending"`
+	NextToken           string `json:"nextToken"`
+	Limit               int    `json:"limit"`
+}
+
+type DescribeLogStreamsOutput struct {
+	LogStreams []LogStreamOutput `json:"logStreams"`
+	NextToken  string            `json:"nextToken,omitempty"`
+}
@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"flag"
+	"myaws/apigateway"
 	"myaws/database"
 	"myaws/docker"
+	"myaws/dynamodb"
+	"myaws/events"
 	"myaws/http"
+	"myaws/kinesis"
 	"myaws/lambda"
 	"myaws/lambda/queries"
 	"myaws/log"
+	"myaws/logs"
 	"myaws/moto"
 	"myaws/s3"
+	"myaws/secretsmanager"
 	"myaws/settings"
+	"myaws/sns"
 	"myaws/sqs"
+	"myaws/sts"
 	"os"
 	"os/signal"
 	"time"
@@ -20,7 +29,14 @@ import (
 )
 
 func main() {
-	cfg := settings.DefaultConfig()
+	configPath := flag.String("config", os.Getenv("MYAWS_CONFIG"), "Path to a JSON configuration file")
+	flag.Parse()
+
+	cfg, err := settings.LoadConfig(*configPath)
+	if err != nil {
+		os.Exit(1)
+	}
+
 	mainCtx := cfg.NewContext(context.Background())
 
 	c := make(chan os.Signal, 1)
@@ -42,12 +58,24 @@ func start(ctx context.Context, config *settings.Config) error {
 	log.Info("Starting up ...")
 
 	initializeDb(config)
-	initializeDocker(ctx)
+	if config.DockerServices {
+		initializeDocker(ctx)
+	} else {
+		log.Info("Docker services are disabled, so S3, Moto, SQS and DynamoDB are not available")
+	}
 	server, err := http.Serve(config)
 	if err != nil {
 		panic(err)
 	}
 
+	go events.RunScheduler(ctx)
+	lambda.RecoverFunctions(ctx)
+
+	// Kinesis is served by myaws, so its event sources don't wait for a container like those of queues & tables
+	db := database.CreateConnection(config)
+	startEventSources(ctx, db, "kinesis")
+	db.Close()
+
 	<-ctx.Done()
 
 	log.Info("Shutting down ...")
@@ -62,19 +90,34 @@ func start(ctx context.Context, config *settings.Config) error {
 		log.Error("Error when shutting down HTTP server")
 	}
 
+	lambda.StopWatching()
+
 	err = docker.ShutdownAll(ctxShutDown)
 	if err != nil {
 		log.Error("Errors when shutting down docker containers: %v", err)
 	}
 
+	err = lambda.ShutdownProcesses()
+	if err != nil {
+		log.Error("Errors when shutting down Function processes: %v", err)
+	}
+
 	return nil
 }
 
 func initializeDb(cfg *settings.Config) {
 	var migrations database.Migrations
+	migrations.AddAll(apigateway.Migrations)
+	migrations.AddAll(dynamodb.Migrations)
+	migrations.AddAll(events.Migrations)
+	migrations.AddAll(kinesis.Migrations)
 	migrations.AddAll(lambda.Migrations)
+	migrations.AddAll(logs.Migrations)
 	migrations.AddAll(moto.Migrations)
+	migrations.AddAll(secretsmanager.Migrations)
+	migrations.AddAll(sns.Migrations)
 	migrations.AddAll(sqs.Migrations)
+	migrations.AddAll(sts.Migrations)
 
 	log.Info("Initializing DB with %d Migrations.", migrations.Size())
 	database.Initialize(cfg, migrations)
@@ -86,6 +129,7 @@ func initializeDocker(ctx context.Context) {
 
 	go initializeMoto(ctx)
 	go initializeElasticMQ(ctx, db)
+	go initializeDynamoDB(ctx, db)
 
 	docker.EnsureImage(ctx, s3.Image)
 	s3Container, err := s3.Container(cfg)
@@ -97,21 +141,6 @@ func initializeDocker(ctx context.Context) {
 	if err != nil {
 		panic(err)
 	}
-
-	docker.EnsureImage(ctx, "mlupin/docker-lambda:python3.8")
-
-	functions, err := queries.LatestFunctions(ctx, db)
-	if err != nil {
-		panic(err)
-	}
-
-	for _, function := range functions {
-		err := lambda.StartFunction(ctx, &function)
-		if err != nil {
-			panic(err)
-		}
-	}
-
 }
 
 func initializeMoto(ctx context.Context) {
@@ -138,6 +167,27 @@ func initializeMoto(ctx context.Context) {
 	}
 }
 
+func initializeDynamoDB(ctx context.Context, db *database.Database) {
+	docker.EnsureImage(ctx, dynamodb.Image)
+
+	cfg := settings.FromContext(ctx)
+	container, err := dynamodb.Container(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	dynamoDbReady, err := docker.Start(ctx, *container, "CorsParams")
+	if err != nil {
+		panic(err)
+	}
+
+	<-dynamoDbReady
+
+	log.Info("DynamoDB Local is ready, starting event sources ...")
+
+	startEventSources(ctx, db, "dynamodb")
+}
+
 func initializeElasticMQ(ctx context.Context, db *database.Database) {
 	docker.EnsureImage(ctx, sqs.Image)
 	cfg := settings.FromContext(ctx)
@@ -155,13 +205,26 @@ func initializeElasticMQ(ctx context.Context, db *database.Database) {
 
 	log.Info("ElasticMQ is ready, starting event sources ...")
 
-	eventSource, err := queries.LoadEventSource(ctx, db, "0486b330-6eed-48eb-87ac-742ab978db18")
+	startEventSources(ctx, db, "sqs")
+}
+
+// startEventSources starts the enabled event sources whose queues or streams belong to the service. One that fails
+// to start doesn't stop the others.
+func startEventSources(ctx context.Context, db *database.Database, service string) {
+	ids, err := queries.EnabledEventSourceIds(ctx, db, service)
 	if err != nil {
-		panic(err)
+		return
 	}
 
-	err = lambda.StartEventSource(ctx, eventSource)
-	if err != nil {
-		panic(err)
+	for _, id := range ids {
+		eventSource, err := queries.LoadEventSource(ctx, db, id)
+		if err != nil || eventSource == nil {
+			continue
+		}
+
+		err = lambda.StartEventSource(ctx, eventSource)
+		if err != nil {
+			log.Error("Unable to start Event Source %s: %v", id, err)
+		}
 	}
 }
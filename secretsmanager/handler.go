@@ -0,0 +1,148 @@
+package secretsmanager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"myaws/database"
+	"myaws/log"
+	"myaws/secretsmanager/queries"
+	"myaws/secretsmanager/types"
+	"net/http"
+	"strings"
+)
+
+const (
+	targetPrefix = "secretsmanager."
+	contentType  = "application/x-amz-json-1.1"
+)
+
+type action func(http.ResponseWriter, *http.Request)
+
+var actions = map[string]action{
+	targetPrefix + "CreateSecret":             createSecret,
+	targetPrefix + "DescribeSecret":           describeSecret,
+	targetPrefix + "ListSecrets":              listSecrets,
+	targetPrefix + "DeleteSecret":             deleteSecret,
+	targetPrefix + "RestoreSecret":            restoreSecret,
+	targetPrefix + "GetSecretValue":           getSecretValue,
+	targetPrefix + "PutSecretValue":           putSecretValue,
+	targetPrefix + "UpdateSecretVersionStage": updateSecretVersionStage,
+	targetPrefix + "RotateSecret":             rotateSecret,
+}
+
+// Handler serves Secrets Manager's JSON protocol, dispatching on the action in the X-Amz-Target header.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	target := request.Header.Get("X-Amz-Target")
+	action, ok := actions[target]
+	if !ok {
+		msg := log.Error("Unsupported Secrets Manager action %s", target)
+		respondWithError(response, http.StatusBadRequest, errorTypeUnknownOperation, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+// decodeBody writes an error response & returns false when the body of the request isn't the expected JSON.
+func decodeBody(response http.ResponseWriter, request *http.Request, value interface{}) bool {
+	defer request.Body.Close()
+
+	err := json.NewDecoder(request.Body).Decode(value)
+	if err != nil && err != io.EOF {
+		msg := log.Error("Error when decoding body: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return false
+	}
+
+	return true
+}
+
+// respondWithJson writes the response without logging it, unlike other services, since it may hold secret values.
+func respondWithJson(response http.ResponseWriter, value interface{}) {
+	response.Header().Set("Content-Type", contentType)
+	json.NewEncoder(response).Encode(value)
+}
+
+// secretByName returns the secret, or sql.ErrNoRows when there is no such secret. A deleted secret whose recovery
+// window has passed is deleted for good when it's found.
+func secretByName(request *http.Request, db *database.Database, name string) (*types.Secret, error) {
+	ctx := request.Context()
+	secret, err := queries.SecretByName(ctx, db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.IsExpired() {
+		log.Info("Recovery window of secret %s has passed, so it's deleted", name)
+		err = queries.DeleteSecret(ctx, db, secret)
+		if err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	return secret, nil
+}
+
+// findSecret writes an error response & returns nil when the ID, which is either the name of the secret or its ARN,
+// isn't of an existing secret. The ARN may be partial, without the random suffix that ends it.
+func findSecret(response http.ResponseWriter, request *http.Request, db *database.Database, id string) *types.Secret {
+	name := id
+	if strings.HasPrefix(id, "arn:") {
+		name = ""
+		if index := strings.Index(id, ":secret:"); index >= 0 {
+			name = id[index+len(":secret:"):]
+		}
+	}
+
+	secret, err := secretByName(request, db, name)
+
+	// a full ARN ends with a hyphen & the secret's suffix
+	if err == sql.ErrNoRows && name != id && len(name) > 7 && name[len(name)-7] == '-' {
+		secret, err = secretByName(request, db, name[:len(name)-7])
+		if err == nil && secret.Suffix != name[len(name)-6:] {
+			err = sql.ErrNoRows
+		}
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Secrets Manager can't find the specified secret: %s", id)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return nil
+	}
+
+	return secret
+}
+
+// findActiveSecret writes an error response & returns nil when the secret doesn't exist or is scheduled for
+// deletion, since its values can't be used or changed until it's restored.
+func findActiveSecret(response http.ResponseWriter, request *http.Request, db *database.Database, id string) *types.Secret {
+	secret := findSecret(response, request, db, id)
+	if secret == nil {
+		return nil
+	}
+
+	if secret.IsScheduledForDeletion() {
+		msg := log.Error("You can't perform this operation on the secret because it was marked for deletion: %s", id)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidRequest, msg)
+		return nil
+	}
+
+	return secret
+}
+
+// saveSecret writes an error response & returns false when the secret can't be saved.
+func saveSecret(response http.ResponseWriter, request *http.Request, db *database.Database, secret *types.Secret) bool {
+	err := queries.SaveSecret(request.Context(), db, secret)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return false
+	}
+
+	return true
+}
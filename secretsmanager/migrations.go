@@ -0,0 +1,37 @@
+package secretsmanager
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "SecretsManager",
+		Description: "Create Secret & Secret Version Tables",
+		Query: `CREATE TABLE IF NOT EXISTS secretsmanager_secret (
+					id						integer primary key autoincrement,
+					name					text not null unique,
+					suffix					text not null,
+					description				text not null,
+					kms_key_id				text not null,
+					tags					text not null,
+					stages					text not null,
+					rotation_enabled		boolean not null,
+					rotation_lambda_arn		text not null,
+					rotation_rules			text not null,
+					last_rotated_date		integer not null,
+					last_changed_date		integer not null,
+					last_accessed_date		integer not null,
+					deleted_date			integer not null,
+					created_on				integer not null
+				);
+				CREATE TABLE IF NOT EXISTS secretsmanager_secret_version (
+					id						integer primary key autoincrement,
+					secret_name				text not null,
+					version_id				text not null,
+					secret_string			text,
+					secret_binary			blob,
+					created_on				integer not null,
+					UNIQUE(secret_name, version_id)
+				);
+		`,
+	},
+}
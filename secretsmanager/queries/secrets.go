@@ -0,0 +1,182 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/secretsmanager/types"
+)
+
+// scanner is either a sql.Row or sql.Rows, so that a single function scans a table's columns for both.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// SaveSecret creates the secret, or replaces what is kept about it when a secret with the same name already exists.
+func SaveSecret(ctx context.Context, db *database.Database, secret *types.Secret) error {
+	tags, err := json.Marshal(secret.Tags)
+	if err != nil {
+		msg := log.Error("Unable to serialize tags of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	stages, err := json.Marshal(secret.Stages)
+	if err != nil {
+		msg := log.Error("Unable to serialize staging labels of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	rotationRules, err := json.Marshal(secret.RotationRules)
+	if err != nil {
+		msg := log.Error("Unable to serialize rotation rules of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`INSERT INTO secretsmanager_secret (name, suffix, description, kms_key_id, tags, stages, rotation_enabled,
+					rotation_lambda_arn, rotation_rules, last_rotated_date, last_changed_date, last_accessed_date,
+					deleted_date, created_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(name) DO UPDATE SET description = excluded.description,
+					kms_key_id = excluded.kms_key_id, tags = excluded.tags, stages = excluded.stages,
+					rotation_enabled = excluded.rotation_enabled, rotation_lambda_arn = excluded.rotation_lambda_arn,
+					rotation_rules = excluded.rotation_rules, last_rotated_date = excluded.last_rotated_date,
+					last_changed_date = excluded.last_changed_date, last_accessed_date = excluded.last_accessed_date,
+					deleted_date = excluded.deleted_date
+		`,
+		secret.Name,
+		secret.Suffix,
+		secret.Description,
+		secret.KmsKeyId,
+		string(tags),
+		string(stages),
+		secret.RotationEnabled,
+		secret.RotationLambdaArn,
+		string(rotationRules),
+		secret.LastRotatedDate,
+		secret.LastChangedDate,
+		secret.LastAccessedDate,
+		secret.DeletedDate,
+		secret.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectSecret = `SELECT id, name, suffix, description, kms_key_id, tags, stages, rotation_enabled,
+				rotation_lambda_arn, rotation_rules, last_rotated_date, last_changed_date, last_accessed_date,
+				deleted_date, created_on
+			FROM secretsmanager_secret`
+
+// SecretByName returns the secret, or sql.ErrNoRows when there is no such secret.
+func SecretByName(ctx context.Context, db *database.Database, name string) (*types.Secret, error) {
+	row := db.QueryRowContext(ctx, selectSecret+` WHERE name = ?`, name)
+	return scanSecret(row)
+}
+
+// Secrets returns at most limit secrets, in order of their names, whose names are after the given name.
+func Secrets(ctx context.Context, db *database.Database, after string, limit int) ([]types.Secret, error) {
+	rows, err := db.QueryContext(ctx, selectSecret+` WHERE name > ? ORDER BY name LIMIT ?`, after, limit)
+	if err != nil {
+		msg := log.Error("Unable to query secrets: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Secret{}
+	for rows.Next() {
+		secret, err := scanSecret(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *secret)
+	}
+
+	return results, nil
+}
+
+func scanSecret(row scanner) (*types.Secret, error) {
+	var secret types.Secret
+	var tags, stages, rotationRules string
+	err := row.Scan(
+		&secret.ID,
+		&secret.Name,
+		&secret.Suffix,
+		&secret.Description,
+		&secret.KmsKeyId,
+		&tags,
+		&stages,
+		&secret.RotationEnabled,
+		&secret.RotationLambdaArn,
+		&rotationRules,
+		&secret.LastRotatedDate,
+		&secret.LastChangedDate,
+		&secret.LastAccessedDate,
+		&secret.DeletedDate,
+		&secret.CreatedOn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(tags), &secret.Tags)
+	if err != nil {
+		msg := log.Error("Unable to deserialize tags of secret %s: %v", secret.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(stages), &secret.Stages)
+	if err != nil {
+		msg := log.Error("Unable to deserialize staging labels of secret %s: %v", secret.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(rotationRules), &secret.RotationRules)
+	if err != nil {
+		msg := log.Error("Unable to deserialize rotation rules of secret %s: %v", secret.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	if secret.Stages == nil {
+		secret.Stages = make(map[string]string)
+	}
+
+	return &secret, nil
+}
+
+// DeleteSecret deletes the secret along with all of its versions.
+func DeleteSecret(ctx context.Context, db *database.Database, secret *types.Secret) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to delete secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secretsmanager_secret_version WHERE secret_name = ?`, secret.Name)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete versions of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secretsmanager_secret WHERE id = ?`, secret.ID)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit deletion of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
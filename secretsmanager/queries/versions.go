@@ -0,0 +1,88 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/secretsmanager/types"
+)
+
+// InsertSecretVersion saves the version along with the staging labels of the secret, which usually moved to it.
+func InsertSecretVersion(ctx context.Context, db *database.Database, secret *types.Secret, version *types.SecretVersion) error {
+	stages, err := json.Marshal(secret.Stages)
+	if err != nil {
+		msg := log.Error("Unable to serialize staging labels of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to save version %s of secret %s: %v", version.VersionId,
+			secret.Name, err)
+		return errors.New(msg)
+	}
+
+	version.ID, err = tx.InsertOne(
+		ctx,
+		`INSERT INTO secretsmanager_secret_version (secret_name, version_id, secret_string, secret_binary, created_on)
+				VALUES (?, ?, ?, ?, ?)`,
+		secret.Name,
+		version.VersionId,
+		version.SecretString,
+		version.SecretBinary,
+		version.CreatedOn,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("Unable to save version %s of secret %s: %v", version.VersionId, secret.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE secretsmanager_secret SET stages = ?, last_changed_date = ? WHERE name = ?`,
+		string(stages),
+		secret.LastChangedDate,
+		secret.Name,
+	)
+
+	if err != nil {
+		msg := tx.Rollback("Unable to save staging labels of secret %s: %v", secret.Name, err)
+		return errors.New(msg)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit version %s of secret %s: %v", version.VersionId, secret.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// SecretVersion returns the version of the secret, or sql.ErrNoRows when there is no such version.
+func SecretVersion(ctx context.Context, db *database.Database, secretName string, versionId string) (*types.SecretVersion, error) {
+	var version types.SecretVersion
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT id, secret_name, version_id, secret_string, secret_binary, created_on
+				FROM secretsmanager_secret_version WHERE secret_name = ? AND version_id = ?`,
+		secretName,
+		versionId,
+	).Scan(
+		&version.ID,
+		&version.SecretName,
+		&version.VersionId,
+		&version.SecretString,
+		&version.SecretBinary,
+		&version.CreatedOn,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,168 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"myaws/database"
+	"myaws/lambda"
+	"myaws/log"
+	"myaws/secretsmanager/queries"
+	"myaws/secretsmanager/types"
+	"myaws/settings"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+// rotationSteps are the steps of a rotation, in order, which the rotation Function is invoked for one at a time.
+var rotationSteps = []string{"createSecret", "setSecret", "testSecret", "finishSecret"}
+
+var functionNameRegex = regexp.MustCompile(`:function:([A-Za-z0-9_-]+)`)
+
+// rotateSecret configures the rotation of the secret, & rotates it unless asked not to. Rotation isn't scheduled, so
+// the rotation rules are only kept for DescribeSecret.
+func rotateSecret(response http.ResponseWriter, request *http.Request) {
+	var body types.RotateSecretInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findActiveSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	functionArn := body.RotationLambdaARN
+	if len(functionArn) == 0 {
+		functionArn = secret.RotationLambdaArn
+	}
+
+	if len(functionArn) == 0 {
+		msg := log.Error("No Lambda rotation function ARN is associated with secret %s", secret.Name)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidRequest, msg)
+		return
+	}
+
+	groups := functionNameRegex.FindStringSubmatch(functionArn)
+	if groups == nil {
+		msg := log.Error("RotationLambdaARN %s isn't the ARN of a Function", functionArn)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	if pending, ok := secret.Stages[types.StagePending]; ok && pending != secret.Stages[types.StageCurrent] {
+		msg := log.Error("A previous rotation of secret %s isn't complete, since version %s is still %s", secret.Name,
+			pending, types.StagePending)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidRequest, msg)
+		return
+	}
+
+	secret.RotationEnabled = true
+	secret.RotationLambdaArn = functionArn
+	if body.RotationRules != nil {
+		secret.RotationRules = body.RotationRules
+	}
+
+	output := types.SecretOutput{ARN: secret.GetArn(ctx), Name: secret.Name}
+	rotateImmediately := body.RotateImmediately == nil || *body.RotateImmediately
+	if rotateImmediately {
+		output.VersionId = body.ClientRequestToken
+		if len(output.VersionId) == 0 {
+			output.VersionId = uuid.Generate().String()
+		}
+
+		// the version is AWSPENDING before the first step, since rotation Functions check it is in
+		// VersionIdsToStages before creating its value
+		secret.MoveStage(types.StagePending, output.VersionId)
+	}
+
+	secret.LastChangedDate = time.Now().UnixMilli()
+	if !saveSecret(response, request, db, secret) {
+		return
+	}
+
+	if rotateImmediately {
+		// the Function is invoked after responding, like Secrets Manager does, so it can't use the request's context
+		go rotate(cfg.NewContext(context.Background()), *secret, groups[1], output.VersionId)
+	}
+
+	respondWithJson(response, output)
+}
+
+// rotate invokes the Function for each step of rotating the secret to the version with the token, which is
+// AWSPENDING until the Function makes it AWSCURRENT. When the rotation fails, AWSPENDING is removed from the version
+// so that the secret can be rotated again.
+func rotate(ctx context.Context, secret types.Secret, functionName string, token string) {
+	arn := secret.GetArn(ctx)
+	log.Info("Rotating secret %s to version %s using Function %s ...", secret.Name, token, functionName)
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	for _, step := range rotationSteps {
+		payload, err := json.Marshal(types.RotationEvent{Step: step, SecretId: arn, ClientRequestToken: token})
+		if err != nil {
+			log.Error("Unable to serialize %s step of rotating secret %s: %v", step, secret.Name, err)
+			abandonRotation(ctx, db, secret.Name, token)
+			return
+		}
+
+		result, err := lambda.Invoke(lambda.WithInvocationSource(ctx, "secretsmanager:"+arn), functionName, payload)
+		if err != nil {
+			log.Error("Unable to invoke Function %s for %s step of rotating secret %s: %v", functionName, step,
+				secret.Name, err)
+			abandonRotation(ctx, db, secret.Name, token)
+			return
+		}
+
+		if len(result.FunctionError) > 0 {
+			log.Error("Function %s failed the %s step of rotating secret %s: %s", functionName, step, secret.Name,
+				result.Payload)
+			abandonRotation(ctx, db, secret.Name, token)
+			return
+		}
+	}
+
+	rotated, err := queries.SecretByName(ctx, db, secret.Name)
+	if err != nil {
+		log.Error("Unable to find secret %s after rotating it: %v", secret.Name, err)
+		return
+	}
+
+	if rotated.Stages[types.StageCurrent] != token {
+		log.Error("Function %s finished rotating secret %s without making version %s %s", functionName, secret.Name,
+			token, types.StageCurrent)
+		abandonRotation(ctx, db, secret.Name, token)
+		return
+	}
+
+	delete(rotated.Stages, types.StagePending)
+	rotated.LastRotatedDate = time.Now().UnixMilli()
+	queries.SaveSecret(ctx, db, rotated)
+
+	log.Info("Rotated secret %s to version %s", secret.Name, token)
+}
+
+// abandonRotation removes AWSPENDING from the version with the token, unless it was moved by someone else since.
+func abandonRotation(ctx context.Context, db *database.Database, secretName string, token string) {
+	secret, err := queries.SecretByName(ctx, db, secretName)
+	if err != nil {
+		log.Error("Unable to find secret %s after failing to rotate it: %v", secretName, err)
+		return
+	}
+
+	if secret.Stages[types.StagePending] != token {
+		return
+	}
+
+	delete(secret.Stages, types.StagePending)
+	queries.SaveSecret(ctx, db, secret)
+}
@@ -0,0 +1,247 @@
+package secretsmanager
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/secretsmanager/queries"
+	"myaws/secretsmanager/types"
+	"myaws/settings"
+	"myaws/utils"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	maxSecretsPerPage = 100
+
+	minRecoveryWindowInDays     = 7
+	maxRecoveryWindowInDays     = 30
+	defaultRecoveryWindowInDays = 30
+)
+
+var secretNameRegex = regexp.MustCompile(`^[A-Za-z0-9/_+=.@-]{1,512}$`)
+
+func createSecret(response http.ResponseWriter, request *http.Request) {
+	var body types.CreateSecretInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if !secretNameRegex.MatchString(body.Name) {
+		msg := log.Error("Invalid name %s, which must match %s", body.Name, secretNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	if body.SecretString != nil && body.SecretBinary != nil {
+		msg := log.Error("You can't specify both a binary secret value and a string secret value in the same secret.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	existing, err := secretByName(request, db, body.Name)
+	switch {
+	case err == nil && existing.IsScheduledForDeletion():
+		msg := log.Error("You can't create this secret because a secret with this name is already scheduled for deletion.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidRequest, msg)
+		return
+	case err == nil:
+		msg := log.Error("The operation failed because the secret %s already exists.", body.Name)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceExists, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	secret := types.Secret{
+		Name:            body.Name,
+		Suffix:          utils.RandomId(6),
+		Description:     body.Description,
+		KmsKeyId:        body.KmsKeyId,
+		Tags:            body.Tags,
+		Stages:          make(map[string]string),
+		LastChangedDate: now,
+		CreatedOn:       now,
+	}
+
+	if !saveSecret(response, request, db, &secret) {
+		return
+	}
+
+	output := types.SecretOutput{ARN: secret.GetArn(ctx), Name: secret.Name}
+
+	// a secret may be created without a value, in which case it doesn't have any versions yet
+	if body.SecretString != nil || body.SecretBinary != nil {
+		version := newVersion(body.ClientRequestToken, body.SecretString, body.SecretBinary)
+		secret.MoveStage(types.StageCurrent, version.VersionId)
+
+		err = queries.InsertSecretVersion(ctx, db, &secret, &version)
+		if err != nil {
+			respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+			return
+		}
+		output.VersionId = version.VersionId
+	}
+
+	respondWithJson(response, output)
+}
+
+func describeSecret(response http.ResponseWriter, request *http.Request) {
+	var body types.SecretIdInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	respondWithJson(response, secret.ToDescription(request.Context()))
+}
+
+func listSecrets(response http.ResponseWriter, request *http.Request) {
+	var body types.ListSecretsInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	limit := body.MaxResults
+	if limit <= 0 || limit > maxSecretsPerPage {
+		limit = maxSecretsPerPage
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	// the token of the next page is the name of the last secret of the page
+	secrets, err := queries.Secrets(ctx, db, body.NextToken, limit+1)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	output := types.ListSecretsOutput{SecretList: []types.SecretDescription{}}
+	if len(secrets) > limit {
+		secrets = secrets[:limit]
+		output.NextToken = secrets[limit-1].Name
+	}
+
+	for _, secret := range secrets {
+		if secret.IsExpired() || (secret.IsScheduledForDeletion() && !body.IncludePlannedDeletion) {
+			continue
+		}
+		output.SecretList = append(output.SecretList, secret.ToListEntry(ctx))
+	}
+
+	respondWithJson(response, output)
+}
+
+// deleteSecret schedules the secret to be deleted once its recovery window passes, unless it's deleted without
+// recovery. Until then, it can be restored.
+func deleteSecret(response http.ResponseWriter, request *http.Request) {
+	var body types.DeleteSecretInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if body.ForceDeleteWithoutRecovery && body.RecoveryWindowInDays != nil {
+		msg := log.Error("You can't use ForceDeleteWithoutRecovery in conjunction with RecoveryWindowInDays.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	days := int64(defaultRecoveryWindowInDays)
+	if body.RecoveryWindowInDays != nil {
+		days = *body.RecoveryWindowInDays
+	}
+
+	if days < minRecoveryWindowInDays || days > maxRecoveryWindowInDays {
+		msg := log.Error("RecoveryWindowInDays must be between %d and %d days, not %d", minRecoveryWindowInDays,
+			maxRecoveryWindowInDays, days)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	if body.ForceDeleteWithoutRecovery {
+		err := queries.DeleteSecret(ctx, db, secret)
+		if err != nil {
+			respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+			return
+		}
+
+		respondWithJson(response, types.DeleteSecretOutput{
+			ARN:          secret.GetArn(ctx),
+			Name:         secret.Name,
+			DeletionDate: types.EpochSeconds(time.Now().UnixMilli()),
+		})
+		return
+	}
+
+	if secret.IsScheduledForDeletion() {
+		msg := log.Error("You can't perform this operation on the secret because it was already scheduled for deletion.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidRequest, msg)
+		return
+	}
+
+	now := time.Now()
+	secret.DeletedDate = now.Add(time.Duration(days) * 24 * time.Hour).UnixMilli()
+	secret.LastChangedDate = now.UnixMilli()
+	if !saveSecret(response, request, db, secret) {
+		return
+	}
+
+	respondWithJson(response, types.DeleteSecretOutput{
+		ARN:          secret.GetArn(ctx),
+		Name:         secret.Name,
+		DeletionDate: types.EpochSeconds(secret.DeletedDate),
+	})
+}
+
+func restoreSecret(response http.ResponseWriter, request *http.Request) {
+	var body types.SecretIdInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	secret.DeletedDate = 0
+	secret.LastChangedDate = time.Now().UnixMilli()
+	if saveSecret(response, request, db, secret) {
+		respondWithJson(response, types.SecretOutput{ARN: secret.GetArn(ctx), Name: secret.Name})
+	}
+}
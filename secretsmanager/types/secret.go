@@ -0,0 +1,219 @@
+package types
+
+import (
+	"context"
+	"myaws/settings"
+	"sort"
+	"time"
+)
+
+const (
+	StageCurrent  = "AWSCURRENT"
+	StagePrevious = "AWSPREVIOUS"
+	StagePending  = "AWSPENDING"
+)
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type RotationRules struct {
+	AutomaticallyAfterDays *int64  `json:",omitempty"`
+	Duration               *string `json:",omitempty"`
+	ScheduleExpression     *string `json:",omitempty"`
+}
+
+// Secret holds what Secrets Manager keeps about a secret apart from its values, which are kept by its versions. Dates
+// are in milliseconds, & are 0 when they haven't happened.
+type Secret struct {
+	ID                int64
+	Name              string
+	Suffix            string
+	Description       string
+	KmsKeyId          string
+	Tags              []Tag
+	Stages            map[string]string
+	RotationEnabled   bool
+	RotationLambdaArn string
+	RotationRules     *RotationRules
+	LastRotatedDate   int64
+	LastChangedDate   int64
+	LastAccessedDate  int64
+	DeletedDate       int64
+	CreatedOn         int64
+}
+
+// GetArn returns the ARN of the secret, which ends with a random suffix so that it differs from the ARN of a
+// deleted secret that had the same name.
+func (secret Secret) GetArn(ctx context.Context) string {
+	cfg := settings.FromContext(ctx)
+	return "arn:aws:secretsmanager:" + cfg.ArnFragment() + ":secret:" + secret.Name + "-" + secret.Suffix
+}
+
+// IsScheduledForDeletion reports whether the secret was deleted, but is still in its recovery window.
+func (secret Secret) IsScheduledForDeletion() bool {
+	return secret.DeletedDate > 0
+}
+
+// IsExpired reports whether the recovery window of the deleted secret has passed, so that it's gone for good.
+func (secret Secret) IsExpired() bool {
+	return secret.IsScheduledForDeletion() && secret.DeletedDate <= time.Now().UnixMilli()
+}
+
+// MoveStage attaches the staging label to the version, removing it from any other version. When the version becomes
+// the current one, the version that was current becomes the previous one, like Secrets Manager does.
+func (secret *Secret) MoveStage(stage string, versionId string) {
+	current, ok := secret.Stages[StageCurrent]
+	if stage == StageCurrent && ok && current != versionId {
+		secret.Stages[StagePrevious] = current
+	}
+
+	secret.Stages[stage] = versionId
+}
+
+// VersionStages returns the staging labels attached to the version, sorted.
+func (secret Secret) VersionStages(versionId string) []string {
+	results := []string{}
+	for stage, id := range secret.Stages {
+		if id == versionId {
+			results = append(results, stage)
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
+
+// VersionIdsToStages returns the staging labels of each version that has any.
+func (secret Secret) VersionIdsToStages() map[string][]string {
+	results := make(map[string][]string)
+	for _, versionId := range secret.Stages {
+		results[versionId] = secret.VersionStages(versionId)
+	}
+
+	return results
+}
+
+// SecretDescription is returned by DescribeSecret, & by ListSecrets with the versions' staging labels in
+// SecretVersionsToStages instead of VersionIdsToStages.
+type SecretDescription struct {
+	ARN                    string
+	Name                   string
+	Description            string              `json:",omitempty"`
+	KmsKeyId               string              `json:",omitempty"`
+	RotationEnabled        bool                `json:",omitempty"`
+	RotationLambdaARN      string              `json:",omitempty"`
+	RotationRules          *RotationRules      `json:",omitempty"`
+	LastRotatedDate        *float64            `json:",omitempty"`
+	LastChangedDate        *float64            `json:",omitempty"`
+	LastAccessedDate       *float64            `json:",omitempty"`
+	DeletedDate            *float64            `json:",omitempty"`
+	Tags                   []Tag               `json:",omitempty"`
+	VersionIdsToStages     map[string][]string `json:",omitempty"`
+	SecretVersionsToStages map[string][]string `json:",omitempty"`
+	CreatedDate            *float64
+}
+
+// EpochSeconds returns the date in milliseconds as the seconds that JSON protocol services use for timestamps, or nil
+// when it hasn't happened.
+func EpochSeconds(milliseconds int64) *float64 {
+	if milliseconds == 0 {
+		return nil
+	}
+
+	seconds := float64(milliseconds) / 1000
+	return &seconds
+}
+
+func (secret Secret) ToDescription(ctx context.Context) SecretDescription {
+	return SecretDescription{
+		ARN:                secret.GetArn(ctx),
+		Name:               secret.Name,
+		Description:        secret.Description,
+		KmsKeyId:           secret.KmsKeyId,
+		RotationEnabled:    secret.RotationEnabled,
+		RotationLambdaARN:  secret.RotationLambdaArn,
+		RotationRules:      secret.RotationRules,
+		LastRotatedDate:    EpochSeconds(secret.LastRotatedDate),
+		LastChangedDate:    EpochSeconds(secret.LastChangedDate),
+		LastAccessedDate:   EpochSeconds(secret.LastAccessedDate),
+		DeletedDate:        EpochSeconds(secret.DeletedDate),
+		Tags:               secret.Tags,
+		VersionIdsToStages: secret.VersionIdsToStages(),
+		CreatedDate:        EpochSeconds(secret.CreatedOn),
+	}
+}
+
+func (secret Secret) ToListEntry(ctx context.Context) SecretDescription {
+	result := secret.ToDescription(ctx)
+	result.SecretVersionsToStages = result.VersionIdsToStages
+	result.VersionIdsToStages = nil
+	return result
+}
+
+type CreateSecretInput struct {
+	Name               string
+	ClientRequestToken string
+	Description        string
+	KmsKeyId           string
+	SecretString       *string
+	SecretBinary       []byte
+	Tags               []Tag
+}
+
+type SecretIdInput struct {
+	SecretId string
+}
+
+// SecretOutput is the output of the actions that only return which secret they changed, & perhaps which version.
+type SecretOutput struct {
+	ARN       string
+	Name      string
+	VersionId string `json:",omitempty"`
+}
+
+type ListSecretsInput struct {
+	MaxResults             int
+	NextToken              string
+	IncludePlannedDeletion bool
+}
+
+type ListSecretsOutput struct {
+	SecretList []SecretDescription
+	NextToken  string `json:",omitempty"`
+}
+
+type UpdateSecretVersionStageInput struct {
+	SecretId            string
+	VersionStage        string
+	RemoveFromVersionId string
+	MoveToVersionId     string
+}
+
+type DeleteSecretInput struct {
+	SecretId                   string
+	RecoveryWindowInDays       *int64
+	ForceDeleteWithoutRecovery bool
+}
+
+type DeleteSecretOutput struct {
+	ARN          string
+	Name         string
+	DeletionDate *float64
+}
+
+type RotateSecretInput struct {
+	SecretId           string
+	ClientRequestToken string
+	RotationLambdaARN  string
+	RotationRules      *RotationRules
+	RotateImmediately  *bool
+}
+
+// RotationEvent is the event that the rotation Function is invoked with for each step of a rotation.
+type RotationEvent struct {
+	Step               string
+	SecretId           string
+	ClientRequestToken string
+}
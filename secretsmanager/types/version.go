@@ -0,0 +1,51 @@
+package types
+
+// SecretVersion is a value of a secret, which is either a string or binary.
+type SecretVersion struct {
+	ID           int64
+	SecretName   string
+	VersionId    string
+	SecretString *string
+	SecretBinary []byte
+	CreatedOn    int64
+}
+
+// HasValue reports whether the version has the same value as the other version.
+func (version SecretVersion) HasValue(other SecretVersion) bool {
+	if version.SecretString != nil || other.SecretString != nil {
+		return version.SecretString != nil && other.SecretString != nil && *version.SecretString == *other.SecretString
+	}
+
+	return string(version.SecretBinary) == string(other.SecretBinary)
+}
+
+type GetSecretValueInput struct {
+	SecretId     string
+	VersionId    string
+	VersionStage string
+}
+
+type GetSecretValueOutput struct {
+	ARN           string
+	Name          string
+	VersionId     string
+	SecretString  *string `json:",omitempty"`
+	SecretBinary  []byte  `json:",omitempty"`
+	VersionStages []string
+	CreatedDate   *float64
+}
+
+type PutSecretValueInput struct {
+	SecretId           string
+	ClientRequestToken string
+	SecretString       *string
+	SecretBinary       []byte
+	VersionStages      []string
+}
+
+type PutSecretValueOutput struct {
+	ARN           string
+	Name          string
+	VersionId     string
+	VersionStages []string
+}
@@ -0,0 +1,227 @@
+package secretsmanager
+
+import (
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/secretsmanager/queries"
+	"myaws/secretsmanager/types"
+	"myaws/settings"
+	"net/http"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+// newVersion returns a version with the value, whose ID is the client request token unless there isn't one.
+func newVersion(token string, secretString *string, secretBinary []byte) types.SecretVersion {
+	if len(token) == 0 {
+		token = uuid.Generate().String()
+	}
+
+	return types.SecretVersion{
+		VersionId:    token,
+		SecretString: secretString,
+		SecretBinary: secretBinary,
+		CreatedOn:    time.Now().UnixMilli(),
+	}
+}
+
+// getSecretValue returns the value of the version with the ID or staging label, which is the current version when
+// neither is given.
+func getSecretValue(response http.ResponseWriter, request *http.Request) {
+	var body types.GetSecretValueInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findActiveSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	stage := body.VersionStage
+	if len(stage) == 0 && len(body.VersionId) == 0 {
+		stage = types.StageCurrent
+	}
+
+	versionId := body.VersionId
+	if len(stage) > 0 {
+		id, ok := secret.Stages[stage]
+		if !ok || (len(versionId) > 0 && versionId != id) {
+			msg := log.Error("Secrets Manager can't find the specified secret value for staging label: %s", stage)
+			respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+			return
+		}
+		versionId = id
+	}
+
+	version, err := queries.SecretVersion(ctx, db, secret.Name, versionId)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Secrets Manager can't find the specified secret value for VersionId: %s", versionId)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+		return
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	// the date the secret was last accessed is rounded to the day, so it's only saved once a day
+	today := time.Now().UTC().Truncate(24 * time.Hour).UnixMilli()
+	if secret.LastAccessedDate != today {
+		secret.LastAccessedDate = today
+		queries.SaveSecret(ctx, db, secret)
+	}
+
+	respondWithJson(response, types.GetSecretValueOutput{
+		ARN:           secret.GetArn(ctx),
+		Name:          secret.Name,
+		VersionId:     version.VersionId,
+		SecretString:  version.SecretString,
+		SecretBinary:  version.SecretBinary,
+		VersionStages: secret.VersionStages(version.VersionId),
+		CreatedDate:   types.EpochSeconds(version.CreatedOn),
+	})
+}
+
+// putSecretValue creates a version of the secret, which becomes the current version unless other staging labels are
+// given. Putting the same version again with the same value succeeds, since versions can't change.
+func putSecretValue(response http.ResponseWriter, request *http.Request) {
+	var body types.PutSecretValueInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if (body.SecretString == nil) == (body.SecretBinary == nil) {
+		msg := log.Error("You must provide either SecretString or SecretBinary, but not both.")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findActiveSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	version := newVersion(body.ClientRequestToken, body.SecretString, body.SecretBinary)
+	existing, err := queries.SecretVersion(ctx, db, secret.Name, version.VersionId)
+	switch {
+	case err == nil && existing.HasValue(version):
+		respondWithJson(response, types.PutSecretValueOutput{
+			ARN:           secret.GetArn(ctx),
+			Name:          secret.Name,
+			VersionId:     existing.VersionId,
+			VersionStages: secret.VersionStages(existing.VersionId),
+		})
+		return
+	case err == nil:
+		msg := log.Error("You can't modify an existing version, you can only create a new version: %s",
+			version.VersionId)
+		respondWithError(response, http.StatusBadRequest, errorTypeResourceExists, msg)
+		return
+	case err != sql.ErrNoRows:
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	stages := body.VersionStages
+	if stages == nil {
+		stages = []string{types.StageCurrent}
+	}
+
+	for _, stage := range stages {
+		secret.MoveStage(stage, version.VersionId)
+	}
+
+	secret.LastChangedDate = time.Now().UnixMilli()
+	err = queries.InsertSecretVersion(ctx, db, secret, &version)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+		return
+	}
+
+	respondWithJson(response, types.PutSecretValueOutput{
+		ARN:           secret.GetArn(ctx),
+		Name:          secret.Name,
+		VersionId:     version.VersionId,
+		VersionStages: secret.VersionStages(version.VersionId),
+	})
+}
+
+// updateSecretVersionStage moves the staging label to a version, or removes it. A label that is attached to a
+// version has to be removed from it explicitly, so that labels aren't moved by accident.
+func updateSecretVersionStage(response http.ResponseWriter, request *http.Request) {
+	var body types.UpdateSecretVersionStageInput
+	if !decodeBody(response, request, &body) {
+		return
+	}
+
+	if len(body.VersionStage) == 0 {
+		msg := log.Error("VersionStage is required")
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	secret := findActiveSecret(response, request, db, body.SecretId)
+	if secret == nil {
+		return
+	}
+
+	stage := body.VersionStage
+	attachedTo, attached := secret.Stages[stage]
+	if len(body.RemoveFromVersionId) > 0 && attachedTo != body.RemoveFromVersionId {
+		msg := log.Error("The staging label %s isn't attached to version %s", stage, body.RemoveFromVersionId)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	if attached && attachedTo != body.MoveToVersionId && attachedTo != body.RemoveFromVersionId {
+		msg := log.Error("The staging label %s is currently attached to version %s, so you must explicitly reference "+
+			"that version in RemoveFromVersionId.", stage, attachedTo)
+		respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+		return
+	}
+
+	if len(body.MoveToVersionId) == 0 {
+		if stage == types.StageCurrent {
+			msg := log.Error("The staging label %s can only be moved to another version, not removed", stage)
+			respondWithError(response, http.StatusBadRequest, errorTypeInvalidParameter, msg)
+			return
+		}
+		delete(secret.Stages, stage)
+	} else {
+		_, err := queries.SecretVersion(ctx, db, secret.Name, body.MoveToVersionId)
+		switch {
+		case err == sql.ErrNoRows:
+			msg := log.Error("Secrets Manager can't find the specified secret value for VersionId: %s",
+				body.MoveToVersionId)
+			respondWithError(response, http.StatusBadRequest, errorTypeResourceNotFound, msg)
+			return
+		case err != nil:
+			respondWithError(response, http.StatusInternalServerError, errorTypeInternal, err.Error())
+			return
+		}
+		secret.MoveStage(stage, body.MoveToVersionId)
+	}
+
+	secret.LastChangedDate = time.Now().UnixMilli()
+	if saveSecret(response, request, db, secret) {
+		respondWithJson(response, types.SecretOutput{ARN: secret.GetArn(ctx), Name: secret.Name})
+	}
+}
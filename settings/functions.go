@@ -0,0 +1,120 @@
+package settings
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ExecutorDocker  = "docker"
+	ExecutorProcess = "process"
+
+	DefaultWatchInterval = time.Second
+	DefaultIdleTimeout   = 10 * time.Minute
+	DefaultStartTimeout  = 30 * time.Second
+)
+
+// Functions holds settings that control how Lambda Functions are run.
+type Functions struct {
+	// RiePath is the path on the host to the aws-lambda-rie binary. When set, it is mounted into container image
+	// Functions and used as their entrypoint, so that images without the Runtime Interface Emulator can be invoked.
+	RiePath string
+
+	// RuntimeApi enables the Lambda Runtime API inside myaws. Functions are then started with their runtime's
+	// bootstrap, which polls myaws for invocations instead of being invoked through docker-lambda or the emulator.
+	// Runtimes whose images have no bootstrap, such as python3.6 and python3.7, keep using docker-lambda.
+	RuntimeApi bool
+
+	// Executor is how Functions are run by default: ExecutorDocker runs each Function in a container, while
+	// ExecutorProcess runs bootstrap binaries and Python handlers as subprocesses of myaws.
+	Executor string
+
+	// Executors overrides Executor for individual Functions, keyed by Function name.
+	Executors map[string]string
+
+	// Python is the interpreter used to run Python handlers with ExecutorProcess. It needs the awslambdaric
+	// package installed.
+	Python string
+
+	// SourcePaths mounts a directory on the host as a Function's code instead of its unpacked zip, keyed by Function
+	// name. Changes to the directory restart the Function.
+	SourcePaths map[string]string
+
+	// LayerPaths mounts a directory on the host as a Function's /opt instead of its unpacked Layers, keyed by
+	// Function name. Changes to the directory restart the Function.
+	LayerPaths map[string]string
+
+	// WatchInterval is how often mounted directories are checked for changes.
+	WatchInterval time.Duration
+
+	// IdleTimeout is how long a Function can go without being invoked before it is stopped. It is started again on
+	// its next invocation. Zero keeps Functions running once they have started.
+	IdleTimeout time.Duration
+
+	// StartTimeout is how long a cold start waits for a Function to accept invocations.
+	StartTimeout time.Duration
+}
+
+// UnmarshalJSON reads the durations in configuration files as strings such as "10m", rather than in nanoseconds.
+func (functions *Functions) UnmarshalJSON(data []byte) error {
+	type plain Functions
+	file := struct {
+		*plain
+		WatchInterval string
+		IdleTimeout   string
+		StartTimeout  string
+	}{plain: (*plain)(functions)}
+
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return err
+	}
+
+	durations := []struct {
+		text  string
+		value *time.Duration
+	}{
+		{file.WatchInterval, &functions.WatchInterval},
+		{file.IdleTimeout, &functions.IdleTimeout},
+		{file.StartTimeout, &functions.StartTimeout},
+	}
+
+	for _, duration := range durations {
+		if len(duration.text) == 0 {
+			continue
+		}
+
+		*duration.value, err = time.ParseDuration(duration.text)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecutorFor returns the executor used to run the named Function.
+func (functions *Functions) ExecutorFor(name string) string {
+	executor, ok := functions.Executors[name]
+	if ok {
+		return executor
+	}
+
+	return functions.Executor
+}
+
+func DefaultFunctions() *Functions {
+	return &Functions{
+		RiePath:    "",
+		RuntimeApi: false,
+		Executor:   ExecutorDocker,
+		Executors:  make(map[string]string),
+		Python:     "python3",
+
+		SourcePaths:   make(map[string]string),
+		LayerPaths:    make(map[string]string),
+		WatchInterval: DefaultWatchInterval,
+		IdleTimeout:   DefaultIdleTimeout,
+		StartTimeout:  DefaultStartTimeout,
+	}
+}
@@ -0,0 +1,115 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"myaws/log"
+	"os"
+	"strconv"
+)
+
+const envPrefix = "MYAWS_"
+
+// configFile is the layout of the JSON configuration file. It is the Config itself, plus the settings that Config
+// doesn't export.
+type configFile struct {
+	*Config
+	DataPath string
+}
+
+// LoadConfig returns the default Config, overridden by the JSON file at path and then by MYAWS_* environment
+// variables. The file is optional: an empty path only applies the environment.
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+
+	if len(path) > 0 {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			msg := log.Error("Unable to read configuration file %s: %v", path, err)
+			return nil, errors.New(msg)
+		}
+
+		file := configFile{Config: config, DataPath: config.dataPath}
+		err = json.Unmarshal(content, &file)
+		if err != nil {
+			msg := log.Error("Unable to parse configuration file %s: %v", path, err)
+			return nil, errors.New(msg)
+		}
+
+		config.dataPath = file.DataPath
+	}
+
+	err := config.applyEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// applyEnvironment overrides the settings that are most often changed between runs. Maps such as Accounts and
+// Functions.Executors can only be set in the configuration file.
+func (config *Config) applyEnvironment() error {
+	texts := map[string]*string{
+		"ACCOUNT_NUMBER":     &config.AccountNumber,
+		"REGION":             &config.Region,
+		"DATA_PATH":          &config.dataPath,
+		"DOCKER_HOST":        &config.DockerHost,
+		"FUNCTIONS_EXECUTOR": &config.Functions.Executor,
+		"FUNCTIONS_PYTHON":   &config.Functions.Python,
+		"FUNCTIONS_RIE_PATH": &config.Functions.RiePath,
+		"DATABASE_FILENAME":  &config.Database.Filename,
+		"DATABASE_OPTIONS":   &config.Database.Options,
+		"HTTP_HOST":          &config.HTTP.Host,
+		"LAMBDA_HOST":        &config.Lambda.Host,
+		"MOTO_HOST":          &config.Moto.Host,
+		"S3_HOST":            &config.S3.Host,
+		"SQS_HOST":           &config.SQS.Host,
+		"DYNAMODB_HOST":      &config.DynamoDB.Host,
+	}
+
+	for name, value := range texts {
+		if env, ok := os.LookupEnv(envPrefix + name); ok {
+			*value = env
+		}
+	}
+
+	flags := map[string]*bool{
+		"DEBUG":                 &config.IsDebug,
+		"DOCKER_SERVICES":       &config.DockerServices,
+		"FUNCTIONS_RUNTIME_API": &config.Functions.RuntimeApi,
+	}
+
+	for name, value := range flags {
+		if env, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.ParseBool(env)
+			if err != nil {
+				msg := log.Error("Invalid value %s for %s%s: %v", env, envPrefix, name, err)
+				return errors.New(msg)
+			}
+			*value = parsed
+		}
+	}
+
+	numbers := map[string]*int{
+		"HTTP_PORT":     &config.HTTP.Port,
+		"LAMBDA_PORT":   &config.Lambda.Port,
+		"MOTO_PORT":     &config.Moto.Port,
+		"S3_PORT":       &config.S3.Port,
+		"SQS_PORT":      &config.SQS.Port,
+		"DYNAMODB_PORT": &config.DynamoDB.Port,
+	}
+
+	for name, value := range numbers {
+		if env, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.Atoi(env)
+			if err != nil {
+				msg := log.Error("Invalid value %s for %s%s: %v", env, envPrefix, name, err)
+				return errors.New(msg)
+			}
+			*value = parsed
+		}
+	}
+
+	return nil
+}
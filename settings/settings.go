@@ -8,16 +8,21 @@ import (
 
 const (
 	configContextKey = contextKey("config")
+	callerContextKey = contextKey("caller")
 
 	DefaultAccountNumber = "271828182845"
 	DefaultRegion        = "us-west-2"
 
 	DefaultDataPath = "data"
 
-	DefaultLambdaPort = 9002
-	DefaultMotoPort   = 9326
-	DefaultS3Port     = 9000
-	DefaultSqsPort    = 9324
+	DefaultDockerHost = "host.docker.internal"
+
+	DefaultDynamoDbPort = 8000
+	DefaultHttpPort     = 8080
+	DefaultLambdaPort   = 9002
+	DefaultMotoPort     = 9326
+	DefaultS3Port       = 9000
+	DefaultSqsPort      = 9324
 )
 
 type contextKey string
@@ -27,11 +32,29 @@ type Config struct {
 	IsDebug       bool
 	Region        string
 
-	Database *Database
-	Lambda   *Server
-	Moto     *Server
-	S3       *Server
-	SQS      *Server
+	// Accounts maps access key IDs to account numbers, so that requests signed with those keys are made from another
+	// account. Any other access key belongs to AccountNumber.
+	Accounts map[string]string
+
+	// Organizations maps account numbers to the ID of the organization that they belong to, which permissions can be
+	// restricted to. Accounts that aren't listed don't belong to an organization.
+	Organizations map[string]string
+
+	// DockerHost is the hostname that containers use to reach back to myaws.
+	DockerHost string
+
+	// DockerServices starts S3, Moto, ElasticMQ and DynamoDB Local in containers. Without Docker, turn it off to only
+	// serve what myaws implements itself, and run Functions with ExecutorProcess.
+	DockerServices bool
+
+	Database  *Database
+	DynamoDB  *Server
+	Functions *Functions
+	HTTP      *Server
+	Lambda    *Server
+	Moto      *Server
+	S3        *Server
+	SQS       *Server
 
 	dataPath string
 }
@@ -40,6 +63,21 @@ func (config *Config) ArnFragment() string {
 	return config.Region + ":" + config.AccountNumber
 }
 
+// AccountForAccessKey returns the account number that requests signed with the access key ID are made from.
+func (config *Config) AccountForAccessKey(accessKey string) string {
+	account, ok := config.Accounts[accessKey]
+	if ok {
+		return account
+	}
+
+	return config.AccountNumber
+}
+
+// OrganizationForAccount returns the ID of the organization that the account belongs to, or an empty string.
+func (config *Config) OrganizationForAccount(account string) string {
+	return config.Organizations[account]
+}
+
 func (config *Config) DataPath() string {
 	if config.dataPath[0] == '/' {
 		return config.dataPath
@@ -53,6 +91,12 @@ func (config *Config) DataPath() string {
 	return filepath.Join(cwd, config.dataPath)
 }
 
+// DockerEndpoint returns the URL of myaws as seen from inside a Docker container.
+func (config *Config) DockerEndpoint() string {
+	server := Server{Protocol: config.HTTP.Protocol, Host: config.DockerHost, Port: config.HTTP.Port}
+	return server.BuildUrl("")
+}
+
 func (config *Config) DbConnectionString() string {
 	return config.Database.connectionString(config.dataPath)
 }
@@ -70,16 +114,40 @@ func FromContext(ctx context.Context) *Config {
 	return DefaultConfig()
 }
 
+// NewCallerContext records the access key ID that signed the current request.
+func NewCallerContext(ctx context.Context, accessKey string) context.Context {
+	return context.WithValue(ctx, callerContextKey, accessKey)
+}
+
+// CallerAccessKey returns the access key ID that signed the current request, which is empty when it wasn't signed.
+func CallerAccessKey(ctx context.Context) string {
+	accessKey, _ := ctx.Value(callerContextKey).(string)
+	return accessKey
+}
+
+// CallerAccount returns the account number of whoever made the current request.
+func CallerAccount(ctx context.Context) string {
+	cfg := FromContext(ctx)
+	return cfg.AccountForAccessKey(CallerAccessKey(ctx))
+}
+
 func DefaultConfig() *Config {
 	return &Config{
-		AccountNumber: DefaultAccountNumber,
-		IsDebug:       false,
-		Region:        DefaultRegion,
-		Database:      DefaultDatabase(),
-		Lambda:        NewLocalhostServer(DefaultLambdaPort),
-		Moto:          NewLocalhostServer(DefaultMotoPort),
-		S3:            NewLocalhostServer(DefaultS3Port),
-		SQS:           NewLocalhostServer(DefaultSqsPort),
-		dataPath:      DefaultDataPath,
+		AccountNumber:  DefaultAccountNumber,
+		IsDebug:        false,
+		Region:         DefaultRegion,
+		Accounts:       make(map[string]string),
+		Organizations:  make(map[string]string),
+		DockerHost:     DefaultDockerHost,
+		DockerServices: true,
+		Database:       DefaultDatabase(),
+		DynamoDB:       NewLocalhostServer(DefaultDynamoDbPort),
+		Functions:      DefaultFunctions(),
+		HTTP:           NewLocalhostServer(DefaultHttpPort),
+		Lambda:         NewLocalhostServer(DefaultLambdaPort),
+		Moto:           NewLocalhostServer(DefaultMotoPort),
+		S3:             NewLocalhostServer(DefaultS3Port),
+		SQS:            NewLocalhostServer(DefaultSqsPort),
+		dataPath:       DefaultDataPath,
 	}
 }
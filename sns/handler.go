@@ -0,0 +1,163 @@
+package sns
+
+import (
+	"encoding/xml"
+	"myaws/log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/distribution/uuid"
+)
+
+const xmlns = "http://sns.amazonaws.com/doc/2010-03-31/"
+
+const (
+	errorCodeNotFound         = "NotFound"
+	errorCodeInvalidParameter = "InvalidParameter"
+	errorCodeInvalidAction    = "InvalidAction"
+	errorCodeInternal         = "InternalError"
+)
+
+type action func(http.ResponseWriter, *http.Request)
+
+var actions = map[string]action{
+	"CreateTopic":               createTopic,
+	"DeleteTopic":               deleteTopic,
+	"ListTopics":                listTopics,
+	"GetTopicAttributes":        getTopicAttributes,
+	"SetTopicAttributes":        setTopicAttributes,
+	"ListTagsForResource":       listTagsForResource,
+	"Subscribe":                 subscribe,
+	"Unsubscribe":               unsubscribe,
+	"ListSubscriptions":         listSubscriptions,
+	"ListSubscriptionsByTopic":  listSubscriptionsByTopic,
+	"GetSubscriptionAttributes": getSubscriptionAttributes,
+	"SetSubscriptionAttributes": setSubscriptionAttributes,
+	"Publish":                   publish,
+	"PublishBatch":              publishBatch,
+}
+
+// Handler serves SNS's query protocol, dispatching on the Action of the form in the body.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	err := request.ParseForm()
+	if err != nil {
+		msg := log.Error("Unable to parse SNS request: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	name := request.Form.Get("Action")
+	action, ok := actions[name]
+	if !ok {
+		msg := log.Error("Unsupported SNS action %s", name)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidAction, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+type responseMetadata struct {
+	RequestId string
+}
+
+type actionResponse struct {
+	XMLName          xml.Name
+	Xmlns            string `xml:"xmlns,attr"`
+	Result           interface{}
+	ResponseMetadata responseMetadata
+}
+
+// respondWithXml wraps the result, which names its own element, in the response to the request's action. Actions
+// without a result pass nil.
+func respondWithXml(response http.ResponseWriter, request *http.Request, result interface{}) {
+	log.Info("Response: %+v", result)
+
+	body := actionResponse{
+		XMLName:          xml.Name{Local: request.Form.Get("Action") + "Response"},
+		Xmlns:            xmlns,
+		Result:           result,
+		ResponseMetadata: responseMetadata{RequestId: uuid.Generate().String()},
+	}
+
+	response.Header().Set("Content-Type", "text/xml")
+	response.Write([]byte(xml.Header))
+	xml.NewEncoder(response).Encode(body)
+}
+
+type errorDetail struct {
+	Type    string
+	Code    string
+	Message string
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Error     errorDetail
+	RequestId string
+}
+
+func respondWithError(response http.ResponseWriter, statusCode int, code string, message string) {
+	errorType := "Sender"
+	if statusCode >= http.StatusInternalServerError {
+		errorType = "Receiver"
+	}
+
+	response.Header().Set("Content-Type", "text/xml")
+	response.WriteHeader(statusCode)
+	response.Write([]byte(xml.Header))
+	xml.NewEncoder(response).Encode(errorResponse{
+		Xmlns:     xmlns,
+		Error:     errorDetail{Type: errorType, Code: code, Message: message},
+		RequestId: uuid.Generate().String(),
+	})
+}
+
+// members returns the members of a list in a form, like Tags.member.1.Key, in order. Each member maps what follows
+// its index in the keys, like Key, to the values.
+func members(form url.Values, prefix string) []map[string]string {
+	byIndex := make(map[int]map[string]string)
+	for key, values := range form {
+		if !strings.HasPrefix(key, prefix+".") {
+			continue
+		}
+
+		parts := strings.SplitN(key[len(prefix)+1:], ".", 2)
+		index, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+
+		if byIndex[index] == nil {
+			byIndex[index] = make(map[string]string)
+		}
+		byIndex[index][parts[1]] = values[0]
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	results := make([]map[string]string, len(indexes))
+	for i, index := range indexes {
+		results[i] = byIndex[index]
+	}
+
+	return results
+}
+
+// attributes returns a map of attributes in a form, like Attributes.entry.1.key & Attributes.entry.1.value.
+func attributes(form url.Values, prefix string) map[string]string {
+	results := make(map[string]string)
+	for _, member := range members(form, prefix+".entry") {
+		results[member["key"]] = member["value"]
+	}
+
+	return results
+}
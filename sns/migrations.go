@@ -0,0 +1,28 @@
+package sns
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "SNS",
+		Description: "Create Topic & Subscription Tables",
+		Query: `CREATE TABLE IF NOT EXISTS sns_topic (
+					id					integer primary key autoincrement,
+					name				text not null unique,
+					attributes			text not null,
+					tags				text not null,
+					created_on			integer not null
+				);
+				CREATE TABLE IF NOT EXISTS sns_subscription (
+					id					integer primary key autoincrement,
+					subscription_id		text not null unique,
+					topic_name			text not null,
+					protocol			text not null,
+					endpoint			text not null,
+					attributes			text not null,
+					created_on			integer not null,
+					UNIQUE(topic_name, protocol, endpoint)
+				);
+		`,
+	},
+}
@@ -0,0 +1,276 @@
+package sns
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	eventTypes "myaws/events/types"
+	"myaws/lambda"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/sns/queries"
+	"myaws/sns/types"
+	"myaws/sqs"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+const maxEntriesPerPublishBatch = 10
+
+var (
+	dataTypeRegex     = regexp.MustCompile(`^(String|Number|Binary|String\.Array)(\.[A-Za-z0-9_.-]+)?$`)
+	functionNameRegex = regexp.MustCompile(`:function:([A-Za-z0-9_-]+)`)
+)
+
+// newMessage returns the message published by the form, which is either the request's form or the form of an entry
+// of a batch, or why it can't be published.
+func newMessage(ctx context.Context, topic *types.Topic, form url.Values) (types.Message, string, bool) {
+	message := types.Message{
+		MessageId:        uuid.Generate().String(),
+		TopicArn:         topic.GetArn(ctx),
+		Subject:          form.Get("Subject"),
+		Message:          form.Get("Message"),
+		MessageStructure: form.Get("MessageStructure"),
+		Attributes:       make(map[string]types.MessageAttribute),
+		Timestamp:        time.Now(),
+	}
+
+	if len(message.Message) == 0 {
+		return message, "Empty message", false
+	}
+
+	if message.MessageStructure == types.MessageStructureJson {
+		var bodies map[string]string
+		if json.Unmarshal([]byte(message.Message), &bodies) != nil || len(bodies["default"]) == 0 {
+			return message, "Message Structure - JSON message body must have a default string", false
+		}
+	}
+
+	for _, member := range members(form, "MessageAttributes.entry") {
+		attribute := types.MessageAttribute{
+			DataType:    member["Value.DataType"],
+			StringValue: member["Value.StringValue"],
+			BinaryValue: member["Value.BinaryValue"],
+		}
+
+		if !dataTypeRegex.MatchString(attribute.DataType) {
+			return message, "The message attribute '" + member["Name"] + "' has an invalid type", false
+		}
+		message.Attributes[member["Name"]] = attribute
+	}
+
+	return message, "", true
+}
+
+func publish(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	arn := request.Form.Get("TopicArn")
+	if len(arn) == 0 {
+		arn = request.Form.Get("TargetArn")
+	}
+
+	topic := findTopic(response, request, db, arn)
+	if topic == nil {
+		return
+	}
+
+	message, msg, ok := newMessage(ctx, topic, request.Form)
+	if !ok {
+		log.Error(msg)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, "Invalid parameter: "+msg)
+		return
+	}
+
+	// subscriptions are delivered to after responding, like SNS does, so they can't use the request's context
+	go fanOut(cfg.NewContext(context.Background()), topic, message)
+
+	respondWithXml(response, request, types.PublishResult{MessageId: message.MessageId})
+}
+
+func publishBatch(response http.ResponseWriter, request *http.Request) {
+	entries := members(request.Form, "PublishBatchRequestEntries.member")
+	if len(entries) == 0 || len(entries) > maxEntriesPerPublishBatch {
+		msg := log.Error("PublishBatch must have between 1 and %d entries, not %d", maxEntriesPerPublishBatch,
+			len(entries))
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("TopicArn"))
+	if topic == nil {
+		return
+	}
+
+	// the whole batch is rejected before anything is published, so that retrying it doesn't publish entries twice
+	ids := make(map[string]bool)
+	for _, entry := range entries {
+		id := entry["Id"]
+		if ids[id] {
+			msg := log.Error("Two or more batch entries in the request have the same Id %s", id)
+			respondWithError(response, http.StatusBadRequest, "BatchEntryIdsNotDistinct", msg)
+			return
+		}
+		ids[id] = true
+	}
+
+	deliveryCtx := cfg.NewContext(context.Background())
+	result := types.PublishBatchResult{Successful: []types.PublishBatchSuccess{}, Failed: []types.PublishBatchFailure{}}
+	for _, entry := range entries {
+		id := entry["Id"]
+		form := url.Values{}
+		for key, value := range entry {
+			form.Set(key, value)
+		}
+
+		message, msg, ok := newMessage(ctx, topic, form)
+		if !ok {
+			log.Error("Unable to publish entry %s: %s", id, msg)
+			result.Failed = append(result.Failed, types.PublishBatchFailure{
+				Id:          id,
+				Code:        errorCodeInvalidParameter,
+				Message:     msg,
+				SenderFault: true,
+			})
+			continue
+		}
+
+		go fanOut(deliveryCtx, topic, message)
+		result.Successful = append(result.Successful, types.PublishBatchSuccess{Id: id, MessageId: message.MessageId})
+	}
+
+	respondWithXml(response, request, result)
+}
+
+// fanOut delivers the message to each of the topic's subscriptions whose filter policy it matches.
+func fanOut(ctx context.Context, topic *types.Topic, message types.Message) {
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	subscriptions, err := queries.Subscriptions(ctx, db, topic.Name)
+	db.Close()
+
+	if err != nil {
+		return
+	}
+
+	log.Info("Publishing message %s to %d subscriptions of topic %s ...", message.MessageId, len(subscriptions),
+		topic.Name)
+
+	for _, subscription := range subscriptions {
+		if !matchesFilterPolicy(subscription, message) {
+			log.Info("Message %s doesn't match the filter policy of subscription %s", message.MessageId,
+				subscription.SubscriptionId)
+			continue
+		}
+
+		err := deliver(ctx, subscription, message)
+		if err != nil {
+			log.Error("Unable to deliver message %s to %s: %v", message.MessageId, subscription.Endpoint, err)
+		}
+	}
+}
+
+// matchesFilterPolicy reports whether the subscription receives the message. Filter policies use the syntax of
+// EventBridge's event patterns, and are matched against either the message attributes or the message's JSON body.
+func matchesFilterPolicy(subscription types.Subscription, message types.Message) bool {
+	policy := subscription.Attributes[types.AttributeFilterPolicy]
+	if len(policy) == 0 {
+		return true
+	}
+
+	pattern, err := eventTypes.ParsePattern(policy)
+	if err != nil {
+		log.Error("Subscription %s has an invalid filter policy: %v", subscription.SubscriptionId, err)
+		return false
+	}
+
+	if subscription.FilterPolicyScope() == types.FilterPolicyScopeMessageBody {
+		return pattern.Matches([]byte(message.BodyFor(subscription.Protocol)))
+	}
+
+	return pattern.Matches(message.FilterDocument())
+}
+
+func deliver(ctx context.Context, subscription types.Subscription, message types.Message) error {
+	cfg := settings.FromContext(ctx)
+	arn := subscription.GetArn(ctx)
+	unsubscribeUrl := cfg.HTTP.BuildUrl("/?Action=Unsubscribe&SubscriptionArn=" + url.QueryEscape(arn))
+
+	switch subscription.Protocol {
+	case types.ProtocolSqs:
+		queueName := subscription.Endpoint[strings.LastIndex(subscription.Endpoint, ":")+1:]
+		if subscription.IsRawMessageDelivery() {
+			return sqs.SendMessage(ctx, queueName, message.BodyFor(types.ProtocolSqs), queueAttributes(message))
+		}
+
+		body, err := json.Marshal(message.ToNotification(types.ProtocolSqs, unsubscribeUrl))
+		if err != nil {
+			return err
+		}
+		return sqs.SendMessage(ctx, queueName, string(body), nil)
+	case types.ProtocolLambda:
+		payload, err := json.Marshal(message.ToLambdaEvent(arn, unsubscribeUrl))
+		if err != nil {
+			return err
+		}
+		return invokeFunction(ctx, subscription, arn, payload)
+	}
+
+	msg := log.Error("Protocol %s of subscription %s isn't supported", subscription.Protocol, arn)
+	return errors.New(msg)
+}
+
+// queueAttributes returns the message attributes that are sent along with raw messages to queues.
+func queueAttributes(message types.Message) map[string]sqs.MessageAttribute {
+	results := make(map[string]sqs.MessageAttribute, len(message.Attributes))
+	for name, attribute := range message.Attributes {
+		result := sqs.MessageAttribute{DataType: attribute.DataType, StringValue: attribute.StringValue}
+		if strings.HasPrefix(attribute.DataType, "Binary") {
+			binary, err := base64.StdEncoding.DecodeString(attribute.BinaryValue)
+			if err != nil {
+				log.Error("Message attribute %s isn't base64 encoded: %v", name, err)
+				continue
+			}
+			result.BinaryValue = binary
+		}
+		results[name] = result
+	}
+
+	return results
+}
+
+func invokeFunction(ctx context.Context, subscription types.Subscription, arn string, payload []byte) error {
+	groups := functionNameRegex.FindStringSubmatch(subscription.Endpoint)
+	if groups == nil {
+		msg := log.Error("Endpoint of subscription %s isn't the ARN of a Function: %s", arn, subscription.Endpoint)
+		return errors.New(msg)
+	}
+
+	name := groups[1]
+	result, err := lambda.Invoke(lambda.WithInvocationSource(ctx, "sns:"+arn), name, payload)
+	if err != nil {
+		return err
+	}
+
+	if len(result.FunctionError) > 0 {
+		msg := log.Error("Function %s failed to handle message from subscription %s: %s", name, arn, result.Payload)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/sns/types"
+)
+
+func InsertSubscription(ctx context.Context, db *database.Database, subscription *types.Subscription) error {
+	attributes, err := json.Marshal(subscription.Attributes)
+	if err != nil {
+		msg := log.Error("Unable to serialize attributes of subscription %s: %v", subscription.SubscriptionId, err)
+		return errors.New(msg)
+	}
+
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO sns_subscription (subscription_id, topic_name, protocol, endpoint, attributes, created_on)
+				VALUES (?, ?, ?, ?, ?, ?)
+		`,
+		subscription.SubscriptionId,
+		subscription.TopicName,
+		subscription.Protocol,
+		subscription.Endpoint,
+		string(attributes),
+		subscription.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save subscription of %s to topic %s: %v", subscription.Endpoint,
+			subscription.TopicName, err)
+		return errors.New(msg)
+	}
+
+	subscription.ID = id
+	return nil
+}
+
+func UpdateSubscriptionAttributes(ctx context.Context, db *database.Database, subscription *types.Subscription) error {
+	attributes, err := json.Marshal(subscription.Attributes)
+	if err != nil {
+		msg := log.Error("Unable to serialize attributes of subscription %s: %v", subscription.SubscriptionId, err)
+		return errors.New(msg)
+	}
+
+	_, err = db.ExecContext(ctx, `UPDATE sns_subscription SET attributes = ? WHERE id = ?`, string(attributes),
+		subscription.ID)
+	if err != nil {
+		msg := log.Error("Unable to update attributes of subscription %s: %v", subscription.SubscriptionId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectSubscription = `SELECT id, subscription_id, topic_name, protocol, endpoint, attributes, created_on
+				FROM sns_subscription`
+
+// SubscriptionById returns the subscription, or sql.ErrNoRows when there is no such subscription.
+func SubscriptionById(ctx context.Context, db *database.Database, id string) (*types.Subscription, error) {
+	row := db.QueryRowContext(ctx, selectSubscription+` WHERE subscription_id = ?`, id)
+	return scanSubscription(row)
+}
+
+// SubscriptionByEndpoint returns the topic's subscription for the endpoint, or sql.ErrNoRows when there is none.
+func SubscriptionByEndpoint(ctx context.Context, db *database.Database, topicName string, protocol string, endpoint string) (*types.Subscription, error) {
+	row := db.QueryRowContext(ctx, selectSubscription+` WHERE topic_name = ? AND protocol = ? AND endpoint = ?`,
+		topicName, protocol, endpoint)
+	return scanSubscription(row)
+}
+
+// Subscriptions returns the subscriptions of the topic, or of all topics when the name is empty.
+func Subscriptions(ctx context.Context, db *database.Database, topicName string) ([]types.Subscription, error) {
+	rows, err := db.QueryContext(ctx, selectSubscription+` WHERE ? = '' OR topic_name = ? ORDER BY id`, topicName,
+		topicName)
+	if err != nil {
+		msg := log.Error("Unable to query subscriptions: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Subscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *subscription)
+	}
+
+	return results, nil
+}
+
+func scanSubscription(row scanner) (*types.Subscription, error) {
+	var subscription types.Subscription
+	var attributes string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.SubscriptionId,
+		&subscription.TopicName,
+		&subscription.Protocol,
+		&subscription.Endpoint,
+		&attributes,
+		&subscription.CreatedOn,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan subscription: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(attributes), &subscription.Attributes)
+	if err != nil {
+		msg := log.Error("Unable to parse attributes of subscription %s: %v", subscription.SubscriptionId, err)
+		return nil, errors.New(msg)
+	}
+
+	return &subscription, nil
+}
+
+func DeleteSubscription(ctx context.Context, db *database.Database, subscription *types.Subscription) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM sns_subscription WHERE id = ?`, subscription.ID)
+	if err != nil {
+		msg := log.Error("Unable to delete subscription %s: %v", subscription.SubscriptionId, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/sns/types"
+)
+
+// scanner is either a sql.Row or sql.Rows, so that a single function scans a table's columns for both.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// SaveTopic creates the topic, or replaces its attributes & tags when a topic with the same name already exists.
+func SaveTopic(ctx context.Context, db *database.Database, topic *types.Topic) error {
+	attributes, err := json.Marshal(topic.Attributes)
+	if err != nil {
+		msg := log.Error("Unable to serialize attributes of topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	tags, err := json.Marshal(topic.Tags)
+	if err != nil {
+		msg := log.Error("Unable to serialize tags of topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`INSERT INTO sns_topic (name, attributes, tags, created_on) VALUES (?, ?, ?, ?)
+				ON CONFLICT(name) DO UPDATE SET attributes = excluded.attributes, tags = excluded.tags
+		`,
+		topic.Name,
+		string(attributes),
+		string(tags),
+		topic.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+const selectTopic = `SELECT id, name, attributes, tags, created_on FROM sns_topic`
+
+// TopicByName returns the topic, or sql.ErrNoRows when there is no such topic.
+func TopicByName(ctx context.Context, db *database.Database, name string) (*types.Topic, error) {
+	row := db.QueryRowContext(ctx, selectTopic+` WHERE name = ?`, name)
+	return scanTopic(row)
+}
+
+func Topics(ctx context.Context, db *database.Database) ([]types.Topic, error) {
+	rows, err := db.QueryContext(ctx, selectTopic+` ORDER BY name`)
+	if err != nil {
+		msg := log.Error("Unable to query topics: %v", err)
+		return nil, errors.New(msg)
+	}
+	defer rows.Close()
+
+	results := []types.Topic{}
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *topic)
+	}
+
+	return results, nil
+}
+
+func scanTopic(row scanner) (*types.Topic, error) {
+	var topic types.Topic
+	var attributes, tags string
+	err := row.Scan(&topic.ID, &topic.Name, &attributes, &tags, &topic.CreatedOn)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		msg := log.Error("Unable to scan topic: %v", err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(attributes), &topic.Attributes)
+	if err != nil {
+		msg := log.Error("Unable to parse attributes of topic %s: %v", topic.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	err = json.Unmarshal([]byte(tags), &topic.Tags)
+	if err != nil {
+		msg := log.Error("Unable to parse tags of topic %s: %v", topic.Name, err)
+		return nil, errors.New(msg)
+	}
+
+	return &topic, nil
+}
+
+// DeleteTopic deletes the topic along with its subscriptions.
+func DeleteTopic(ctx context.Context, db *database.Database, topic *types.Topic) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		msg := log.Error("Unable to begin transaction to delete topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM sns_subscription WHERE topic_name = ?`, topic.Name)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete subscriptions of topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM sns_topic WHERE id = ?`, topic.ID)
+	if err != nil {
+		msg := tx.Rollback("Unable to delete topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		msg := log.Error("Unable to commit transaction to delete topic %s: %v", topic.Name, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
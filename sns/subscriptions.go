@@ -0,0 +1,281 @@
+package sns
+
+import (
+	"database/sql"
+	"myaws/database"
+	eventTypes "myaws/events/types"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/sns/queries"
+	"myaws/sns/types"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/uuid"
+)
+
+// endpointPrefixes are the prefixes of the endpoints of each supported protocol, which are ARNs.
+var endpointPrefixes = map[string]string{
+	types.ProtocolSqs:    "arn:aws:sqs:",
+	types.ProtocolLambda: "arn:aws:lambda:",
+}
+
+// validateSubscriptionAttribute returns why the attribute can't be set on a subscription when it can't.
+func validateSubscriptionAttribute(name string, value string) (string, bool) {
+	switch name {
+	case types.AttributeRawMessageDelivery:
+		if value != "true" && value != "false" {
+			return "RawMessageDelivery must be true or false", false
+		}
+	case types.AttributeFilterPolicy:
+		if len(value) == 0 {
+			return "", true
+		}
+		_, err := eventTypes.ParsePattern(value)
+		if err != nil {
+			return "FilterPolicy: " + err.Error(), false
+		}
+	case types.AttributeFilterPolicyScope:
+		if value != types.FilterPolicyScopeMessageAttributes && value != types.FilterPolicyScopeMessageBody {
+			return "FilterPolicyScope must be MessageAttributes or MessageBody", false
+		}
+	case "DeliveryPolicy", "RedrivePolicy", "SubscriptionRoleArn":
+	default:
+		return "AttributeName " + name + " is not supported", false
+	}
+
+	return "", true
+}
+
+// findSubscription writes an error response & returns nil when the ARN isn't of an existing subscription.
+func findSubscription(response http.ResponseWriter, request *http.Request, db *database.Database, arn string) *types.Subscription {
+	// the ARN of a subscription is the ARN of its topic followed by its ID
+	index := strings.LastIndex(arn, ":")
+	if index < 0 {
+		index = 0
+	}
+
+	if _, ok := topicName(arn[:index]); !ok {
+		msg := log.Error("Invalid parameter: SubscriptionArn %s", arn)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return nil
+	}
+
+	subscription, err := queries.SubscriptionById(request.Context(), db, arn[index+1:])
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Subscription does not exist: %s", arn)
+		respondWithError(response, http.StatusNotFound, errorCodeNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return nil
+	}
+
+	return subscription
+}
+
+func subscribe(response http.ResponseWriter, request *http.Request) {
+	protocol := request.Form.Get("Protocol")
+	endpoint := request.Form.Get("Endpoint")
+	prefix, ok := endpointPrefixes[protocol]
+	if !ok {
+		msg := log.Error("Invalid parameter: Protocol %s isn't supported, only sqs & lambda are", protocol)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	if !strings.HasPrefix(endpoint, prefix) {
+		msg := log.Error("Invalid parameter: Endpoint %s must be an ARN starting with %s", endpoint, prefix)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	subscriptionAttributes := attributes(request.Form, "Attributes")
+	for name, value := range subscriptionAttributes {
+		if msg, ok := validateSubscriptionAttribute(name, value); !ok {
+			log.Error(msg)
+			respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, "Invalid parameter: "+msg)
+			return
+		}
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("TopicArn"))
+	if topic == nil {
+		return
+	}
+
+	// subscribing the same endpoint again returns the existing subscription, like SNS does
+	existing, err := queries.SubscriptionByEndpoint(ctx, db, topic.Name, protocol, endpoint)
+	if err == nil {
+		respondWithXml(response, request, types.SubscribeResult{SubscriptionArn: existing.GetArn(ctx)})
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	// subscriptions to queues & Functions in the same account don't need to be confirmed
+	subscription := types.Subscription{
+		SubscriptionId: uuid.Generate().String(),
+		TopicName:      topic.Name,
+		Protocol:       protocol,
+		Endpoint:       endpoint,
+		Attributes:     subscriptionAttributes,
+		CreatedOn:      time.Now().UnixMilli(),
+	}
+
+	err = queries.InsertSubscription(ctx, db, &subscription)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, types.SubscribeResult{SubscriptionArn: subscription.GetArn(ctx)})
+}
+
+func unsubscribe(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	subscription := findSubscription(response, request, db, request.Form.Get("SubscriptionArn"))
+	if subscription == nil {
+		return
+	}
+
+	err := queries.DeleteSubscription(ctx, db, subscription)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, nil)
+}
+
+func subscriptionMembers(request *http.Request, db *database.Database, topicName string) ([]types.SubscriptionMember, error) {
+	ctx := request.Context()
+	subscriptions, err := queries.Subscriptions(ctx, db, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := settings.FromContext(ctx)
+	results := make([]types.SubscriptionMember, len(subscriptions))
+	for i, subscription := range subscriptions {
+		results[i] = types.SubscriptionMember{
+			SubscriptionArn: subscription.GetArn(ctx),
+			Owner:           cfg.AccountNumber,
+			Protocol:        subscription.Protocol,
+			Endpoint:        subscription.Endpoint,
+			TopicArn:        subscription.GetTopicArn(ctx),
+		}
+	}
+
+	return results, nil
+}
+
+func listSubscriptions(response http.ResponseWriter, request *http.Request) {
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	subscriptions, err := subscriptionMembers(request, db, "")
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, types.ListSubscriptionsResult{Subscriptions: subscriptions})
+}
+
+func listSubscriptionsByTopic(response http.ResponseWriter, request *http.Request) {
+	cfg := settings.FromContext(request.Context())
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("TopicArn"))
+	if topic == nil {
+		return
+	}
+
+	subscriptions, err := subscriptionMembers(request, db, topic.Name)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, types.ListSubscriptionsByTopicResult{Subscriptions: subscriptions})
+}
+
+func getSubscriptionAttributes(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	subscription := findSubscription(response, request, db, request.Form.Get("SubscriptionArn"))
+	if subscription == nil {
+		return
+	}
+
+	attributes := map[string]string{
+		"SubscriptionArn":                 subscription.GetArn(ctx),
+		"TopicArn":                        subscription.GetTopicArn(ctx),
+		"Protocol":                        subscription.Protocol,
+		"Endpoint":                        subscription.Endpoint,
+		"Owner":                           cfg.AccountNumber,
+		"ConfirmationWasAuthenticated":    "true",
+		"PendingConfirmation":             "false",
+		types.AttributeRawMessageDelivery: "false",
+	}
+
+	for key, value := range subscription.Attributes {
+		attributes[key] = value
+	}
+
+	respondWithXml(response, request, types.GetSubscriptionAttributesResult{Attributes: toEntries(attributes)})
+}
+
+func setSubscriptionAttributes(response http.ResponseWriter, request *http.Request) {
+	name := request.Form.Get("AttributeName")
+	value := request.Form.Get("AttributeValue")
+	if msg, ok := validateSubscriptionAttribute(name, value); !ok {
+		log.Error(msg)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, "Invalid parameter: "+msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	subscription := findSubscription(response, request, db, request.Form.Get("SubscriptionArn"))
+	if subscription == nil {
+		return
+	}
+
+	if len(value) == 0 {
+		delete(subscription.Attributes, name)
+	} else {
+		subscription.Attributes[name] = value
+	}
+
+	err := queries.UpdateSubscriptionAttributes(ctx, db, subscription)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, nil)
+}
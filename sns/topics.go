@@ -0,0 +1,258 @@
+package sns
+
+import (
+	"context"
+	"database/sql"
+	"myaws/database"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/sns/queries"
+	"myaws/sns/types"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var topicNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}(\.fifo)?$`)
+
+// topicName returns the name of the topic in its ARN, or false when it isn't the ARN of a topic.
+func topicName(arn string) (string, bool) {
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || !strings.HasPrefix(arn, "arn:aws:sns:") || !topicNameRegex.MatchString(parts[5]) {
+		return "", false
+	}
+
+	return parts[5], true
+}
+
+// findTopic writes an error response & returns nil when the ARN isn't of an existing topic.
+func findTopic(response http.ResponseWriter, request *http.Request, db *database.Database, arn string) *types.Topic {
+	name, ok := topicName(arn)
+	if !ok {
+		msg := log.Error("Invalid parameter: TopicArn %s", arn)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return nil
+	}
+
+	topic, err := queries.TopicByName(request.Context(), db, name)
+	switch {
+	case err == sql.ErrNoRows:
+		msg := log.Error("Topic does not exist: %s", arn)
+		respondWithError(response, http.StatusNotFound, errorCodeNotFound, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return nil
+	}
+
+	return topic
+}
+
+func tags(form url.Values) []types.Tag {
+	results := []types.Tag{}
+	for _, member := range members(form, "Tags.member") {
+		results = append(results, types.Tag{Key: member["Key"], Value: member["Value"]})
+	}
+
+	return results
+}
+
+func createTopic(response http.ResponseWriter, request *http.Request) {
+	name := request.Form.Get("Name")
+	if !topicNameRegex.MatchString(name) {
+		msg := log.Error("Invalid parameter: Topic Name %s must match %s", name, topicNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	// creating a topic that already exists returns it, like SNS does
+	_, err := queries.TopicByName(ctx, db, name)
+	if err == nil {
+		respondWithXml(response, request, types.CreateTopicResult{TopicArn: types.TopicArn(ctx, name)})
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	topic := types.Topic{
+		Name:       name,
+		Attributes: attributes(request.Form, "Attributes"),
+		Tags:       tags(request.Form),
+		CreatedOn:  time.Now().UnixMilli(),
+	}
+
+	err = queries.SaveTopic(ctx, db, &topic)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, types.CreateTopicResult{TopicArn: topic.GetArn(ctx)})
+}
+
+func deleteTopic(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	name, ok := topicName(request.Form.Get("TopicArn"))
+	if !ok {
+		msg := log.Error("Invalid parameter: TopicArn %s", request.Form.Get("TopicArn"))
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	topic, err := queries.TopicByName(ctx, db, name)
+	if err == sql.ErrNoRows {
+		// deleting a topic that doesn't exist succeeds, like it does in SNS
+		respondWithXml(response, request, nil)
+		return
+	}
+
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	err = queries.DeleteTopic(ctx, db, topic)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, nil)
+}
+
+func listTopics(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topics, err := queries.Topics(ctx, db)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	result := types.ListTopicsResult{Topics: make([]types.TopicMember, len(topics))}
+	for i, topic := range topics {
+		result.Topics[i] = types.TopicMember{TopicArn: topic.GetArn(ctx)}
+	}
+
+	respondWithXml(response, request, result)
+}
+
+// toEntries returns the attributes as entries, sorted by their keys.
+func toEntries(attributes map[string]string) []types.Entry {
+	entries := make([]types.Entry, 0, len(attributes))
+	for key, value := range attributes {
+		entries = append(entries, types.Entry{Key: key, Value: value})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+func topicAttributes(ctx context.Context, db *database.Database, topic *types.Topic) (map[string]string, error) {
+	subscriptions, err := queries.Subscriptions(ctx, db, topic.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := settings.FromContext(ctx)
+	results := map[string]string{
+		"DisplayName":             "",
+		"Owner":                   cfg.AccountNumber,
+		"TopicArn":                topic.GetArn(ctx),
+		"SubscriptionsConfirmed":  strconv.Itoa(len(subscriptions)),
+		"SubscriptionsPending":    "0",
+		"SubscriptionsDeleted":    "0",
+		"FifoTopic":               strconv.FormatBool(strings.HasSuffix(topic.Name, ".fifo")),
+		"EffectiveDeliveryPolicy": `{"http":{"defaultHealthyRetryPolicy":{"numRetries":3}}}`,
+	}
+
+	for key, value := range topic.Attributes {
+		results[key] = value
+	}
+
+	return results, nil
+}
+
+func getTopicAttributes(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("TopicArn"))
+	if topic == nil {
+		return
+	}
+
+	attributes, err := topicAttributes(ctx, db, topic)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, types.GetTopicAttributesResult{Attributes: toEntries(attributes)})
+}
+
+func setTopicAttributes(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("TopicArn"))
+	if topic == nil {
+		return
+	}
+
+	name := request.Form.Get("AttributeName")
+	if len(name) == 0 {
+		msg := log.Error("Invalid parameter: AttributeName is required")
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidParameter, msg)
+		return
+	}
+
+	topic.Attributes[name] = request.Form.Get("AttributeValue")
+	err := queries.SaveTopic(ctx, db, topic)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	respondWithXml(response, request, nil)
+}
+
+func listTagsForResource(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	topic := findTopic(response, request, db, request.Form.Get("ResourceArn"))
+	if topic == nil {
+		return
+	}
+
+	respondWithXml(response, request, types.ListTagsForResourceResult{Tags: topic.Tags})
+}
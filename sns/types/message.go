@@ -0,0 +1,185 @@
+package types
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MessageStructureJson = "json"
+
+	timestampFormat = "2006-01-02T15:04:05.000Z"
+
+	// signature is sent in place of a real one, since myaws doesn't sign messages
+	signature = "EXAMPLE"
+)
+
+type MessageAttribute struct {
+	DataType    string
+	StringValue string
+	BinaryValue string
+}
+
+// Message is a message published to a topic, before being delivered to each of its subscriptions.
+type Message struct {
+	MessageId        string
+	TopicArn         string
+	Subject          string
+	Message          string
+	MessageStructure string
+	Attributes       map[string]MessageAttribute
+	Timestamp        time.Time
+}
+
+// BodyFor returns the message delivered to subscriptions with the protocol. When the MessageStructure is json, the
+// message has a body for each protocol, with a default for the others.
+func (message Message) BodyFor(protocol string) string {
+	if message.MessageStructure != MessageStructureJson {
+		return message.Message
+	}
+
+	var bodies map[string]string
+	if json.Unmarshal([]byte(message.Message), &bodies) != nil {
+		return message.Message
+	}
+
+	if body, ok := bodies[protocol]; ok {
+		return body
+	}
+
+	return bodies["default"]
+}
+
+// FilterDocument returns the message attributes as a JSON object that filter policies are matched against, where
+// Number attributes are numbers & String.Array attributes are arrays. Binary attributes aren't filtered on.
+func (message Message) FilterDocument() []byte {
+	document := make(map[string]interface{})
+	for name, attribute := range message.Attributes {
+		switch {
+		case strings.HasPrefix(attribute.DataType, "Number"):
+			number, err := strconv.ParseFloat(attribute.StringValue, 64)
+			if err == nil {
+				document[name] = number
+			}
+		case attribute.DataType == "String.Array":
+			var values []interface{}
+			if json.Unmarshal([]byte(attribute.StringValue), &values) == nil {
+				document[name] = values
+			}
+		case strings.HasPrefix(attribute.DataType, "String"):
+			document[name] = attribute.StringValue
+		}
+	}
+
+	encoded, _ := json.Marshal(document)
+	return encoded
+}
+
+func (message Message) envelopeAttributes() map[string]EnvelopeAttribute {
+	attributes := make(map[string]EnvelopeAttribute, len(message.Attributes))
+	for name, attribute := range message.Attributes {
+		if strings.HasPrefix(attribute.DataType, "Binary") {
+			attributes[name] = EnvelopeAttribute{Type: "Binary", Value: attribute.BinaryValue}
+			continue
+		}
+
+		attributes[name] = EnvelopeAttribute{Type: attribute.DataType, Value: attribute.StringValue}
+	}
+
+	return attributes
+}
+
+type EnvelopeAttribute struct {
+	Type  string
+	Value string
+}
+
+// Notification is the envelope of messages delivered to queues without raw message delivery.
+type Notification struct {
+	Type              string
+	MessageId         string
+	TopicArn          string
+	Subject           string `json:",omitempty"`
+	Message           string
+	Timestamp         string
+	SignatureVersion  string
+	Signature         string
+	SigningCertURL    string
+	UnsubscribeURL    string
+	MessageAttributes map[string]EnvelopeAttribute `json:",omitempty"`
+}
+
+func (message Message) ToNotification(protocol string, unsubscribeUrl string) Notification {
+	notification := Notification{
+		Type:             "Notification",
+		MessageId:        message.MessageId,
+		TopicArn:         message.TopicArn,
+		Subject:          message.Subject,
+		Message:          message.BodyFor(protocol),
+		Timestamp:        message.Timestamp.UTC().Format(timestampFormat),
+		SignatureVersion: "1",
+		Signature:        signature,
+		UnsubscribeURL:   unsubscribeUrl,
+	}
+
+	if len(message.Attributes) > 0 {
+		notification.MessageAttributes = message.envelopeAttributes()
+	}
+
+	return notification
+}
+
+// LambdaEvent is the event that Functions subscribed to topics are invoked with.
+type LambdaEvent struct {
+	Records []LambdaRecord
+}
+
+type LambdaRecord struct {
+	EventVersion         string
+	EventSubscriptionArn string
+	EventSource          string
+	Sns                  LambdaNotification
+}
+
+// LambdaNotification differs from the Notification sent to queues in the casing of URLs, and in always having a
+// Subject & MessageAttributes.
+type LambdaNotification struct {
+	Type              string
+	MessageId         string
+	TopicArn          string
+	Subject           *string
+	Message           string
+	Timestamp         string
+	SignatureVersion  string
+	Signature         string
+	SigningCertUrl    string
+	UnsubscribeUrl    string
+	MessageAttributes map[string]EnvelopeAttribute
+}
+
+func (message Message) ToLambdaEvent(subscriptionArn string, unsubscribeUrl string) LambdaEvent {
+	var subject *string
+	if len(message.Subject) > 0 {
+		subject = &message.Subject
+	}
+
+	return LambdaEvent{Records: []LambdaRecord{{
+		EventVersion:         "1.0",
+		EventSubscriptionArn: subscriptionArn,
+		EventSource:          "aws:sns",
+		Sns: LambdaNotification{
+			Type:              "Notification",
+			MessageId:         message.MessageId,
+			TopicArn:          message.TopicArn,
+			Subject:           subject,
+			Message:           message.BodyFor(ProtocolLambda),
+			Timestamp:         message.Timestamp.UTC().Format(timestampFormat),
+			SignatureVersion:  "1",
+			Signature:         signature,
+			UnsubscribeUrl:    unsubscribeUrl,
+			MessageAttributes: message.envelopeAttributes(),
+		},
+	}}}
+}
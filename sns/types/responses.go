@@ -0,0 +1,86 @@
+package types
+
+import "encoding/xml"
+
+// SNS uses the query protocol, so these are the XML results of its actions, which are wrapped in a response with
+// the request's ID.
+
+type Entry struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+type TopicMember struct {
+	TopicArn string
+}
+
+type SubscriptionMember struct {
+	SubscriptionArn string
+	Owner           string
+	Protocol        string
+	Endpoint        string
+	TopicArn        string
+}
+
+type CreateTopicResult struct {
+	XMLName  xml.Name `xml:"CreateTopicResult"`
+	TopicArn string
+}
+
+type ListTopicsResult struct {
+	XMLName xml.Name      `xml:"ListTopicsResult"`
+	Topics  []TopicMember `xml:"Topics>member"`
+}
+
+type GetTopicAttributesResult struct {
+	XMLName    xml.Name `xml:"GetTopicAttributesResult"`
+	Attributes []Entry  `xml:"Attributes>entry"`
+}
+
+type GetSubscriptionAttributesResult struct {
+	XMLName    xml.Name `xml:"GetSubscriptionAttributesResult"`
+	Attributes []Entry  `xml:"Attributes>entry"`
+}
+
+type ListTagsForResourceResult struct {
+	XMLName xml.Name `xml:"ListTagsForResourceResult"`
+	Tags    []Tag    `xml:"Tags>member"`
+}
+
+type SubscribeResult struct {
+	XMLName         xml.Name `xml:"SubscribeResult"`
+	SubscriptionArn string
+}
+
+type ListSubscriptionsResult struct {
+	XMLName       xml.Name             `xml:"ListSubscriptionsResult"`
+	Subscriptions []SubscriptionMember `xml:"Subscriptions>member"`
+}
+
+type ListSubscriptionsByTopicResult struct {
+	XMLName       xml.Name             `xml:"ListSubscriptionsByTopicResult"`
+	Subscriptions []SubscriptionMember `xml:"Subscriptions>member"`
+}
+
+type PublishResult struct {
+	XMLName   xml.Name `xml:"PublishResult"`
+	MessageId string
+}
+
+type PublishBatchSuccess struct {
+	Id        string
+	MessageId string
+}
+
+type PublishBatchFailure struct {
+	Id          string
+	Code        string
+	Message     string
+	SenderFault bool
+}
+
+type PublishBatchResult struct {
+	XMLName    xml.Name              `xml:"PublishBatchResult"`
+	Successful []PublishBatchSuccess `xml:"Successful>member"`
+	Failed     []PublishBatchFailure `xml:"Failed>member"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"context"
+	"strconv"
+)
+
+const (
+	ProtocolSqs    = "sqs"
+	ProtocolLambda = "lambda"
+
+	AttributeRawMessageDelivery = "RawMessageDelivery"
+	AttributeFilterPolicy       = "FilterPolicy"
+	AttributeFilterPolicyScope  = "FilterPolicyScope"
+
+	FilterPolicyScopeMessageAttributes = "MessageAttributes"
+	FilterPolicyScopeMessageBody       = "MessageBody"
+)
+
+type Subscription struct {
+	ID             int64
+	SubscriptionId string
+	TopicName      string
+	Protocol       string
+	Endpoint       string
+	Attributes     map[string]string
+	CreatedOn      int64
+}
+
+func (subscription Subscription) GetTopicArn(ctx context.Context) string {
+	return TopicArn(ctx, subscription.TopicName)
+}
+
+// GetArn returns the ARN of the subscription, which is the ARN of its topic followed by its ID.
+func (subscription Subscription) GetArn(ctx context.Context) string {
+	return subscription.GetTopicArn(ctx) + ":" + subscription.SubscriptionId
+}
+
+func (subscription Subscription) IsRawMessageDelivery() bool {
+	raw, _ := strconv.ParseBool(subscription.Attributes[AttributeRawMessageDelivery])
+	return raw
+}
+
+// FilterPolicyScope returns what the filter policy applies to, which is the message attributes unless set otherwise.
+func (subscription Subscription) FilterPolicyScope() string {
+	scope := subscription.Attributes[AttributeFilterPolicyScope]
+	if len(scope) == 0 {
+		return FilterPolicyScopeMessageAttributes
+	}
+
+	return scope
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"context"
+	"myaws/settings"
+)
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type Topic struct {
+	ID         int64
+	Name       string
+	Attributes map[string]string
+	Tags       []Tag
+	CreatedOn  int64
+}
+
+func TopicArn(ctx context.Context, name string) string {
+	cfg := settings.FromContext(ctx)
+	return "arn:aws:sns:" + cfg.ArnFragment() + ":" + name
+}
+
+func (topic Topic) GetArn(ctx context.Context) string {
+	return TopicArn(ctx, topic.Name)
+}
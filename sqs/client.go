@@ -0,0 +1,69 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"myaws/log"
+	"myaws/settings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+func newClient(cfg *settings.Config) *sqs.Client {
+	endpoint := cfg.SQS.BuildUrl("")
+	return sqs.NewFromConfig(aws.Config{
+		Region: cfg.Region,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+		EndpointResolverWithOptions: aws.EndpointResolverWithOptionsFunc(
+			func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+				return aws.Endpoint{URL: endpoint, HostnameImmutable: true}, nil
+			},
+		),
+	})
+}
+
+// MessageAttribute is an attribute sent along with a message, which has either a StringValue or a BinaryValue
+// depending on its DataType.
+type MessageAttribute struct {
+	DataType    string
+	StringValue string
+	BinaryValue []byte
+}
+
+// SendMessage sends the body to the named queue in ElasticMQ, for services that deliver to queues, like EventBridge.
+func SendMessage(ctx context.Context, queueName string, body string, attributes map[string]MessageAttribute) error {
+	cfg := settings.FromContext(ctx)
+	client := newClient(cfg)
+
+	urlOutput, err := client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: &queueName})
+	if err != nil {
+		msg := log.Error("Unable to find URL of queue %s: %v", queueName, err)
+		return errors.New(msg)
+	}
+
+	input := sqs.SendMessageInput{QueueUrl: urlOutput.QueueUrl, MessageBody: &body}
+	if len(attributes) > 0 {
+		input.MessageAttributes = make(map[string]sqsTypes.MessageAttributeValue, len(attributes))
+		for name, attribute := range attributes {
+			value := sqsTypes.MessageAttributeValue{DataType: aws.String(attribute.DataType)}
+			if attribute.BinaryValue != nil {
+				value.BinaryValue = attribute.BinaryValue
+			} else {
+				value.StringValue = aws.String(attribute.StringValue)
+			}
+			input.MessageAttributes[name] = value
+		}
+	}
+
+	_, err = client.SendMessage(ctx, &input)
+	if err != nil {
+		msg := log.Error("Unable to send message to queue %s: %v", queueName, err)
+		return errors.New(msg)
+	}
+
+	return nil
+}
@@ -72,7 +72,7 @@ func writeConfigFile(cfg *settings.Config, configPath string) error {
 	}
 
 	if err == nil {
-		log.Info("The file %s already exists, so returning without creating.")
+		log.Info("The file %s already exists, so returning without creating.", configPath)
 		return nil
 	}
 
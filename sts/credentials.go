@@ -0,0 +1,223 @@
+package sts
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"myaws/database"
+	"myaws/iam"
+	"myaws/log"
+	"myaws/settings"
+	"myaws/sts/queries"
+	"myaws/sts/types"
+	"myaws/utils"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minDurationSeconds     = 900
+	maxDurationSeconds     = 43200
+	defaultDurationSeconds = 3600
+)
+
+var (
+	roleArnRegex         = regexp.MustCompile(`^arn:aws:iam::\d{12}:role/(?:[\x21-\x7e]*/)?([\w+=,.@-]{1,64})$`)
+	roleSessionNameRegex = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+)
+
+// randomKey returns a random base64 string of the bytes, for the secrets of temporary credentials.
+func randomKey(bytes int) string {
+	key := make([]byte, bytes)
+	rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func getCallerIdentity(response http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	session, err := queries.SessionByAccessKeyId(ctx, db, settings.CallerAccessKey(ctx))
+	switch {
+	case err == sql.ErrNoRows:
+		// credentials that myaws didn't issue are those of the root user of their account
+		account := settings.CallerAccount(ctx)
+		respondWithXml(response, request, types.GetCallerIdentityResult{
+			Arn:     "arn:aws:iam::" + account + ":root",
+			UserId:  account,
+			Account: account,
+		})
+		return
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return
+	}
+
+	if session.IsExpired() {
+		msg := log.Error("The security token included in the request is expired")
+		respondWithError(response, http.StatusForbidden, errorCodeExpiredToken, msg)
+		return
+	}
+
+	respondWithXml(response, request, types.GetCallerIdentityResult{
+		Arn:     session.GetArn(),
+		UserId:  session.ToAssumedRoleUser().AssumedRoleId,
+		Account: session.Account(),
+	})
+}
+
+// newSession writes an error response & returns nil when the form doesn't ask for a role held by Moto. Otherwise it
+// issues temporary credentials for the role. Trust policies aren't evaluated, so any role can be assumed.
+func newSession(response http.ResponseWriter, request *http.Request) *types.Session {
+	roleArn := request.Form.Get("RoleArn")
+	groups := roleArnRegex.FindStringSubmatch(roleArn)
+	if groups == nil {
+		msg := log.Error("RoleArn %s isn't the ARN of a role", roleArn)
+		respondWithError(response, http.StatusBadRequest, errorCodeValidation, msg)
+		return nil
+	}
+
+	sessionName := request.Form.Get("RoleSessionName")
+	if !roleSessionNameRegex.MatchString(sessionName) {
+		msg := log.Error("RoleSessionName %s must match %s", sessionName, roleSessionNameRegex)
+		respondWithError(response, http.StatusBadRequest, errorCodeValidation, msg)
+		return nil
+	}
+
+	duration := defaultDurationSeconds
+	if value := request.Form.Get("DurationSeconds"); len(value) > 0 {
+		var err error
+		duration, err = strconv.Atoi(value)
+		if err != nil || duration < minDurationSeconds || duration > maxDurationSeconds {
+			msg := log.Error("DurationSeconds must be between %d and %d, not %s", minDurationSeconds,
+				maxDurationSeconds, value)
+			respondWithError(response, http.StatusBadRequest, errorCodeValidation, msg)
+			return nil
+		}
+	}
+
+	ctx := request.Context()
+	role, err := iam.GetRole(ctx, groups[1])
+	switch {
+	case err == iam.ErrNoSuchRole:
+		msg := log.Error("Not authorized to perform sts:AssumeRole on %s, since the role doesn't exist", roleArn)
+		respondWithError(response, http.StatusForbidden, errorCodeAccessDenied, msg)
+		return nil
+	case err != nil:
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return nil
+	}
+
+	now := time.Now()
+	session := types.Session{
+		AccessKeyId:     "ASIA" + strings.ToUpper(utils.RandomId(16)),
+		SecretAccessKey: randomKey(30),
+		SessionToken:    randomKey(120),
+		RoleArn:         roleArn,
+		RoleId:          role.RoleId,
+		SessionName:     sessionName,
+		Expiration:      now.Add(time.Duration(duration) * time.Second).UnixMilli(),
+		CreatedOn:       now.UnixMilli(),
+	}
+
+	cfg := settings.FromContext(ctx)
+	db := database.CreateConnection(cfg)
+	defer db.Close()
+
+	err = queries.InsertSession(ctx, db, &session)
+	if err != nil {
+		respondWithError(response, http.StatusInternalServerError, errorCodeInternal, err.Error())
+		return nil
+	}
+
+	return &session
+}
+
+func assumeRole(response http.ResponseWriter, request *http.Request) {
+	session := newSession(response, request)
+	if session == nil {
+		return
+	}
+
+	respondWithXml(response, request, types.AssumeRoleResult{
+		Credentials:     session.ToCredentials(),
+		AssumedRoleUser: session.ToAssumedRoleUser(),
+	})
+}
+
+type webIdentityClaims struct {
+	Subject  string      `json:"sub"`
+	Issuer   string      `json:"iss"`
+	Audience interface{} `json:"aud"`
+}
+
+// parseWebIdentityToken returns the claims of the JSON Web Token, or false when it isn't one. Its signature isn't
+// verified, since myaws doesn't know the identity providers.
+func parseWebIdentityToken(token string) (*webIdentityClaims, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+
+	var claims webIdentityClaims
+	if json.Unmarshal(payload, &claims) != nil || len(claims.Subject) == 0 {
+		return nil, false
+	}
+
+	return &claims, true
+}
+
+// audience returns the audience of the token, which is either a single audience or the first of many.
+func (claims webIdentityClaims) audience() string {
+	switch audience := claims.Audience.(type) {
+	case string:
+		return audience
+	case []interface{}:
+		if len(audience) > 0 {
+			value, _ := audience[0].(string)
+			return value
+		}
+	}
+
+	return ""
+}
+
+// assumeRoleWithWebIdentity isn't signed, since the token stands in for credentials, so it's routed to STS by the
+// lack of a signature.
+func assumeRoleWithWebIdentity(response http.ResponseWriter, request *http.Request) {
+	claims, ok := parseWebIdentityToken(request.Form.Get("WebIdentityToken"))
+	if !ok {
+		msg := log.Error("The web identity token that was passed could not be validated")
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidIdentityToken, msg)
+		return
+	}
+
+	session := newSession(response, request)
+	if session == nil {
+		return
+	}
+
+	provider := request.Form.Get("ProviderId")
+	if len(provider) == 0 {
+		provider = strings.TrimPrefix(claims.Issuer, "https://")
+	}
+
+	respondWithXml(response, request, types.AssumeRoleWithWebIdentityResult{
+		Credentials:                 session.ToCredentials(),
+		AssumedRoleUser:             session.ToAssumedRoleUser(),
+		SubjectFromWebIdentityToken: claims.Subject,
+		Provider:                    provider,
+		Audience:                    claims.audience(),
+	})
+}
@@ -0,0 +1,106 @@
+package sts
+
+import (
+	"encoding/xml"
+	"myaws/log"
+	"net/http"
+
+	"github.com/docker/distribution/uuid"
+)
+
+const xmlns = "https://sts.amazonaws.com/doc/2011-06-15/"
+
+const (
+	errorCodeAccessDenied         = "AccessDenied"
+	errorCodeExpiredToken         = "ExpiredToken"
+	errorCodeInvalidIdentityToken = "InvalidIdentityToken"
+	errorCodeValidation           = "ValidationError"
+	errorCodeInvalidAction        = "InvalidAction"
+	errorCodeInternal             = "InternalFailure"
+)
+
+type action func(http.ResponseWriter, *http.Request)
+
+// ActionAssumeRoleWithWebIdentity is the action of the only unsigned requests to STS.
+const ActionAssumeRoleWithWebIdentity = "AssumeRoleWithWebIdentity"
+
+var actions = map[string]action{
+	"GetCallerIdentity":             getCallerIdentity,
+	"AssumeRole":                    assumeRole,
+	ActionAssumeRoleWithWebIdentity: assumeRoleWithWebIdentity,
+}
+
+// Handler serves STS's query protocol, dispatching on the Action of the form in the body.
+func Handler(response http.ResponseWriter, request *http.Request) {
+	err := request.ParseForm()
+	if err != nil {
+		msg := log.Error("Unable to parse STS request: %v", err)
+		respondWithError(response, http.StatusBadRequest, errorCodeValidation, msg)
+		return
+	}
+
+	name := request.Form.Get("Action")
+	action, ok := actions[name]
+	if !ok {
+		msg := log.Error("Unsupported STS action %s", name)
+		respondWithError(response, http.StatusBadRequest, errorCodeInvalidAction, msg)
+		return
+	}
+
+	action(response, request)
+}
+
+type responseMetadata struct {
+	RequestId string
+}
+
+type actionResponse struct {
+	XMLName          xml.Name
+	Xmlns            string `xml:"xmlns,attr"`
+	Result           interface{}
+	ResponseMetadata responseMetadata
+}
+
+// respondWithXml wraps the result, which names its own element, in the response to the request's action. Unlike
+// other services, the result isn't logged, since it may hold credentials.
+func respondWithXml(response http.ResponseWriter, request *http.Request, result interface{}) {
+	body := actionResponse{
+		XMLName:          xml.Name{Local: request.Form.Get("Action") + "Response"},
+		Xmlns:            xmlns,
+		Result:           result,
+		ResponseMetadata: responseMetadata{RequestId: uuid.Generate().String()},
+	}
+
+	response.Header().Set("Content-Type", "text/xml")
+	response.Write([]byte(xml.Header))
+	xml.NewEncoder(response).Encode(body)
+}
+
+type errorDetail struct {
+	Type    string
+	Code    string
+	Message string
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Error     errorDetail
+	RequestId string
+}
+
+func respondWithError(response http.ResponseWriter, statusCode int, code string, message string) {
+	errorType := "Sender"
+	if statusCode >= http.StatusInternalServerError {
+		errorType = "Receiver"
+	}
+
+	response.Header().Set("Content-Type", "text/xml")
+	response.WriteHeader(statusCode)
+	response.Write([]byte(xml.Header))
+	xml.NewEncoder(response).Encode(errorResponse{
+		Xmlns:     xmlns,
+		Error:     errorDetail{Type: errorType, Code: code, Message: message},
+		RequestId: uuid.Generate().String(),
+	})
+}
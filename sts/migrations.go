@@ -0,0 +1,22 @@
+package sts
+
+import "myaws/database"
+
+var Migrations = []database.Migration{
+	{
+		Service:     "STS",
+		Description: "Create Session Table",
+		Query: `CREATE TABLE IF NOT EXISTS sts_session (
+					id					integer primary key autoincrement,
+					access_key_id		text not null unique,
+					secret_access_key	text not null,
+					session_token		text not null,
+					role_arn			text not null,
+					role_id				text not null,
+					session_name		text not null,
+					expiration			integer not null,
+					created_on			integer not null
+				);
+		`,
+	},
+}
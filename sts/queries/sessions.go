@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"myaws/database"
+	"myaws/log"
+	"myaws/sts/types"
+)
+
+func InsertSession(ctx context.Context, db *database.Database, session *types.Session) error {
+	id, err := db.InsertOne(
+		ctx,
+		`INSERT INTO sts_session (access_key_id, secret_access_key, session_token, role_arn, role_id, session_name,
+					expiration, created_on)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.AccessKeyId,
+		session.SecretAccessKey,
+		session.SessionToken,
+		session.RoleArn,
+		session.RoleId,
+		session.SessionName,
+		session.Expiration,
+		session.CreatedOn,
+	)
+
+	if err != nil {
+		msg := log.Error("Unable to save session %s of role %s: %v", session.SessionName, session.RoleArn, err)
+		return errors.New(msg)
+	}
+
+	session.ID = id
+	return nil
+}
+
+// SessionByAccessKeyId returns the session with the credentials, or sql.ErrNoRows when there is no such session.
+func SessionByAccessKeyId(ctx context.Context, db *database.Database, accessKeyId string) (*types.Session, error) {
+	var session types.Session
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT id, access_key_id, secret_access_key, session_token, role_arn, role_id, session_name, expiration,
+					created_on
+				FROM sts_session WHERE access_key_id = ?`,
+		accessKeyId,
+	).Scan(
+		&session.ID,
+		&session.AccessKeyId,
+		&session.SecretAccessKey,
+		&session.SessionToken,
+		&session.RoleArn,
+		&session.RoleId,
+		&session.SessionName,
+		&session.Expiration,
+		&session.CreatedOn,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
@@ -0,0 +1,42 @@
+package types
+
+import "encoding/xml"
+
+// STS uses the query protocol, so these are the XML results of its actions, which are wrapped in a response with
+// the request's ID.
+
+type Credentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      string
+}
+
+type AssumedRoleUser struct {
+	AssumedRoleId string
+	Arn           string
+}
+
+type GetCallerIdentityResult struct {
+	XMLName xml.Name `xml:"GetCallerIdentityResult"`
+	Arn     string
+	UserId  string
+	Account string
+}
+
+type AssumeRoleResult struct {
+	XMLName          xml.Name `xml:"AssumeRoleResult"`
+	Credentials      Credentials
+	AssumedRoleUser  AssumedRoleUser
+	PackedPolicySize int
+}
+
+type AssumeRoleWithWebIdentityResult struct {
+	XMLName                     xml.Name `xml:"AssumeRoleWithWebIdentityResult"`
+	Credentials                 Credentials
+	AssumedRoleUser             AssumedRoleUser
+	SubjectFromWebIdentityToken string
+	Provider                    string
+	Audience                    string
+	PackedPolicySize            int
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Session holds the temporary credentials issued for a role, which are valid until they expire. Expiration is in
+// milliseconds.
+type Session struct {
+	ID              int64
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	RoleArn         string
+	RoleId          string
+	SessionName     string
+	Expiration      int64
+	CreatedOn       int64
+}
+
+// Account returns the account of the session's role.
+func (session Session) Account() string {
+	return strings.Split(session.RoleArn, ":")[4]
+}
+
+// GetArn returns the ARN of the assumed role, which is what requests signed with the session's credentials are made
+// by.
+func (session Session) GetArn() string {
+	name := session.RoleArn[strings.LastIndex(session.RoleArn, "/")+1:]
+	return "arn:aws:sts::" + session.Account() + ":assumed-role/" + name + "/" + session.SessionName
+}
+
+func (session Session) IsExpired() bool {
+	return session.Expiration <= time.Now().UnixMilli()
+}
+
+func (session Session) ToCredentials() Credentials {
+	return Credentials{
+		AccessKeyId:     session.AccessKeyId,
+		SecretAccessKey: session.SecretAccessKey,
+		SessionToken:    session.SessionToken,
+		Expiration:      time.UnixMilli(session.Expiration).UTC().Format(time.RFC3339),
+	}
+}
+
+func (session Session) ToAssumedRoleUser() AssumedRoleUser {
+	return AssumedRoleUser{AssumedRoleId: session.RoleId + ":" + session.SessionName, Arn: session.GetArn()}
+}
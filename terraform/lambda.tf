@@ -90,12 +90,6 @@ resource "aws_lambda_function" "copy_file" {
   handler          = "main.handler"
   filename         = data.archive_file.copy_file.output_path
   source_code_hash = data.archive_file.copy_file.output_base64sha256
-
-  environment {
-    variables = {
-      ENDPOINT = "http://localhost:8080"
-    }
-  }
 }
 
 resource "aws_lambda_permission" "copy_file" {
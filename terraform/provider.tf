@@ -8,7 +8,7 @@ provider "aws" {
     s3     = var.endpoints.s3
     sqs    = var.endpoints.sqs
     ssm    = var.endpoints.ssm
-#    sts    = var.endpoints.sts
+    sts    = var.endpoints.sts
   }
 }
 
@@ -2,8 +2,10 @@ package utils
 
 import (
 	"errors"
+	"io"
 	"myaws/log"
 	"os"
+	"path/filepath"
 )
 
 func CreateDirs(dirPath string) error {
@@ -16,3 +18,109 @@ func CreateDirs(dirPath string) error {
 
 	return nil
 }
+
+// DirSize returns the total size of the regular files under the directory.
+func DirSize(dirPath string) (int64, error) {
+	var total int64
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		msg := log.Error("Unable to calculate size of %s: %v", dirPath, err)
+		return 0, errors.New(msg)
+	}
+
+	return total, nil
+}
+
+// MakeFilesReadOnly removes the write permissions of the regular files under the directory, so that the files hard
+// linked to them can't be changed either.
+func MakeFilesReadOnly(dirPath string) error {
+	return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return os.Chmod(path, info.Mode().Perm()&^0222)
+	})
+}
+
+// OverlayDir makes everything under srcPath available under destPath, replacing what is already there. Files are
+// hard linked so that nothing is copied, unless the directories are on different file systems.
+func OverlayDir(srcPath string, destPath string) error {
+	return filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(srcPath, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(destPath, relative)
+		if info.IsDir() {
+			existing, err := os.Lstat(target)
+			if err == nil && !existing.IsDir() {
+				err = os.Remove(target)
+				if err != nil {
+					return err
+				}
+			}
+
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		err = os.RemoveAll(target)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		}
+
+		err = os.Link(path, target)
+		if err != nil {
+			log.Debug("Unable to link %s, copying instead: %v", path, err)
+			return copyFile(path, target, info.Mode().Perm())
+		}
+
+		return nil
+	})
+}
+
+func copyFile(srcPath string, destPath string, mode os.FileMode) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dest, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	_, err = io.Copy(dest, src)
+	return err
+}
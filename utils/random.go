@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const idAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// RandomId returns a random string of lowercase letters & digits, like the IDs that AWS generates for resources.
+func RandomId(length int) string {
+	id := make([]byte, length)
+	max := big.NewInt(int64(len(idAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		id[i] = idAlphabet[n.Int64()]
+	}
+
+	return string(id)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"hash/fnv"
+	"myaws/log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// WatchDirs polls the directories every interval and sends on the returned channel once files under them have been
+// added, removed or modified. A change is only reported after the directories have been stable for one interval, so
+// that a burst of writes (e.g. a build) results in a single notification. The channel is closed when ctx is done.
+func WatchDirs(ctx context.Context, interval time.Duration, dirs ...string) <-chan bool {
+	changes := make(chan bool)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := fingerprintDirs(dirs)
+		pending := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := fingerprintDirs(dirs)
+				switch {
+				case current != last:
+					log.Debug("Detected changes in %v", dirs)
+					last = current
+					pending = true
+				case pending:
+					pending = false
+					select {
+					case changes <- true:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+func fingerprintDirs(dirs []string) uint64 {
+	hash := fnv.New64a()
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				hash.Write([]byte(path + ":error"))
+				return nil
+			}
+
+			hash.Write([]byte(path))
+			hash.Write([]byte(strconv.FormatInt(info.Size(), 10)))
+			hash.Write([]byte(strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+			return nil
+		})
+	}
+
+	return hash.Sum64()
+}
@@ -3,10 +3,26 @@ package utils
 import (
 	"archive/zip"
 	"errors"
+	"fmt"
 	"io"
 	"myaws/log"
 	"os"
 	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxUncompressedZipSize is the most that is extracted from a single zip, which is Lambda's limit for a
+	// Function's code and Layers combined.
+	MaxUncompressedZipSize = 262144000
+
+	// MaxZipEntries is the most files & directories that are extracted from a single zip.
+	MaxZipEntries = 65536
+
+	maxSymlinkLength = 4096
+
+	// creatorUnix is the "version made by" host for zips with Unix attributes
+	creatorUnix = 3
 )
 
 func min(a int, b int64) int64 {
@@ -35,8 +51,8 @@ func (source ZipContent) ReadAt(p []byte, off int64) (n int, err error) {
 	return count, nil
 }
 
-func saveFile(filePath string, file zip.File) error {
-	destFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+func saveFile(filePath string, file *zip.File, mode os.FileMode, remaining *int64) error {
+	destFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
 		msg := log.Error("unable to open file %s: %v", filePath, err)
 		return errors.New(msg)
@@ -50,12 +66,26 @@ func saveFile(filePath string, file zip.File) error {
 	}
 	defer fileInArchive.Close()
 
-	_, err = io.Copy(destFile, fileInArchive)
+	// the sizes in the zip's headers can't be trusted, so count what is actually written
+	written, err := io.Copy(destFile, io.LimitReader(fileInArchive, *remaining+1))
 	if err != nil {
 		msg := log.Error("problem decompressing file %s: %v", file.Name, err)
 		return errors.New(msg)
 	}
 
+	*remaining -= written
+	if *remaining < 0 {
+		msg := log.Error("zip is larger than %d bytes once uncompressed", MaxUncompressedZipSize)
+		return errors.New(msg)
+	}
+
+	// the mode is only applied by OpenFile when the file is created, and then with the umask
+	err = os.Chmod(filePath, mode)
+	if err != nil {
+		msg := log.Error("unable to set mode of file %s: %v", filePath, err)
+		return errors.New(msg)
+	}
+
 	return nil
 }
 
@@ -81,30 +111,308 @@ func UncompressZipFileBytes(bytes []byte, destPath string) error {
 	return decompressZipFile(reader, destPath)
 }
 
+// UncompressedZipSize returns the total size of the files in the zip once they are uncompressed.
+func UncompressedZipSize(bytes []byte) (int64, error) {
+	content := ZipContent{Content: bytes, Length: int64(len(bytes))}
+	reader, err := zip.NewReader(content, content.Length)
+	if err != nil {
+		msg := log.Error("Unable to read zip from bytes: %v", err)
+		return 0, errors.New(msg)
+	}
+
+	return uncompressedSize(reader), nil
+}
+
+// UncompressedZipFileSize returns the total size of the files in the zip file once they are uncompressed.
+func UncompressedZipFileSize(file string) (int64, error) {
+	reader, err := zip.OpenReader(file)
+	if err != nil {
+		msg := log.Error("Unable to read zip from file %s: %v", file, err)
+		return 0, errors.New(msg)
+	}
+	defer reader.Close()
+
+	return uncompressedSize(&reader.Reader), nil
+}
+
+func uncompressedSize(reader *zip.Reader) int64 {
+	var total int64
+	for _, f := range reader.File {
+		total += int64(f.UncompressedSize64)
+	}
+
+	return total
+}
+
+// extractionPath returns where an entry of the zip is extracted to, or an error when the entry would escape the
+// destination (zip-slip).
+func extractionPath(destPath string, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) {
+		return "", fmt.Errorf("entry %s has an absolute path", name)
+	}
+
+	filePath := filepath.Join(destPath, name)
+	if !isWithin(destPath, filePath) {
+		return "", fmt.Errorf("entry %s is outside of %s", name, destPath)
+	}
+
+	return filePath, nil
+}
+
+func isWithin(dir string, path string) bool {
+	relative, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// fileMode returns the permissions to extract a file with. Zips created without Unix attributes have no executable
+// bits, so a bootstrap at the root is made executable as Lambda would otherwise be unable to run it.
+func fileMode(file *zip.File) os.FileMode {
+	mode := file.Mode().Perm()
+	if file.CreatorVersion>>8 != creatorUnix {
+		mode = 0644
+		if file.Name == "bootstrap" {
+			mode = 0755
+		}
+	}
+
+	// files must at least be readable by whoever runs the Function
+	return mode | 0444
+}
+
+// symlinkParent returns the first directory from destPath down to dir that is a symlink, or an empty string. Nothing
+// is written through a symlink, whether it came from this zip or an earlier one.
+func symlinkParent(destPath string, dir string) (string, error) {
+	relative, err := filepath.Rel(destPath, dir)
+	if err != nil {
+		return "", err
+	}
+
+	current := destPath
+	for _, part := range strings.Split(relative, string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return current, nil
+		}
+	}
+
+	return "", nil
+}
+
+// resolveSymlink returns where the target of a symlink in linkDir points to, following the symlinks that already
+// exist along the way like the kernel would. Parts of the target that don't exist yet are resolved as they are.
+func resolveSymlink(linkDir string, target string) (string, error) {
+	current, err := filepath.EvalSymlinks(linkDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			continue
+		}
+
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			current, err = filepath.EvalSymlinks(current)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return current, nil
+}
+
+// symlinkEscapes reports whether the symlink at filePath points outside of the real path of the destination.
+func symlinkEscapes(realDest string, filePath string, target string) bool {
+	resolved, err := resolveSymlink(filepath.Dir(filePath), target)
+	return err != nil || !isWithin(realDest, resolved)
+}
+
+func readSymlink(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		msg := log.Error("unable to decompress symlink %s: %v", file.Name, err)
+		return "", errors.New(msg)
+	}
+	defer reader.Close()
+
+	target, err := io.ReadAll(io.LimitReader(reader, maxSymlinkLength+1))
+	if err != nil {
+		msg := log.Error("problem decompressing symlink %s: %v", file.Name, err)
+		return "", errors.New(msg)
+	}
+
+	if len(target) == 0 || len(target) > maxSymlinkLength || filepath.IsAbs(string(target)) {
+		msg := log.Error("symlink %s has an invalid target %s", file.Name, target)
+		return "", errors.New(msg)
+	}
+
+	return string(target), nil
+}
+
+// saveSymlinks creates the zip's symlinks once everything else is extracted, so that no entry of the zip is written
+// through one. Each symlink is checked again after all of them exist, as one can redirect the target of another.
+func saveSymlinks(realDest string, filePaths []string, files []*zip.File) error {
+	targets := make([]string, len(files))
+	for i, file := range files {
+		target, err := readSymlink(file)
+		if err != nil {
+			return err
+		}
+
+		if symlinkEscapes(realDest, filePaths[i], target) {
+			msg := log.Error("symlink %s points outside of %s", file.Name, realDest)
+			return errors.New(msg)
+		}
+
+		err = os.Symlink(target, filePaths[i])
+		if err != nil {
+			msg := log.Error("unable to create symlink %s: %v", filePaths[i], err)
+			return errors.New(msg)
+		}
+
+		targets[i] = target
+	}
+
+	var escaped []string
+	for i, filePath := range filePaths {
+		if symlinkEscapes(realDest, filePath, targets[i]) {
+			escaped = append(escaped, files[i].Name)
+		}
+	}
+
+	if len(escaped) > 0 {
+		for _, filePath := range filePaths {
+			os.Remove(filePath)
+		}
+
+		msg := log.Error("symlinks %v point outside of %s", escaped, realDest)
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// decompressZipFile extracts the zip into destPath, overwriting what is already there so that zips can be layered
+// on top of each other. Entries that would end up outside destPath or be written through a symlink are rejected, as
+// are symlinks pointing outside of it and zips that are too large.
 func decompressZipFile(reader *zip.Reader, destPath string) error {
+	if len(reader.File) > MaxZipEntries {
+		msg := log.Error("zip has %d entries, more than the maximum of %d", len(reader.File), MaxZipEntries)
+		return errors.New(msg)
+	}
+
+	destPath, err := filepath.Abs(destPath)
+	if err != nil {
+		msg := log.Error("unable to find absolute path of %s: %v", destPath, err)
+		return errors.New(msg)
+	}
+
+	err = CreateDirs(destPath)
+	if err != nil {
+		msg := log.Error("unable to create directory %s: %v", destPath, err)
+		return errors.New(msg)
+	}
+
+	realDest, err := filepath.EvalSymlinks(destPath)
+	if err != nil {
+		msg := log.Error("unable to find real path of %s: %v", destPath, err)
+		return errors.New(msg)
+	}
+
+	var symlinkPaths []string
+	var symlinks []*zip.File
+	remaining := int64(MaxUncompressedZipSize)
 	for _, f := range reader.File {
-		filePath := filepath.Join(destPath, f.Name)
+		filePath, err := extractionPath(destPath, f.Name)
+		if err != nil {
+			msg := log.Error("unable to extract zip into %s: %v", destPath, err)
+			return errors.New(msg)
+		}
+
+		// a directory entry may itself be an existing symlink, which mustn't be written through either
+		dir := filepath.Dir(filePath)
+		if f.FileInfo().IsDir() {
+			dir = filePath
+		}
+
+		link, err := symlinkParent(destPath, dir)
+		if err != nil || len(link) > 0 {
+			msg := log.Error("unable to extract %s into %s through symlink %s: %v", f.Name, destPath, link, err)
+			return errors.New(msg)
+		}
 
-		var err error
 		if f.FileInfo().IsDir() {
 			err = CreateDirs(filePath)
+			if err != nil {
+				msg := log.Error("unable to create directory %s in %s: %v", f.Name, destPath, err)
+				return errors.New(msg)
+			}
 			continue
-		} else {
-			err = CreateDirs(filepath.Dir(filePath))
 		}
 
+		err = CreateDirs(filepath.Dir(filePath))
 		if err != nil {
 			msg := log.Error("unable to create directory for file %s in %s: %v", f.Name, destPath, err)
 			return errors.New(msg)
 		}
 
-		log.Info("Saving %s ...", filePath)
-		err = saveFile(filePath, *f)
+		// remove whatever a previous zip left behind, so a symlink is never written through
+		err = os.Remove(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			msg := log.Error("unable to replace file %s in %s: %v", f.Name, destPath, err)
+			return errors.New(msg)
+		}
+
+		if f.Mode()&os.ModeSymlink != 0 {
+			symlinkPaths = append(symlinkPaths, filePath)
+			symlinks = append(symlinks, f)
+			continue
+		}
+
+		log.Debug("Saving %s ...", filePath)
+		err = saveFile(filePath, f, fileMode(f), &remaining)
 		if err != nil {
 			msg := log.Error("unable to save file %s in %s: %v", f.Name, destPath, err)
 			return errors.New(msg)
 		}
+	}
 
+	err = saveSymlinks(realDest, symlinkPaths, symlinks)
+	if err != nil {
+		msg := log.Error("unable to save symlinks in %s: %v", destPath, err)
+		return errors.New(msg)
 	}
 
 	return nil
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type zipEntry struct {
+	name    string
+	content string
+	symlink bool
+}
+
+func buildZip(t *testing.T, entries ...zipEntry) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		header.SetMode(0644)
+		if entry.symlink {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("unable to add %s to zip: %v", entry.name, err)
+		}
+
+		_, err = w.Write([]byte(entry.content))
+		if err != nil {
+			t.Fatalf("unable to write %s to zip: %v", entry.name, err)
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatalf("unable to close zip: %v", err)
+	}
+
+	return buffer.Bytes()
+}
+
+// extractInto extracts the zip into a "dest" directory, returning it and its parent so that escapes can be spotted.
+func extractInto(t *testing.T, content []byte) (string, string, error) {
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "dest")
+	return parent, dest, UncompressZipFileBytes(content, dest)
+}
+
+func assertNotExists(t *testing.T, path string) {
+	_, err := os.Lstat(path)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected %s not to exist, got %v", path, err)
+	}
+}
+
+func TestUncompressZipFileBytes(t *testing.T) {
+	content := buildZip(t,
+		zipEntry{name: "lib/handler.py", content: "def handler(event, context): pass"},
+		zipEntry{name: "handler.py", content: "lib/handler.py", symlink: true},
+	)
+
+	_, dest, err := extractInto(t, content)
+	if err != nil {
+		t.Fatalf("unable to extract zip: %v", err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(dest, "handler.py"))
+	if err != nil || string(body) != "def handler(event, context): pass" {
+		t.Errorf("expected handler.py to link to lib/handler.py, got %q, %v", body, err)
+	}
+}
+
+func TestUncompressZipFileBytesRejectsParentTraversal(t *testing.T) {
+	content := buildZip(t, zipEntry{name: "../evil", content: "evil"})
+
+	parent, _, err := extractInto(t, content)
+	if err == nil {
+		t.Errorf("expected ../evil to be rejected")
+	}
+
+	assertNotExists(t, filepath.Join(parent, "evil"))
+}
+
+func TestUncompressZipFileBytesRejectsAbsolutePath(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "evil")
+	content := buildZip(t, zipEntry{name: target, content: "evil"})
+
+	_, _, err := extractInto(t, content)
+	if err == nil {
+		t.Errorf("expected %s to be rejected", target)
+	}
+
+	assertNotExists(t, target)
+}
+
+func TestUncompressZipFileBytesRejectsEscapingSymlink(t *testing.T) {
+	content := buildZip(t, zipEntry{name: "up", content: "..", symlink: true})
+
+	_, dest, err := extractInto(t, content)
+	if err == nil {
+		t.Errorf("expected symlink to .. to be rejected")
+	}
+
+	assertNotExists(t, filepath.Join(dest, "up"))
+}
+
+func TestUncompressZipFileBytesRejectsChainedSymlinks(t *testing.T) {
+	content := buildZip(t,
+		zipEntry{name: "s1", content: ".", symlink: true},
+		zipEntry{name: "s2", content: "s1/..", symlink: true},
+		zipEntry{name: "s2/evil", content: "evil"},
+	)
+
+	parent, _, err := extractInto(t, content)
+	if err == nil {
+		t.Errorf("expected chained symlinks to be rejected")
+	}
+
+	assertNotExists(t, filepath.Join(parent, "evil"))
+}
+
+func TestUncompressZipFileBytesRejectsSymlinksRedirectedByLaterOnes(t *testing.T) {
+	content := buildZip(t,
+		zipEntry{name: "s2", content: "s1/..", symlink: true},
+		zipEntry{name: "s1", content: ".", symlink: true},
+	)
+
+	_, dest, err := extractInto(t, content)
+	if err == nil {
+		t.Errorf("expected s2 to be rejected once s1 points to the destination")
+	}
+
+	assertNotExists(t, filepath.Join(dest, "s2"))
+}
+
+func TestUncompressZipFileBytesRejectsWritingThroughEarlierSymlink(t *testing.T) {
+	parent, dest, err := extractInto(t, buildZip(t,
+		zipEntry{name: "sub/keep", content: "keep"},
+		zipEntry{name: "link", content: "sub", symlink: true},
+	))
+	if err != nil {
+		t.Fatalf("unable to extract first zip: %v", err)
+	}
+
+	err = UncompressZipFileBytes(buildZip(t, zipEntry{name: "link/evil", content: "evil"}), dest)
+	if err == nil {
+		t.Errorf("expected link/evil to be rejected")
+	}
+
+	assertNotExists(t, filepath.Join(dest, "sub", "evil"))
+	assertNotExists(t, filepath.Join(parent, "evil"))
+}